@@ -42,14 +42,30 @@ var (
 	errBodyRead   = errors.New("error reading body")
 	errNoLink     = errors.New("Error: problem finding link")
 
+	errNotAuthenticated = errors.New("client has not been authenticated")
+
 	debug, _    = strconv.ParseBool(os.Getenv("GOSCALEIO_DEBUG"))
 	showHTTP, _ = strconv.ParseBool(os.Getenv("GOSCALEIO_SHOWHTTP"))
 )
 
 // Client defines struct for Client
 type Client struct {
-	configConnect *ConfigConnect
-	api           api.Client
+	// auth is shared with the copies made by WithContext
+	auth         *clientAuth
+	api          api.Client
+	capabilities *Capabilities
+	// instrumentation receives spans and measurements, no-op if nil
+	instrumentation Instrumentation
+	// auditSink receives an event for every mutating call, none if nil
+	auditSink AuditSink
+	// ctx is the context of the client's calls, set by WithContext
+	ctx context.Context
+	// origin is the client a library call was made on, operation the name
+	// of the call and audited what it declared with audit, set on the copy
+	// made by trace
+	origin    *Client
+	operation string
+	audited   *auditedCall
 	// FringeObject  interface{}
 }
 
@@ -65,6 +81,45 @@ type ConfigConnect struct {
 	Password string
 }
 
+// clientAuth holds the credentials a client and its copies authenticate with
+type clientAuth struct {
+	mu            sync.RWMutex
+	configConnect *ConfigConnect
+}
+
+// get returns a copy of the credentials, empty if there are none
+func (a *clientAuth) get() ConfigConnect {
+	if a == nil {
+		return ConfigConnect{}
+	}
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.configConnect == nil {
+		return ConfigConnect{}
+	}
+	return *a.configConnect
+}
+
+// set replaces the credentials, keeping the negotiated API version
+func (a *clientAuth) set(configConnect *ConfigConnect) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.configConnect != nil {
+		configConnect.Version = a.configConnect.Version
+	}
+	a.configConnect = configConnect
+}
+
+// setVersion records the negotiated API version
+func (a *clientAuth) setVersion(version string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.configConnect == nil {
+		a.configConnect = &ConfigConnect{}
+	}
+	a.configConnect.Version = version
+}
+
 // ClientPersistent defines struct for ClientPersistent
 type ClientPersistent struct {
 	configConnect *ConfigConnect
@@ -75,7 +130,7 @@ type ClientPersistent struct {
 func (c *Client) getVersion() (string, error) {
 
 	resp, err := c.api.DoAndGetResponseBody(
-		context.Background(), http.MethodGet, "/api/version", nil, nil)
+		c.requestContext(), http.MethodGet, "/api/version", nil, nil)
 	if err != nil {
 		return "", err
 	}
@@ -108,13 +163,66 @@ func (c *Client) updateVersion() error {
 	if err != nil {
 		return err
 	}
-	c.configConnect.Version = version
+	c.auth.setVersion(version)
 
 	updateHeaders(version)
 
 	return nil
 }
 
+// GetVersion returns the API version negotiated with the gateway, or an
+// empty string if it is not known yet
+func (c *Client) GetVersion() string {
+	return c.auth.get().Version
+}
+
+// checkMinVersion returns an error if the negotiated API version is older
+// than minVersion, or if it is not known
+func (c *Client) checkMinVersion(feature, minVersion string) error {
+	version := c.GetVersion()
+	older, err := versionLess(version, minVersion)
+	if err != nil {
+		return fmt.Errorf("%s requires API version %s or later: %v",
+			feature, minVersion, err)
+	}
+	if older {
+		return fmt.Errorf("%s requires API version %s or later, the gateway supports %s",
+			feature, minVersion, version)
+	}
+	return nil
+}
+
+// versionLess reports whether the major.minor version a is older than b
+func versionLess(a, b string) (bool, error) {
+	parse := func(v string) ([2]int, error) {
+		var out [2]int
+		parts := strings.SplitN(v, ".", 3)
+		if v == "" || len(parts) < 2 {
+			return out, fmt.Errorf("invalid version %q", v)
+		}
+		for i := range out {
+			n, err := strconv.Atoi(parts[i])
+			if err != nil {
+				return out, fmt.Errorf("invalid version %q", v)
+			}
+			out[i] = n
+		}
+		return out, nil
+	}
+	va, err := parse(a)
+	if err != nil {
+		return false, err
+	}
+	vb, err := parse(b)
+	if err != nil {
+		return false, err
+	}
+	if va[0] != vb[0] {
+		return va[0] < vb[0], nil
+	}
+	return va[1] < vb[1], nil
+}
+
 func updateHeaders(version string) {
 	mu.Lock()
 	defer mu.Unlock()
@@ -128,8 +236,7 @@ func updateHeaders(version string) {
 // Authenticate controls authentication to client
 func (c *Client) Authenticate(configConnect *ConfigConnect) (Cluster, error) {
 
-	configConnect.Version = c.configConnect.Version
-	c.configConnect = configConnect
+	c.auth.set(configConnect)
 
 	c.api.SetToken("")
 
@@ -138,7 +245,7 @@ func (c *Client) Authenticate(configConnect *ConfigConnect) (Cluster, error) {
 		configConnect.Username, configConnect.Password)
 
 	resp, err := c.api.DoAndGetResponseBody(
-		context.Background(), http.MethodGet, "api/login", headers, nil)
+		c.requestContext(), http.MethodGet, "api/login", headers, nil)
 	if err != nil {
 		doLog(log.WithError(err).Error, "")
 		return Cluster{}, err
@@ -160,7 +267,7 @@ func (c *Client) Authenticate(configConnect *ConfigConnect) (Cluster, error) {
 
 	c.api.SetToken(token)
 
-	if c.configConnect.Version == "" {
+	if c.GetVersion() == "" {
 		err = c.updateVersion()
 		if err != nil {
 			return Cluster{}, errors.New("error getting version of ScaleIO")
@@ -179,14 +286,27 @@ func (c *Client) getJSONWithRetry(
 	method, uri string,
 	body, resp interface{}) error {
 
+	audit := c.startAudit(method, uri, body)
+	err := c.doJSONWithRetry(method, uri, body, resp)
+	audit.end(resp, err)
+
+	return err
+}
+
+func (c *Client) doJSONWithRetry(
+	method, uri string,
+	body, resp interface{}) error {
+
 	headers := make(map[string]string, 2)
 	headers[api.HeaderKeyAccept] = accHeader
 	headers[api.HeaderKeyContentType] = conHeader
 	addMetaData(headers, body)
 
+	trace := c.traceRequest(method, uri)
 	err := c.api.DoWithHeaders(
-		context.Background(), method, uri, headers, body, resp)
+		trace.ctx, method, uri, headers, body, resp)
 	if err == nil {
+		trace.end(0, nil)
 		return nil
 	}
 
@@ -196,14 +316,19 @@ func (c *Client) getJSONWithRetry(
 		if e.HTTPStatusCode == 401 {
 			doLog(log.Info, "Need to re-auth")
 			// Authenticate then try again
-			if _, err := c.Authenticate(c.configConnect); err != nil {
+			if _, err := c.reauthenticate(); err != nil {
+				trace.end(0, err)
 				return fmt.Errorf("Error Authenticating: %s", err)
 			}
-			return c.api.DoWithHeaders(
-				context.Background(), method, uri, headers, body, resp)
+			trace.retry()
+			err = c.api.DoWithHeaders(
+				trace.ctx, method, uri, headers, body, resp)
+			trace.end(0, err)
+			return err
 		}
 	}
 	doLog(log.WithError(err).Error, "returning error")
+	trace.end(0, err)
 
 	return err
 }
@@ -219,11 +344,14 @@ func (c *Client) authorizedJSONWithRetry(method string, uri string,
 	// 	headers map[string]string,
 	// 	body, resp interface{}
 
-	ctx := context.Background()
-	ctx, cancel := context.WithTimeout(ctx, timeout)
+	trace := c.traceRequest(method, uri)
+	ctx, cancel := context.WithTimeout(trace.ctx, timeout)
 	defer cancel()
 
+	audit := c.startAudit(method, uri, body)
 	resp, err := c.api.DoAndGetResponseBodyAuthorized(ctx, method, uri, headers, body)
+	trace.end(0, err)
+	audit.end(resp, err)
 	if err == nil {
 		return resp, nil
 	}
@@ -254,6 +382,17 @@ func (c *Client) getStringWithRetry(
 	method, uri string,
 	body interface{}) (string, error) {
 
+	audit := c.startAudit(method, uri, body)
+	s, err := c.doStringWithRetry(method, uri, body)
+	audit.end(nil, err)
+
+	return s, err
+}
+
+func (c *Client) doStringWithRetry(
+	method, uri string,
+	body interface{}) (string, error) {
+
 	headers := make(map[string]string, 2)
 	headers[api.HeaderKeyAccept] = accHeader
 	headers[api.HeaderKeyContentType] = conHeader
@@ -280,9 +419,11 @@ func (c *Client) getStringWithRetry(
 		return s, false, nil
 	}
 
+	trace := c.traceRequest(method, uri)
 	resp, err := c.api.DoAndGetResponseBody(
-		context.Background(), method, uri, headers, body)
+		trace.ctx, method, uri, headers, body)
 	if err != nil {
+		trace.end(0, err)
 		return "", err
 	}
 	s, retry, httpErr := checkResponse(resp)
@@ -290,23 +431,36 @@ func (c *Client) getStringWithRetry(
 		if retry {
 			doLog(log.Info, "need to re-auth")
 			// Authenticate then try again
-			if _, err = c.Authenticate(c.configConnect); err != nil {
+			if _, err = c.reauthenticate(); err != nil {
+				trace.end(0, err)
 				return "", fmt.Errorf("Error Authenticating: %s", err)
 			}
+			trace.retry()
 			resp, err = c.api.DoAndGetResponseBody(
-				context.Background(), method, uri, headers, body)
+				trace.ctx, method, uri, headers, body)
 			if err != nil {
+				trace.end(0, err)
 				return "", err
 			}
 			s, _, err = checkResponse(resp)
+			trace.end(resp.StatusCode, err)
 		} else {
+			trace.end(0, httpErr)
 			return "", httpErr
 		}
+	} else {
+		trace.end(resp.StatusCode, nil)
 	}
 
 	return s, nil
 }
 
+// reauthenticate logs in again with the credentials of the client
+func (c *Client) reauthenticate() (Cluster, error) {
+	configConnect := c.auth.get()
+	return c.Authenticate(&configConnect)
+}
+
 // SetToken sets token
 func (c *Client) SetToken(token string) {
 	c.api.SetToken(token)
@@ -333,17 +487,33 @@ func NewClientWithArgs(
 	insecure,
 	useCerts bool) (client *Client, err error) {
 
+	return NewClientWithOptions(endpoint, version, api.ClientOptions{
+		Insecure: insecure,
+		UseCerts: useCerts,
+	})
+}
+
+// NewClientWithOptions returns a new client with the given API client
+// options, such as request interceptors. ShowHTTP is also enabled by the
+// GOSCALEIO_SHOWHTTP environment variable.
+func NewClientWithOptions(
+	endpoint string,
+	version string,
+	opts api.ClientOptions) (client *Client, err error) {
+
 	if showHTTP {
 		debug = true
+		opts.ShowHTTP = true
 	}
 
 	fields := map[string]interface{}{
-		"endpoint": endpoint,
-		"insecure": insecure,
-		"useCerts": useCerts,
-		"version":  version,
-		"debug":    debug,
-		"showHTTP": showHTTP,
+		"endpoint":     endpoint,
+		"insecure":     opts.Insecure,
+		"useCerts":     opts.UseCerts,
+		"version":      version,
+		"debug":        debug,
+		"showHTTP":     opts.ShowHTTP,
+		"interceptors": len(opts.Interceptors),
 	}
 
 	doLog(log.WithFields(fields).Debug, "goscaleio client init")
@@ -354,13 +524,9 @@ func NewClientWithArgs(
 			withFields(fields, "endpoint is required")
 	}
 
-	opts := api.ClientOptions{
-		Insecure: insecure,
-		UseCerts: useCerts,
-		ShowHTTP: showHTTP,
-	}
-
-	ac, err := api.New(context.Background(), endpoint, opts, debug)
+	// the status of every response is recorded on the span of its request
+	opts.Interceptors = append(append([]api.Interceptor{}, opts.Interceptors...), recordStatus)
+	ac, err := api.New(context.Background(), endpoint, opts, debug || opts.ShowHTTP)
 	if err != nil {
 		doLog(log.WithError(err).Error, "Unable to create HTTP client")
 		return nil, err
@@ -368,8 +534,10 @@ func NewClientWithArgs(
 
 	client = &Client{
 		api: ac,
-		configConnect: &ConfigConnect{
-			Version: version,
+		auth: &clientAuth{
+			configConnect: &ConfigConnect{
+				Version: version,
+			},
 		},
 	}
 
@@ -444,6 +612,9 @@ func doLog(
 }
 
 // ExternalTimeRecorder is used to track time
+//
+// Deprecated: use Client.SetInstrumentation, which also traces the HTTP
+// requests of each call.
 var ExternalTimeRecorder func(string, time.Duration)
 
 // TimeSpent is used to track time spent
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strings"
@@ -118,13 +119,23 @@ type Client interface {
 }
 
 type client struct {
-	http     *http.Client
-	host     string
-	token    string
-	showHTTP bool
-	debug    bool
+	http      *http.Client
+	host      string
+	token     string
+	debug     bool
+	roundTrip RoundTripFunc
 }
 
+// RoundTripFunc sends an HTTP request to the API and returns its response.
+// The request's context is the one passed to the Client.
+type RoundTripFunc func(req *http.Request) (*http.Response, error)
+
+// Interceptor wraps the sending of every request, for example to add
+// headers, log or inject faults. It calls next to send the request on, or
+// returns without calling it to short-circuit the request. ResponseError
+// decodes the error of a failed response without consuming it.
+type Interceptor func(next RoundTripFunc) RoundTripFunc
+
 // ClientOptions are options for the API client.
 type ClientOptions struct {
 	// Insecure is a flag that indicates whether or not to supress SSL errors.
@@ -139,6 +150,11 @@ type ClientOptions struct {
 	// ShowHTTP is a flag that indicates whether or not HTTP requests and
 	// responses should be logged to stdout
 	ShowHTTP bool
+
+	// Interceptors wrap every request, the first one outermost. When
+	// ShowHTTP is set the logging interceptor is innermost, so it logs the
+	// requests as sent.
+	Interceptors []Interceptor
 }
 
 // New returns a new API client.
@@ -187,11 +203,15 @@ func New(
 		}
 	}
 
+	c.debug = debug
+
+	c.roundTrip = c.http.Do
 	if opts.ShowHTTP {
-		c.showHTTP = true
+		c.roundTrip = logInterceptor(c.doLog)(c.roundTrip)
+	}
+	for i := len(opts.Interceptors) - 1; i >= 0; i-- {
+		c.roundTrip = opts.Interceptors[i](c.roundTrip)
 	}
-
-	c.debug = debug
 
 	return c, nil
 }
@@ -380,21 +400,13 @@ func (c *client) DoAndGetResponseBodyAuthorized(
 		req.Header.Set("Authorization", "Bearer "+c.token)
 	}
 
-	if c.showHTTP {
-		logRequest(ctx, req, c.doLog)
-	}
-
 	// send the request
 	req = req.WithContext(ctx)
 
-	if res, err = c.http.Do(req); err != nil {
+	if res, err = c.roundTrip(req); err != nil {
 		return nil, err
 	}
 
-	if c.showHTTP {
-		logResponse(ctx, res, c.doLog)
-	}
-
 	return res, err
 }
 func (c *client) DoAndGetResponseBody(
@@ -482,20 +494,12 @@ func (c *client) DoAndGetResponseBody(
 		req.SetBasicAuth("", c.token)
 	}
 
-	if c.showHTTP {
-		logRequest(ctx, req, c.doLog)
-	}
-
 	// send the request
 	req = req.WithContext(ctx)
-	if res, err = c.http.Do(req); err != nil {
+	if res, err = c.roundTrip(req); err != nil {
 		return nil, err
 	}
 
-	if c.showHTTP {
-		logResponse(ctx, res, c.doLog)
-	}
-
 	return res, err
 }
 
@@ -508,6 +512,29 @@ func (c *client) GetToken() string {
 }
 
 func (c *client) ParseJSONError(r *http.Response) error {
+	return parseJSONError(r)
+}
+
+// ResponseError returns the error the API returned in res, or nil if the
+// request succeeded. The body of res is left unread for the caller.
+func ResponseError(res *http.Response) error {
+	if res == nil || (res.StatusCode >= 200 && res.StatusCode <= 299) {
+		return nil
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	res.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	peek := *res
+	peek.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return parseJSONError(&peek)
+}
+
+func parseJSONError(r *http.Response) error {
 
 	jsonError := &types.Error{}
 
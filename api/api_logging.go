@@ -30,6 +30,21 @@ func isBinOctetBody(h http.Header) bool {
 	return h.Get(HeaderKeyContentType) == headerValContentTypeBinaryOctetStream
 }
 
+// logInterceptor logs every request and response, used with ShowHTTP
+func logInterceptor(lf func(func(args ...interface{}), string)) Interceptor {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			logRequest(req.Context(), req, lf)
+			res, err := next(req)
+			if err != nil {
+				return nil, err
+			}
+			logResponse(req.Context(), res, lf)
+			return res, nil
+		}
+	}
+}
+
 func logRequest(
 	ctx context.Context,
 	req *http.Request,
@@ -0,0 +1,200 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	types "github.com/AnshumanPradipPatil1506/goscaleio/types/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingInterceptor appends name to calls before and after sending
+func recordingInterceptor(name string, calls *[]string) Interceptor {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			*calls = append(*calls, name+" request "+req.Header.Get("X-Chain"))
+			req.Header.Set("X-Chain", strings.TrimSpace(req.Header.Get("X-Chain")+" "+name))
+			res, err := next(req)
+			*calls = append(*calls, name+" response")
+			return res, err
+		}
+	}
+}
+
+func newTestClient(t *testing.T, handler http.HandlerFunc, interceptors ...Interceptor) Client {
+	t.Helper()
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+
+	c, err := New(context.Background(), ts.URL, ClientOptions{
+		Insecure:     true,
+		Interceptors: interceptors,
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func Test_InterceptorOrder(t *testing.T) {
+	var calls []string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "sent "+r.Header.Get("X-Chain"))
+		w.Write([]byte(`{"id":"sys1"}`))
+	}, recordingInterceptor("outer", &calls), recordingInterceptor("inner", &calls))
+
+	var resp types.System
+	assert.Nil(t, c.Get(context.Background(), "/api/types/System/instances", nil, &resp))
+	assert.Equal(t, "sys1", resp.ID)
+	assert.Equal(t, []string{
+		"outer request ",
+		"inner request outer",
+		"sent outer inner",
+		"inner response",
+		"outer response",
+	}, calls)
+}
+
+func Test_InterceptorShortCircuit(t *testing.T) {
+	errInjected := errors.New("injected fault")
+
+	tests := map[string]struct {
+		interceptor Interceptor
+		check       func(t *testing.T, resp *types.System, err error)
+	}{
+		"response": {
+			interceptor: func(next RoundTripFunc) RoundTripFunc {
+				return func(req *http.Request) (*http.Response, error) {
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Header:     http.Header{},
+						Body:       ioutil.NopCloser(strings.NewReader(`{"id":"cached"}`)),
+						Request:    req,
+					}, nil
+				}
+			},
+			check: func(t *testing.T, resp *types.System, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, "cached", resp.ID)
+			},
+		},
+		"error": {
+			interceptor: func(next RoundTripFunc) RoundTripFunc {
+				return func(req *http.Request) (*http.Response, error) {
+					return nil, errInjected
+				}
+			},
+			check: func(t *testing.T, resp *types.System, err error) {
+				assert.True(t, errors.Is(err, errInjected), "error: %v", err)
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var calls []string
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			}, recordingInterceptor("outer", &calls), tt.interceptor, recordingInterceptor("inner", &calls))
+
+			var resp types.System
+			err := c.Get(context.Background(), "/api/types/System/instances", nil, &resp)
+			tt.check(t, &resp, err)
+			// the interceptors after the short-circuit are not called
+			assert.Equal(t, []string{"outer request ", "outer response"}, calls)
+		})
+	}
+}
+
+func Test_ResponseError(t *testing.T) {
+	tests := map[string]struct {
+		status      int
+		contentType string
+		body        string
+		want        error
+		isErr       bool
+	}{
+		"success": {
+			status:      http.StatusOK,
+			contentType: HeaderValContentTypeJSON,
+			body:        `{"id":"sys1"}`,
+		},
+		"json error": {
+			status:      http.StatusNotFound,
+			contentType: HeaderValContentTypeJSON,
+			body:        `{"message":"Could not find the volume","errorCode":3}`,
+			want: &types.Error{
+				Message:        "Could not find the volume",
+				HTTPStatusCode: http.StatusNotFound,
+				ErrorCode:      3,
+			},
+		},
+		"json error without message": {
+			status:      http.StatusInternalServerError,
+			contentType: HeaderValContentTypeJSON,
+			body:        `{}`,
+			want: &types.Error{
+				Message:        http.StatusText(http.StatusInternalServerError),
+				HTTPStatusCode: http.StatusInternalServerError,
+			},
+		},
+		"html error": {
+			status:      http.StatusBadGateway,
+			contentType: "text/html",
+			body:        `<html>bad gateway</html>`,
+			want: &types.Error{
+				Message:        http.StatusText(http.StatusBadGateway),
+				HTTPStatusCode: http.StatusBadGateway,
+			},
+		},
+		"malformed error": {
+			status:      http.StatusBadRequest,
+			contentType: HeaderValContentTypeJSON,
+			body:        `not json`,
+			isErr:       true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			res := &http.Response{
+				Status:     http.StatusText(tt.status),
+				StatusCode: tt.status,
+				Header:     http.Header{HeaderKeyContentType: []string{tt.contentType}},
+				Body:       ioutil.NopCloser(strings.NewReader(tt.body)),
+			}
+
+			err := ResponseError(res)
+			if tt.isErr {
+				_, isAPIError := err.(*types.Error)
+				assert.True(t, err != nil && !isAPIError, "error: %v", err)
+			} else {
+				assert.Equal(t, tt.want, err)
+			}
+
+			// the body is left unread for the caller
+			body, err := ioutil.ReadAll(res.Body)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.body, string(body))
+		})
+	}
+
+	assert.Nil(t, ResponseError(nil))
+}
@@ -14,6 +14,7 @@ package goscaleio
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -21,9 +22,11 @@ import (
 	"net/http/httptest"
 	"os"
 	"reflect"
+	"strings"
 	"sync"
 	"testing"
 
+	"github.com/AnshumanPradipPatil1506/goscaleio/api"
 	v1 "github.com/AnshumanPradipPatil1506/goscaleio/types/v1"
 )
 
@@ -279,6 +282,101 @@ func Test_getJSONWithRetry(t *testing.T) {
 	})
 }
 
+func Test_NewClientWithOptionsInterceptors(t *testing.T) {
+	var correlationIDs []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		correlationIDs = append(correlationIDs, r.Header.Get("X-Correlation-ID"))
+		switch r.URL.Path {
+		case "/api/ok":
+			fmt.Fprint(w, `{"id": "ok"}`)
+		case "/api/fail":
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"message": "failed", "errorCode": 7}`)
+		default:
+			t.Fatalf("unexpected path: %q", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	var (
+		order []string
+		seen  []string
+		errs  []error
+	)
+	correlate := func(next api.RoundTripFunc) api.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			order = append(order, "correlate")
+			req.Header.Set("X-Correlation-ID", fmt.Sprintf("id-%d", len(order)))
+			return next(req)
+		}
+	}
+	audit := func(next api.RoundTripFunc) api.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			order = append(order, "audit")
+			seen = append(seen, fmt.Sprintf("%s %s %s",
+				req.Method, req.URL.Path, req.Header.Get("X-Correlation-ID")))
+			res, err := next(req)
+			if err == nil {
+				errs = append(errs, api.ResponseError(res))
+			}
+			return res, err
+		}
+	}
+	faultInjected := errors.New("injected fault")
+	inject := func(next api.RoundTripFunc) api.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path == "/api/fault" {
+				return nil, faultInjected
+			}
+			return next(req)
+		}
+	}
+
+	c, err := NewClientWithOptions(ts.URL, "3.5", api.ClientOptions{
+		Insecure:     true,
+		Interceptors: []api.Interceptor{correlate, audit, inject},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var resp map[string]string
+	if err := c.getJSONWithRetry(http.MethodGet, "/api/ok", nil, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp["id"] != "ok" {
+		t.Errorf("response: got %+v", resp)
+	}
+
+	// the interceptor decodes the error without consuming the response
+	err = c.getJSONWithRetry(http.MethodPost, "/api/fail", nil, nil)
+	if e, ok := err.(*v1.Error); !ok || e.ErrorCode != 7 || e.Message != "failed" {
+		t.Errorf("error: got %#v", err)
+	}
+
+	if err := c.getJSONWithRetry(http.MethodGet, "/api/fault", nil, nil); !errors.Is(err, faultInjected) {
+		t.Errorf("fault: got %v, want %v", err, faultInjected)
+	}
+
+	wantOrder := []string{"correlate", "audit", "correlate", "audit", "correlate", "audit"}
+	if !reflect.DeepEqual(order, wantOrder) {
+		t.Errorf("order: got %v, want %v", order, wantOrder)
+	}
+	wantSeen := []string{"GET /api/ok id-1", "POST /api/fail id-3", "GET /api/fault id-5"}
+	if !reflect.DeepEqual(seen, wantSeen) {
+		t.Errorf("seen: got %v, want %v", seen, wantSeen)
+	}
+	if wantIDs := []string{"id-1", "id-3"}; !reflect.DeepEqual(correlationIDs, wantIDs) {
+		t.Errorf("correlation IDs: got %v, want %v", correlationIDs, wantIDs)
+	}
+	if len(errs) != 2 || errs[0] != nil {
+		t.Fatalf("decoded errors: got %v", errs)
+	}
+	if e, ok := errs[1].(*v1.Error); !ok || e.ErrorCode != 7 || e.HTTPStatusCode != 500 {
+		t.Errorf("decoded error: got %#v", errs[1])
+	}
+}
+
 // testFilterHeaders accepts a header and a list of header names
 // to filter on (inclusive).  The returned http.Header will include only
 // header fields with these names.
@@ -321,3 +419,62 @@ func testjsonEncode(t *testing.T, w io.Writer, v interface{}) {
 		t.Fatal(err)
 	}
 }
+
+// testGateway is a gateway that answers the requests listed in its
+// responses, keyed by "METHOD path", and records each request as
+// "METHOD path body". A response of type func() interface{} is called
+// for every request. Any other request fails the test.
+type testGateway struct {
+	t         *testing.T
+	responses map[string]interface{}
+
+	mu       sync.Mutex
+	requests []string
+}
+
+// newTestGateway starts a testGateway for t and returns a client of it
+// speaking the given API version.
+func newTestGateway(t *testing.T, version string, responses map[string]interface{}) (*Client, *testGateway) {
+	t.Helper()
+	g := &testGateway{t: t, responses: responses}
+	ts := httptest.NewServer(g)
+	t.Cleanup(ts.Close)
+
+	client, err := NewClientWithArgs(ts.URL, version, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, g
+}
+
+func (g *testGateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		g.t.Error(err)
+	}
+	path := fmt.Sprintf("%s %s", r.Method, r.URL.Path)
+	g.mu.Lock()
+	g.requests = append(g.requests, strings.TrimSpace(path+" "+strings.TrimSpace(string(body))))
+	g.mu.Unlock()
+
+	resp, ok := g.responses[path]
+	if !ok {
+		g.t.Errorf("unexpected request: %q", path)
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(testBuildError(http.StatusNotFound))
+		return
+	}
+	if fn, ok := resp.(func() interface{}); ok {
+		resp = fn()
+	}
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		g.t.Error(err)
+	}
+}
+
+// Requests returns the requests received so far.
+func (g *testGateway) Requests() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]string(nil), g.requests...)
+}
@@ -0,0 +1,319 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goscaleio
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AnshumanPradipPatil1506/goscaleio/api"
+	types "github.com/AnshumanPradipPatil1506/goscaleio/types/v1"
+	log "github.com/sirupsen/logrus"
+)
+
+// auditRedacted replaces the values of sensitive parameters
+const auditRedacted = "[REDACTED]"
+
+// auditSensitiveKeys are the parameter name fragments whose values are
+// never audited
+var auditSensitiveKeys = []string{"password", "secret", "token", "credential"}
+
+// AuditEvent records one mutating call made through a Client, including
+// calls refused by capability checks before any request was sent
+type AuditEvent struct {
+	// Time is when the call started
+	Time time.Time `json:"time"`
+	// Operation is the library call, such as "Volume.RemoveVolume" or
+	// "Volume.MapVolumeSdc"
+	Operation string `json:"operation"`
+	// Method and Path are the request method and its path without IDs,
+	// empty for a call refused by a capability check
+	Method string `json:"method,omitempty"`
+	Path   string `json:"path,omitempty"`
+	// ObjectType is the type of the changed object, such as "Volume"
+	ObjectType string `json:"objectType,omitempty"`
+	// ObjectIDs are the changed object and, for creations, the new object
+	ObjectIDs []string `json:"objectIds,omitempty"`
+	// TargetIDs are the objects the changed object is mapped to or
+	// unmapped from, such as the SDC of MapVolumeSdc
+	TargetIDs []string `json:"targetIds,omitempty"`
+	// Parameters are the request parameters with sensitive values redacted
+	Parameters interface{} `json:"parameters,omitempty"`
+	// User is the user the client authenticated as
+	User string `json:"user,omitempty"`
+	// Duration is how long the call took, including retries
+	Duration time.Duration `json:"duration"`
+	// Success reports whether the call succeeded, Error and ErrorCode why
+	// it did not
+	Success   bool   `json:"success"`
+	Error     string `json:"error,omitempty"`
+	ErrorCode int    `json:"errorCode,omitempty"`
+}
+
+// AuditSink receives the audit events of a Client. Audit is called
+// synchronously after each mutating call and must be safe for concurrent
+// use.
+type AuditSink interface {
+	Audit(event *AuditEvent)
+}
+
+// AuditFunc is an AuditSink calling a function with every event
+type AuditFunc func(event *AuditEvent)
+
+// Audit implements AuditSink
+func (f AuditFunc) Audit(event *AuditEvent) {
+	f(event)
+}
+
+// JSONLinesAuditSink is an AuditSink writing every event as one line of
+// JSON
+type JSONLinesAuditSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewJSONLinesAuditSink returns a JSONLinesAuditSink writing to w
+func NewJSONLinesAuditSink(w io.Writer) *JSONLinesAuditSink {
+	return &JSONLinesAuditSink{w: w}
+}
+
+// OpenJSONLinesAuditFile returns a JSONLinesAuditSink appending to the file
+// at path, which is created if needed. Close closes the file.
+func OpenJSONLinesAuditFile(path string) (*JSONLinesAuditSink, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return NewJSONLinesAuditSink(f), nil
+}
+
+// Audit implements AuditSink. Write errors are logged.
+func (s *JSONLinesAuditSink) Audit(event *AuditEvent) {
+	line, err := json.Marshal(event)
+	if err != nil {
+		doLog(log.WithError(err).Error, "audit: encoding event")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.w.Write(append(line, '\n')); err != nil {
+		doLog(log.WithError(err).Error, "audit: writing event")
+	}
+}
+
+// Close closes the underlying writer if it is an io.Closer
+func (s *JSONLinesAuditSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// SetAuditSink sets the sink receiving an event for every mutating call
+// of the client, or disables auditing if sink is nil
+func (c *Client) SetAuditSink(sink AuditSink) {
+	c.auditSink = sink
+}
+
+// auditParameters returns the parameters of a request body as generic JSON
+// with sensitive values redacted
+func auditParameters(body interface{}) interface{} {
+	var raw []byte
+	switch b := body.(type) {
+	case nil:
+		return nil
+	case string:
+		raw = []byte(b)
+	case []byte:
+		raw = b
+	default:
+		var err error
+		if raw, err = json.Marshal(body); err != nil {
+			return nil
+		}
+	}
+
+	var params interface{}
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil
+	}
+	return redact(params)
+}
+
+func redact(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		if len(value) == 0 {
+			return nil
+		}
+		for k, nested := range value {
+			if isSensitiveKey(k) {
+				value[k] = auditRedacted
+				continue
+			}
+			value[k] = redact(nested)
+		}
+	case []interface{}:
+		for i, nested := range value {
+			value[i] = redact(nested)
+		}
+	}
+	return v
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range auditSensitiveKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// auditedCall is what a mutating library call declares about the object
+// it changes
+type auditedCall struct {
+	objectType string
+	objectIDs  []string
+	targetIDs  []string
+}
+
+// audit declares that the call of c changes the object of the given type
+// and ID, empty for a creation, and maps it to or unmaps it from the
+// objects with the given target IDs. The requests of the call other than
+// reads are then audited. It must be called on the client returned by
+// trace, before checkCapability.
+func (c *Client) audit(objectType, objectID string, targetIDs ...string) {
+	call := &auditedCall{objectType: objectType}
+	if objectID != "" {
+		call.objectIDs = []string{objectID}
+	}
+	for _, id := range targetIDs {
+		if id != "" {
+			call.targetIDs = append(call.targetIDs, id)
+		}
+	}
+	c.audited = call
+}
+
+// auditEvent returns a new audit event of the call of c
+func (c *Client) auditEvent() *AuditEvent {
+	event := &AuditEvent{
+		Time:      time.Now(),
+		Operation: c.operation,
+		User:      c.auth.get().Username,
+	}
+	if c.audited != nil {
+		event.ObjectType = c.audited.objectType
+		event.ObjectIDs = append([]string(nil), c.audited.objectIDs...)
+		event.TargetIDs = c.audited.targetIDs
+	}
+	return event
+}
+
+// auditDenied sends the audit event of a call refused by a capability
+// check. It must be called by checkCapability.
+func (c *Client) auditDenied(err error) {
+	if c.auditSink == nil {
+		return
+	}
+	event := c.auditEvent()
+	event.Error = err.Error()
+	c.auditSink.Audit(event)
+}
+
+// auditRecord collects the audit event of one request
+type auditRecord struct {
+	sink  AuditSink
+	event *AuditEvent
+	start time.Time
+}
+
+// startAudit starts the audit event of a request, or returns nil if the
+// request is not audited because it is a read or its call did not declare
+// itself with audit
+func (c *Client) startAudit(method, uri string, body interface{}) *auditRecord {
+	if c.auditSink == nil || c.audited == nil ||
+		method == http.MethodGet || method == http.MethodHead {
+		return nil
+	}
+
+	event := c.auditEvent()
+	event.Method = method
+	event.Path = routeTemplate(uri)
+	event.Parameters = auditParameters(body)
+	return &auditRecord{sink: c.auditSink, event: event, start: time.Now()}
+}
+
+// end completes the event with the result of the request and sends it.
+// The ID in resp, if any, is the ID of a created object. resp may be the
+// *http.Response of the request, whose body is left for the caller to read.
+func (r *auditRecord) end(resp interface{}, err error) {
+	if r == nil {
+		return
+	}
+
+	r.event.Duration = time.Since(r.start)
+	var raw []byte
+	if hr, ok := resp.(*http.Response); ok {
+		if err == nil {
+			err = api.ResponseError(hr)
+		}
+		if err == nil && hr.Body != nil {
+			raw, _ = ioutil.ReadAll(hr.Body)
+			hr.Body.Close()
+			hr.Body = ioutil.NopCloser(bytes.NewReader(raw))
+		}
+	} else if err == nil && resp != nil {
+		raw, _ = json.Marshal(resp)
+	}
+	if err == nil && raw != nil {
+		var created struct {
+			ID string `json:"id"`
+		}
+		if json.Unmarshal(raw, &created) == nil && created.ID != "" &&
+			!containsString(r.event.ObjectIDs, created.ID) {
+			r.event.ObjectIDs = append(r.event.ObjectIDs, created.ID)
+		}
+	}
+
+	r.event.Success = err == nil
+	if err != nil {
+		r.event.Error = err.Error()
+		if e, ok := err.(*types.Error); ok {
+			r.event.ErrorCode = e.ErrorCode
+		}
+	}
+
+	r.sink.Audit(r.event)
+}
@@ -0,0 +1,222 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goscaleio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	types "github.com/AnshumanPradipPatil1506/goscaleio/types/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AuditSink(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch fmt.Sprintf("%s %s", r.Method, r.URL.Path) {
+		case "POST /api/types/Volume/instances":
+			fmt.Fprint(w, `{"id": "vol1"}`)
+		case "POST /api/instances/Volume::vol1/action/addMappedSdc",
+			"POST /api/instances/User::u1/action/resetPassword",
+			"POST /api/instances/querySelectedStatistics":
+			fmt.Fprint(w, `{}`)
+		case "GET /api/instances/Volume::vol1":
+			fmt.Fprint(w, `{"id": "vol1"}`)
+		case "POST /api/instances/Volume::vol1/action/removeVolume":
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"message": "volume is mapped", "errorCode": 94}`)
+		case "PUT /api/v1/ServiceTemplate/tpl1":
+			fmt.Fprint(w, `{}`)
+		case "POST /api/v1/ServiceTemplate":
+			fmt.Fprint(w, `{"id": "tpl2", "templateName": "t2"}`)
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	client, err := NewClientWithArgs(ts.URL, "3.5", true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.auth.configConnect.Username = "admin"
+
+	var events []*AuditEvent
+	client.SetAuditSink(AuditFunc(func(e *AuditEvent) {
+		events = append(events, e)
+	}))
+
+	pool := NewStoragePool(client)
+	pool.StoragePool = &types.StoragePool{ID: "sp1", ProtectionDomainID: "pd1"}
+	_, err = pool.CreateVolume(&types.VolumeParam{Name: "vol", VolumeSizeInKb: "8388608"})
+	assert.Nil(t, err)
+
+	volume := NewVolume(client)
+	volume.Volume = &types.Volume{ID: "vol1", Links: []*types.Link{
+		{Rel: "self", HREF: "/api/instances/Volume::vol1"},
+	}}
+	assert.Nil(t, volume.MapVolumeSdc(&types.MapVolumeSdcParam{SdcID: "sdc1"}))
+	assert.NotNil(t, volume.RemoveVolume("ONLY_ME"))
+
+	system := NewSystem(client)
+	assert.Nil(t, system.ResetUserPassword("u1", "Secret123!"))
+
+	// reads and queries are not audited
+	assert.Nil(t, client.getJSONWithRetry(http.MethodGet, "/api/instances/Volume::vol1", nil, nil))
+	_, err = system.QuerySelectedStatistics(
+		StatisticsQuery{Type: StatisticsTypeVolume, Properties: []string{"userDataReadBwc"}})
+	assert.Nil(t, err)
+
+	_, err = client.UpdateTemplate(`{"templateName": "t"}`, "tpl1")
+	assert.Nil(t, err)
+	resp, err := client.FromString(`{"templateName": "t2"}`)
+	assert.Nil(t, err)
+	// the body is still there for the caller
+	assert.Contains(t, string(testReadAll(t, resp.(*http.Response).Body)), `"tpl2"`)
+
+	assert.Len(t, events, 6)
+	for _, e := range events {
+		assert.Equal(t, "admin", e.User)
+		assert.False(t, e.Time.IsZero())
+	}
+
+	assert.Equal(t, "StoragePool.CreateVolume", events[0].Operation)
+	assert.Equal(t, "Volume", events[0].ObjectType)
+	assert.Equal(t, []string{"vol1"}, events[0].ObjectIDs)
+	assert.Equal(t, "/api/types/Volume/instances", events[0].Path)
+	assert.Equal(t, "vol", events[0].Parameters.(map[string]interface{})["name"])
+	assert.Equal(t, "sp1", events[0].Parameters.(map[string]interface{})["storagePoolId"])
+	assert.True(t, events[0].Success)
+
+	assert.Equal(t, "Volume.MapVolumeSdc", events[1].Operation)
+	assert.Equal(t, []string{"vol1"}, events[1].ObjectIDs)
+	assert.Equal(t, []string{"sdc1"}, events[1].TargetIDs)
+	assert.Equal(t, "/api/instances/Volume::{id}/action/addMappedSdc", events[1].Path)
+	assert.Equal(t, map[string]interface{}{"sdcId": "sdc1"}, events[1].Parameters)
+
+	assert.Equal(t, "Volume.RemoveVolume", events[2].Operation)
+	assert.Equal(t, []string{"vol1"}, events[2].ObjectIDs)
+	assert.Nil(t, events[2].TargetIDs)
+	assert.False(t, events[2].Success)
+	assert.Equal(t, 94, events[2].ErrorCode)
+	assert.Equal(t, "volume is mapped", events[2].Error)
+
+	assert.Equal(t, "System.ResetUserPassword", events[3].Operation)
+	assert.Equal(t, []string{"u1"}, events[3].ObjectIDs)
+	assert.Equal(t, "User", events[3].ObjectType)
+	assert.Equal(t, map[string]interface{}{"password": auditRedacted}, events[3].Parameters)
+
+	assert.Equal(t, "Client.UpdateTemplate", events[4].Operation)
+	assert.Equal(t, "ServiceTemplate", events[4].ObjectType)
+	assert.Equal(t, []string{"tpl1"}, events[4].ObjectIDs)
+	assert.Equal(t, map[string]interface{}{"templateName": "t"}, events[4].Parameters)
+
+	assert.Equal(t, "Client.FromString", events[5].Operation)
+	assert.Equal(t, []string{"tpl2"}, events[5].ObjectIDs)
+}
+
+func Test_AuditDenied(t *testing.T) {
+	client, err := NewClientWithArgs("https://gateway.invalid", "3.5", true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.auth.configConnect.Username = "monitor"
+	client.SetCapabilities(CapabilitiesForRole("monitor", types.UserRoleMonitor))
+
+	var events []*AuditEvent
+	client.SetAuditSink(AuditFunc(func(e *AuditEvent) {
+		events = append(events, e)
+	}))
+
+	volume := NewVolume(client)
+	volume.Volume = &types.Volume{ID: "vol1"}
+	err = volume.MapVolumeSdc(&types.MapVolumeSdcParam{SdcID: "sdc1"})
+	assert.IsType(t, &PermissionError{}, err)
+
+	// the call is audited although no request was sent
+	assert.Len(t, events, 1)
+	assert.Equal(t, "Volume.MapVolumeSdc", events[0].Operation)
+	assert.Equal(t, "Volume", events[0].ObjectType)
+	assert.Equal(t, []string{"vol1"}, events[0].ObjectIDs)
+	assert.Equal(t, []string{"sdc1"}, events[0].TargetIDs)
+	assert.Equal(t, "monitor", events[0].User)
+	assert.False(t, events[0].Success)
+	assert.Equal(t, err.Error(), events[0].Error)
+	assert.Empty(t, events[0].Method)
+}
+
+func Test_JSONLinesAuditSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := OpenJSONLinesAuditFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink.Audit(&AuditEvent{Operation: "RemoveVolume", ObjectIDs: []string{"vol1"}, Success: true})
+	sink.Audit(&AuditEvent{Operation: "MapVolumeSdc", Error: "failed"})
+	assert.Nil(t, sink.Close())
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var operations []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e AuditEvent
+		assert.Nil(t, json.Unmarshal(scanner.Bytes(), &e))
+		operations = append(operations, e.Operation)
+	}
+	assert.Equal(t, []string{"RemoveVolume", "MapVolumeSdc"}, operations)
+
+	buf := &bytes.Buffer{}
+	NewJSONLinesAuditSink(buf).Audit(&AuditEvent{Operation: "CreateVolume"})
+	assert.Contains(t, buf.String(), `"operation":"CreateVolume"`)
+	assert.Nil(t, NewJSONLinesAuditSink(buf).Close())
+}
+
+func Test_audit(t *testing.T) {
+	client, err := NewClientWithArgs("https://gateway.invalid", "3.5", true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetAuditSink(AuditFunc(func(e *AuditEvent) {}))
+
+	c, end := client.trace("Volume.MapVolumeSdc")
+	defer end(nil)
+	c.audit("Volume", "vol1", "", "sdc1")
+
+	// empty target IDs, such as the SDC of a mapping to all SDCs, are left out
+	event := c.startAudit(http.MethodPost, "/api/instances/Volume::vol1/action/addMappedSdc", nil).event
+	assert.Equal(t, "Volume.MapVolumeSdc", event.Operation)
+	assert.Equal(t, "Volume", event.ObjectType)
+	assert.Equal(t, []string{"vol1"}, event.ObjectIDs)
+	assert.Equal(t, []string{"sdc1"}, event.TargetIDs)
+	assert.Equal(t, http.MethodPost, event.Method)
+
+	// reads are not audited
+	assert.Nil(t, c.startAudit(http.MethodGet, "/api/instances/Volume::vol1", nil))
+
+	// nor are the calls made by an audited call, or the client itself
+	nested, endNested := c.trace("System.FindSdc")
+	defer endNested(nil)
+	assert.Nil(t, nested.startAudit(http.MethodPost, "/api/types/Sdc/instances/action/queryIdByKey", nil))
+	assert.Nil(t, client.startAudit(http.MethodPost, "/api/instances/Volume::vol1/action/addMappedSdc", nil))
+}
@@ -0,0 +1,177 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goscaleio
+
+import (
+	"fmt"
+
+	types "github.com/AnshumanPradipPatil1506/goscaleio/types/v1"
+)
+
+// Operation is a class of mutating request that a user role may or may
+// not be permitted to perform
+type Operation string
+
+// Operations checked by Capabilities
+const (
+	// OpVolumeCreate covers creating volumes
+	OpVolumeCreate Operation = "volume-create"
+	// OpVolumeRemove covers removing volumes
+	OpVolumeRemove Operation = "volume-remove"
+	// OpVolumeModify covers renaming and resizing volumes
+	OpVolumeModify Operation = "volume-modify"
+	// OpVolumeMap covers mapping and unmapping volumes and setting mapping limits
+	OpVolumeMap Operation = "volume-map"
+	// OpHostAdmin covers renaming SDCs and creating, renaming and removing
+	// non-SDC hosts such as SCSI initiators
+	OpHostAdmin Operation = "host-admin"
+	// OpSdsAdmin covers SDS and SDT administration
+	OpSdsAdmin Operation = "sds-admin"
+	// OpDeviceAdmin covers adding and removing devices
+	OpDeviceAdmin Operation = "device-admin"
+	// OpStorageAdmin covers protection domain and storage pool administration
+	OpStorageAdmin Operation = "storage-admin"
+	// OpReplicationAdmin covers replication consistency groups and pairs
+	OpReplicationAdmin Operation = "replication-admin"
+	// OpUserAdmin covers creating and removing users and changing their roles and passwords
+	OpUserAdmin Operation = "user-admin"
+	// OpTemplateAdmin covers creating, updating and removing service templates
+	OpTemplateAdmin Operation = "template-admin"
+)
+
+var (
+	frontendOperations = []Operation{
+		OpVolumeCreate, OpVolumeRemove, OpVolumeModify, OpVolumeMap, OpHostAdmin,
+	}
+	backendOperations = []Operation{
+		OpSdsAdmin, OpDeviceAdmin, OpStorageAdmin,
+	}
+
+	allOperations = append(append([]Operation{OpUserAdmin, OpReplicationAdmin, OpTemplateAdmin},
+		frontendOperations...), backendOperations...)
+
+	// roleOperations are the operations each of types.UserRoles may
+	// perform. Roles missing from it, such as ones added by a newer
+	// gateway, are not checked locally and are left to the gateway.
+	roleOperations = map[types.UserRole][]Operation{
+		types.UserRoleMonitor:         {},
+		types.UserRoleFrontendConfig:  frontendOperations,
+		types.UserRoleBackendConfig:   backendOperations,
+		types.UserRoleConfigure:       append(append([]Operation{OpReplicationAdmin}, frontendOperations...), backendOperations...),
+		types.UserRoleSecurity:        {OpUserAdmin},
+		types.UserRoleDeployment:      append([]Operation{OpTemplateAdmin}, backendOperations...),
+		types.UserRoleAdministrator:   allOperations,
+		types.UserRoleSuperUser:       allOperations,
+		types.UserRoleSystemAdminSite: allOperations,
+	}
+)
+
+// PermissionError is returned when the role of the current user does not
+// permit an operation
+type PermissionError struct {
+	Operation Operation
+	User      string
+	Role      types.UserRole
+}
+
+func (e *PermissionError) Error() string {
+	return fmt.Sprintf("user %s with role %s is not permitted to perform %s",
+		e.User, e.Role, e.Operation)
+}
+
+// Capabilities describes which operations a user may perform
+type Capabilities struct {
+	User    string
+	Role    types.UserRole
+	allowed map[Operation]bool
+	// unchecked is set for a role the library does not know
+	unchecked bool
+}
+
+// CapabilitiesForRole returns the capabilities of a user with the given
+// role. A role the library does not know permits every operation, so a
+// newer gateway's roles are not locked out; the gateway still enforces them.
+func CapabilitiesForRole(user string, role types.UserRole) *Capabilities {
+	ops, known := roleOperations[role]
+	c := &Capabilities{
+		User:      user,
+		Role:      role,
+		allowed:   make(map[Operation]bool),
+		unchecked: !known,
+	}
+	for _, op := range ops {
+		c.allowed[op] = true
+	}
+	return c
+}
+
+// Can reports whether the operation is permitted
+func (c *Capabilities) Can(op Operation) bool {
+	return c.unchecked || c.allowed[op]
+}
+
+// Check returns a *PermissionError if the operation is not permitted
+func (c *Capabilities) Check(op Operation) error {
+	if c.Can(op) {
+		return nil
+	}
+	return &PermissionError{
+		Operation: op,
+		User:      c.User,
+		Role:      c.Role,
+	}
+}
+
+// GetCapabilities returns the capabilities of the current user
+func (s *System) GetCapabilities() (_ *Capabilities, err error) {
+	c, end := s.client.trace("System.GetCapabilities")
+	defer end(&err)
+
+	user, err := s.withClient(c).GetCurrentUser()
+	if err != nil {
+		return nil, err
+	}
+	return CapabilitiesForRole(user.Name, user.Role()), nil
+}
+
+// EnableCapabilityChecks looks up the capabilities of the current user and
+// makes the client fail operations they do not permit with a
+// *PermissionError, without sending the request
+func (s *System) EnableCapabilityChecks() error {
+	caps, err := s.GetCapabilities()
+	if err != nil {
+		return err
+	}
+	s.client.SetCapabilities(caps)
+	return nil
+}
+
+// SetCapabilities makes the client check operations against the given
+// capabilities before sending them. A nil value disables the checks.
+// It should be called before the client is used concurrently.
+func (c *Client) SetCapabilities(caps *Capabilities) {
+	c.capabilities = caps
+}
+
+// checkCapability returns a *PermissionError if capability checks are
+// enabled and the operation is not permitted. The refused call is audited.
+func (c *Client) checkCapability(op Operation) error {
+	if c.capabilities == nil {
+		return nil
+	}
+	err := c.capabilities.Check(op)
+	if err != nil {
+		c.auditDenied(err)
+	}
+	return err
+}
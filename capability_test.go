@@ -0,0 +1,157 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goscaleio
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	types "github.com/AnshumanPradipPatil1506/goscaleio/types/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CapabilitiesForRole(t *testing.T) {
+	tests := map[types.UserRole]struct {
+		allowed []Operation
+		denied  []Operation
+	}{
+		types.UserRoleMonitor: {
+			denied: []Operation{OpVolumeCreate, OpVolumeMap, OpSdsAdmin, OpUserAdmin},
+		},
+		types.UserRoleFrontendConfig: {
+			allowed: []Operation{OpVolumeCreate, OpVolumeRemove, OpVolumeMap},
+			denied:  []Operation{OpSdsAdmin, OpDeviceAdmin, OpUserAdmin},
+		},
+		types.UserRoleBackendConfig: {
+			allowed: []Operation{OpSdsAdmin, OpDeviceAdmin, OpStorageAdmin},
+			denied:  []Operation{OpVolumeCreate, OpVolumeMap, OpUserAdmin},
+		},
+		types.UserRoleConfigure: {
+			allowed: []Operation{OpVolumeCreate, OpVolumeMap, OpSdsAdmin, OpDeviceAdmin},
+			denied:  []Operation{OpUserAdmin},
+		},
+		types.UserRoleSecurity: {
+			allowed: []Operation{OpUserAdmin},
+			denied:  []Operation{OpVolumeCreate, OpSdsAdmin},
+		},
+		types.UserRoleSuperUser: {
+			allowed: []Operation{OpVolumeCreate, OpVolumeMap, OpSdsAdmin, OpDeviceAdmin, OpUserAdmin},
+		},
+		types.UserRoleDeployment: {
+			allowed: []Operation{OpTemplateAdmin, OpSdsAdmin, OpDeviceAdmin},
+			denied:  []Operation{OpVolumeCreate, OpUserAdmin},
+		},
+		types.UserRoleSystemAdminSite: {
+			allowed: []Operation{OpVolumeCreate, OpHostAdmin, OpSdsAdmin, OpUserAdmin, OpTemplateAdmin},
+		},
+		// roles the library does not know are left to the gateway
+		"SomeFutureRole": {
+			allowed: []Operation{OpVolumeCreate, OpUserAdmin},
+		},
+	}
+	for role, tt := range tests {
+		t.Run(string(role), func(t *testing.T) {
+			caps := CapabilitiesForRole("someone", role)
+			for _, op := range tt.allowed {
+				assert.True(t, caps.Can(op), op)
+				assert.Nil(t, caps.Check(op))
+			}
+			for _, op := range tt.denied {
+				assert.False(t, caps.Can(op), op)
+				var perr *PermissionError
+				assert.True(t, errors.As(caps.Check(op), &perr))
+				assert.Equal(t, op, perr.Operation)
+				assert.Equal(t, role, perr.Role)
+			}
+		})
+	}
+}
+
+func Test_RoleOperationsCoverUserRoles(t *testing.T) {
+	for _, role := range types.UserRoles {
+		_, ok := roleOperations[role]
+		assert.True(t, ok, role)
+	}
+}
+
+func Test_EnableCapabilityChecks(t *testing.T) {
+	var paths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := fmt.Sprintf("%s %s", r.Method, r.URL.Path)
+		paths = append(paths, path)
+		switch path {
+		case "GET /api/login":
+			fmt.Fprintf(w, `"fakesessiontoken"`)
+		case "POST /api/types/User/instances/action/queryIdByKey":
+			fmt.Fprintf(w, `"u1"`)
+		case "GET /api/instances/User::u1":
+			user := types.User{ID: "u1", Name: "watcher", UserRole: "Monitor"}
+			if err := json.NewEncoder(w).Encode(user); err != nil {
+				t.Error(err)
+			}
+		default:
+			t.Errorf("unexpected path: %q", path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client, err := NewClientWithArgs(ts.URL, "3.5", true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.Authenticate(&ConfigConnect{Username: "watcher", Password: "password"})
+	assert.Nil(t, err)
+	system := NewSystem(client)
+	system.System.ID = "sys1"
+
+	assert.Nil(t, system.EnableCapabilityChecks())
+
+	pool := NewStoragePoolEx(client, &types.StoragePool{ID: "sp1"})
+	_, err = pool.CreateVolume(&types.VolumeParam{Name: "vol"})
+	var perr *PermissionError
+	assert.True(t, errors.As(err, &perr))
+	assert.Equal(t, OpVolumeCreate, perr.Operation)
+	assert.Equal(t, "watcher", perr.User)
+
+	vol := NewVolume(client)
+	vol.Volume = &types.Volume{ID: "v1"}
+	assert.NotNil(t, vol.MapVolumeSdc(&types.MapVolumeSdcParam{SdcID: "sdc1"}))
+
+	_, err = system.ChangeSdcName("sdc1", "renamed")
+	assert.True(t, errors.As(err, &perr))
+	assert.Equal(t, OpHostAdmin, perr.Operation)
+	_, err = system.CreateSnapshotConsistencyGroup(&types.SnapshotVolumesParam{})
+	assert.True(t, errors.As(err, &perr))
+	assert.Equal(t, OpVolumeCreate, perr.Operation)
+	_, err = client.FromString("{}")
+	assert.True(t, errors.As(err, &perr))
+	assert.Equal(t, OpTemplateAdmin, perr.Operation)
+	_, err = client.DeleteTemplate("t1")
+	assert.True(t, errors.As(err, &perr))
+	assert.Equal(t, OpTemplateAdmin, perr.Operation)
+
+	// nothing was sent for the rejected operations
+	assert.Equal(t, []string{
+		"GET /api/login",
+		"POST /api/types/User/instances/action/queryIdByKey",
+		"GET /api/instances/User::u1",
+	}, paths)
+
+	client.SetCapabilities(nil)
+	assert.Nil(t, client.checkCapability(OpVolumeCreate))
+}
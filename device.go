@@ -16,8 +16,6 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"reflect"
-	"time"
 
 	types "github.com/AnshumanPradipPatil1506/goscaleio/types/v1"
 )
@@ -47,8 +45,15 @@ func NewDeviceEx(client *Client, device *types.Device) *Device {
 // AttachDevice attaches a device
 func (sp *StoragePool) AttachDevice(
 	path string,
-	sdsID string) (string, error) {
-	defer TimeSpent("AttachDevice", time.Now())
+	sdsID string) (_ string, err error) {
+	c, end := sp.client.trace("StoragePool.AttachDevice")
+	defer end(&err)
+
+	c.audit("Device", "")
+
+	if err := c.checkCapability(OpDeviceAdmin); err != nil {
+		return "", err
+	}
 
 	deviceParam := &types.DeviceParam{
 		Name:                  path,
@@ -58,7 +63,7 @@ func (sp *StoragePool) AttachDevice(
 		TestMode:              "testAndActivate"}
 
 	dev := types.DeviceResp{}
-	err := sp.client.getJSONWithRetry(
+	err = c.getJSONWithRetry(
 		http.MethodPost, "/api/types/Device/instances",
 		deviceParam, &dev)
 	if err != nil {
@@ -69,15 +74,16 @@ func (sp *StoragePool) AttachDevice(
 }
 
 // GetDevice returns a device
-func (sp *StoragePool) GetDevice() ([]types.Device, error) {
-	defer TimeSpent("GetDevice", time.Now())
+func (sp *StoragePool) GetDevice() (_ []types.Device, err error) {
+	c, end := sp.client.trace("StoragePool.GetDevice")
+	defer end(&err)
 
 	path := fmt.Sprintf(
 		"/api/instances/StoragePool::%v/relationships/Device",
 		sp.StoragePool.ID)
 
 	var devices []types.Device
-	err := sp.client.getJSONWithRetry(
+	err = c.getJSONWithRetry(
 		http.MethodGet, path, nil, &devices)
 	if err != nil {
 		return nil, err
@@ -86,23 +92,43 @@ func (sp *StoragePool) GetDevice() ([]types.Device, error) {
 	return devices, nil
 }
 
-// FindDevice returns a Device
-func (sp *StoragePool) FindDevice(
-	field, value string) (*types.Device, error) {
-	defer TimeSpent("FindDevice", time.Now())
+// FindDevice returns the first Device matching all of the supplied options
+func (sp *StoragePool) FindDevice(opts ...FindOption) (_ *types.Device, err error) {
+	c, end := sp.client.trace("StoragePool.FindDevice")
+	defer end(&err)
+
+	devices, err := sp.withClient(c).FindAllDevice(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return devices[0], nil
+}
+
+// FindAllDevice returns every Device matching all of the supplied options
+func (sp *StoragePool) FindAllDevice(
+	opts ...FindOption) (_ []*types.Device, err error) {
+	c, end := sp.client.trace("StoragePool.FindAllDevice")
+	defer end(&err)
+
+	if err := deviceFinder.validate(opts); err != nil {
+		return nil, err
+	}
 
-	devices, err := sp.GetDevice()
+	devices, err := sp.withClient(c).GetDevice()
 	if err != nil {
 		return nil, err
 	}
 
-	for _, device := range devices {
-		valueOf := reflect.ValueOf(device)
-		switch {
-		case reflect.Indirect(valueOf).FieldByName(field).String() == value:
-			return &device, nil
-		}
+	matches := deviceFinder.match(devices, opts)
+	if len(matches) == 0 {
+		return nil, errors.New("Couldn't find DEV")
+	}
+
+	found := make([]*types.Device, 0, len(matches))
+	for _, i := range matches {
+		found = append(found, &devices[i])
 	}
 
-	return nil, errors.New("Couldn't find DEV")
+	return found, nil
 }
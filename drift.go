@@ -0,0 +1,197 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goscaleio
+
+import (
+	"fmt"
+	"sort"
+
+	types "github.com/AnshumanPradipPatil1506/goscaleio/types/v1"
+)
+
+// VolumeDriftState classifies a volume in a NodeDriftReport
+type VolumeDriftState string
+
+const (
+	// VolumeDriftHealthy means the volume is mapped to the local SDC and its device is present
+	VolumeDriftHealthy VolumeDriftState = "healthy"
+	// VolumeDriftMissingLocally means the volume is mapped to the local SDC but has no device
+	VolumeDriftMissingLocally VolumeDriftState = "missing-locally"
+	// VolumeDriftOrphanedLocally means a device is present for a volume that is not mapped to the local SDC
+	VolumeDriftOrphanedLocally VolumeDriftState = "orphaned-locally"
+	// VolumeDriftWrongSystem means a device is present for a system the local SDC is not connected to
+	VolumeDriftWrongSystem VolumeDriftState = "wrong-system"
+	// VolumeDriftDisconnected means a device is present for a volume of the system, but the local
+	// SDC is not connected to the system, so the state of the volume is unknown
+	VolumeDriftDisconnected VolumeDriftState = "disconnected"
+)
+
+// VolumeDrift is the state of one volume in a NodeDriftReport
+type VolumeDrift struct {
+	// SystemID is the system the volume belongs to
+	SystemID string
+	// VolumeID is the ID of the volume
+	VolumeID string
+	// State is the classification of the volume
+	State VolumeDriftState
+	// Volume is the gateway's view of the volume, nil if it is not mapped
+	Volume *types.Volume
+	// Local is the local device, nil if there is none
+	Local *SdcMappedVolume
+}
+
+// NodeDriftReport compares the volumes the gateway has mapped to the local
+// SDC with the volume devices present on the node
+type NodeDriftReport struct {
+	// SystemID is the system the report was made against
+	SystemID string
+	// Sdc is the local SDC as known to the system
+	Sdc *types.Sdc
+	// Connected reports whether the local SDC is connected to the system.
+	// If it is not, none of the system's volumes are healthy and those with
+	// a device are disconnected.
+	Connected bool
+	// Volumes are the volumes found, sorted by system ID, volume ID and state
+	Volumes []VolumeDrift
+}
+
+// Count returns the number of volumes in the given state
+func (r *NodeDriftReport) Count(state VolumeDriftState) int {
+	n := 0
+	for _, v := range r.Volumes {
+		if v.State == state {
+			n++
+		}
+	}
+	return n
+}
+
+// Healthy reports whether every volume in the report is healthy
+func (r *NodeDriftReport) Healthy() bool {
+	return r.Count(VolumeDriftHealthy) == len(r.Volumes)
+}
+
+// GetNodeDriftReport identifies the local SDC by its GUID, and classifies
+// each volume mapped to it and each volume device present on the node.
+// Devices of other systems the SDC is connected to are not reported. A
+// volume is only healthy if the SDC is connected to the system; while it is
+// not, the system's volumes that have a device are reported as disconnected.
+func (s *System) GetNodeDriftReport() (_ *NodeDriftReport, err error) {
+	c, end := s.client.trace("System.GetNodeDriftReport")
+	defer end(&err)
+
+	identity, err := GetLocalSdcIdentity()
+	if err != nil {
+		return nil, err
+	}
+
+	sdc, err := s.withClient(c).FindSdc(ByGUID(identity.GUID))
+	if err != nil {
+		return nil, fmt.Errorf("GetNodeDriftReport: SDC %s: %v", identity.GUID, err)
+	}
+
+	mapped, err := NewSdc(c, sdc.Sdc).GetVolume()
+	if err != nil {
+		return nil, err
+	}
+
+	local, err := GetLocalVolumeMap()
+	if err != nil {
+		return nil, err
+	}
+
+	systems, err := DrvCfgQuerySystems()
+	if err != nil {
+		return nil, err
+	}
+	connected := make(map[string]bool, len(*systems))
+	for _, sys := range *systems {
+		connected[normalizeSystemID(sys.SystemID)] = true
+	}
+	systemID := normalizeSystemID(s.System.ID)
+
+	report := &NodeDriftReport{
+		SystemID:  s.System.ID,
+		Sdc:       sdc.Sdc,
+		Connected: connected[systemID],
+	}
+
+	// the devices of the system are only trusted if the SDC is connected to
+	// it; otherwise their state is unknown
+	present, orphaned := VolumeDriftHealthy, VolumeDriftOrphanedLocally
+	if !report.Connected {
+		present, orphaned = VolumeDriftDisconnected, VolumeDriftDisconnected
+	}
+
+	localByID := make(map[string]*SdcMappedVolume)
+	for _, l := range local {
+		mdmID := normalizeSystemID(l.MdmID)
+		switch {
+		case mdmID == systemID:
+			localByID[l.VolumeID] = l
+		case !connected[mdmID]:
+			report.Volumes = append(report.Volumes, VolumeDrift{
+				SystemID: l.MdmID,
+				VolumeID: l.VolumeID,
+				State:    VolumeDriftWrongSystem,
+				Local:    l,
+			})
+		}
+	}
+
+	for _, vol := range mapped {
+		drift := VolumeDrift{
+			SystemID: s.System.ID,
+			VolumeID: vol.ID,
+			Volume:   vol,
+			State:    VolumeDriftMissingLocally,
+		}
+		if l, ok := localByID[vol.ID]; ok {
+			drift.State = present
+			drift.Local = l
+			delete(localByID, vol.ID)
+		}
+		report.Volumes = append(report.Volumes, drift)
+	}
+
+	for _, l := range localByID {
+		report.Volumes = append(report.Volumes, VolumeDrift{
+			SystemID: l.MdmID,
+			VolumeID: l.VolumeID,
+			State:    orphaned,
+			Local:    l,
+		})
+	}
+
+	sort.Slice(report.Volumes, func(i, j int) bool {
+		a, b := report.Volumes[i], report.Volumes[j]
+		if a.SystemID != b.SystemID {
+			return a.SystemID < b.SystemID
+		}
+		if a.VolumeID != b.VolumeID {
+			return a.VolumeID < b.VolumeID
+		}
+		return a.State < b.State
+	})
+
+	return report, nil
+}
+
+// normalizeSystemID returns the 16 digit form of a system ID, so that IDs
+// reported by the SDC and by the gateway compare equal
+func normalizeSystemID(systemID string) string {
+	if id, err := parseSystemID(systemID); err == nil {
+		return formatSystemID(id)
+	}
+	return systemID
+}
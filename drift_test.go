@@ -0,0 +1,123 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goscaleio
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	types "github.com/AnshumanPradipPatil1506/goscaleio/types/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GetNodeDriftReport(t *testing.T) {
+	prefix, driver := setupFakeDev(t)
+	// connected to the system under test and to one other system
+	driver.Systems = append(driver.Systems, ConfiguredCluster{SystemID: "2222222222222222"})
+
+	link := func(systemID, volumeID string) {
+		name := fmt.Sprintf("emc-vol-%s-%s", systemID, volumeID)
+		if err := os.Symlink("../../scinia", filepath.Join(prefix, "dev/disk/by-id", name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	link(testNodeSystemID, "a000000000000001")   // healthy
+	link(testNodeSystemID, "c000000000000003")   // orphaned
+	link("2222222222222222", "d000000000000004") // another connected system, ignored
+	link("3333333333333333", "e000000000000005") // disconnected system
+
+	sdcs := []types.Sdc{
+		{ID: "sdc0", SdcGUID: "11111111-2222-3333-4444-555555555555"},
+		{ID: testNodeSdcID, SdcGUID: mockGUID},
+	}
+	mapped := []types.Volume{{ID: "a000000000000001"}, {ID: "b000000000000002"}}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var resp interface{}
+		switch r.URL.Path {
+		case fmt.Sprintf("/api/instances/System::%s/relationships/Sdc", testNodeSystemID):
+			resp = sdcs
+		case fmt.Sprintf("/api/instances/Sdc::%s/relationships/Volume", testNodeSdcID):
+			resp = mapped
+		default:
+			t.Fatalf("unexpected path: %q", r.URL.Path)
+		}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Fatal(err)
+		}
+	}))
+	defer ts.Close()
+
+	client, err := NewClientWithArgs(ts.URL, "", true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	system := NewSystem(client)
+	system.System.ID = testNodeSystemID
+
+	report, err := system.GetNodeDriftReport()
+	assert.Nil(t, err)
+	if !assert.NotNil(t, report) {
+		return
+	}
+	assert.Equal(t, testNodeSdcID, report.Sdc.ID)
+
+	type row struct {
+		system, volume string
+		state          VolumeDriftState
+	}
+	var got []row
+	for _, v := range report.Volumes {
+		got = append(got, row{v.SystemID, v.VolumeID, v.State})
+	}
+	assert.Equal(t, []row{
+		{testNodeSystemID, "a000000000000001", VolumeDriftHealthy},
+		{testNodeSystemID, "b000000000000002", VolumeDriftMissingLocally},
+		{testNodeSystemID, "c000000000000003", VolumeDriftOrphanedLocally},
+		{"3333333333333333", "e000000000000005", VolumeDriftWrongSystem},
+	}, got)
+	assert.Equal(t, 1, report.Count(VolumeDriftHealthy))
+	assert.False(t, report.Healthy())
+	assert.True(t, report.Connected)
+
+	// an SDC that is not connected to the system has no healthy volumes, and
+	// the devices of the system are not mistaken for another system's
+	SetSDCDriver(NewFakeSDCDriver(mockGUID, ConfiguredCluster{SystemID: "2222222222222222"}))
+	report, err = system.GetNodeDriftReport()
+	assert.Nil(t, err)
+	if assert.NotNil(t, report) {
+		assert.False(t, report.Connected)
+		got = nil
+		for _, v := range report.Volumes {
+			got = append(got, row{v.SystemID, v.VolumeID, v.State})
+		}
+		assert.Equal(t, []row{
+			{testNodeSystemID, "a000000000000001", VolumeDriftDisconnected},
+			{testNodeSystemID, "b000000000000002", VolumeDriftMissingLocally},
+			{testNodeSystemID, "c000000000000003", VolumeDriftDisconnected},
+			{"3333333333333333", "e000000000000005", VolumeDriftWrongSystem},
+		}, got)
+		assert.Equal(t, 2, report.Count(VolumeDriftDisconnected))
+		assert.False(t, report.Healthy())
+	}
+
+	// an SDC unknown to the system is an error
+	SetSDCDriver(NewFakeSDCDriver("4D2C2B9A-3E1F-4A77-8C55-0B9E6F7A1234"))
+	_, err = system.GetNodeDriftReport()
+	assert.NotNil(t, err)
+}
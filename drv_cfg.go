@@ -13,10 +13,15 @@
 package goscaleio
 
 import (
+	"encoding/binary"
+	"errors"
 	"fmt"
+	"net"
 	"os"
+	"os/exec"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"unsafe"
 
@@ -32,17 +37,30 @@ const (
 	_IOCTLRescan    = 10
 	// IOCTLDevice is the default device to send queries to
 	IOCTLDevice = "/dev/scini"
-	mockGUID    = "9E56672F-2F4B-4A42-BFF4-88B6846FBFDA"
-	mockSystem  = "000000000001"
+	// DefaultMdmPort is the port the SDC uses to reach an MDM
+	DefaultMdmPort = 6611
+	mockGUID       = "9E56672F-2F4B-4A42-BFF4-88B6846FBFDA"
+	mockSystem     = "000000000001"
 )
 
 var (
 	// SDCDevice is the device used to communicate with the SDC
 	SDCDevice = IOCTLDevice
-	// SCINIMockMode is used for testing upper layer code that attempts to call these methods
+	// SCINIMockMode is used for testing upper layer code that attempts to call these methods.
+	//
+	// Deprecated: install a FakeSDCDriver with SetSDCDriver instead.
 	SCINIMockMode = false
 )
 
+// drvCfgCommand runs DrvCfgBinary with the given arguments and returns its
+// combined output
+var drvCfgCommand = func(args ...string) ([]byte, error) {
+	// #nosec G204, DrvCfgBinary is set by the application
+	return exec.Command(DrvCfgBinary, args...).CombinedOutput()
+}
+
+var errNoMdmAddress = errors.New("at least one MDM address is required")
+
 type ioctlGUID struct {
 	rc         [8]byte
 	uuid       [16]byte
@@ -50,25 +68,275 @@ type ioctlGUID struct {
 	netIDTime  uint32
 }
 
+// SDCDriver is the interface to the SDC kernel module. The default driver
+// talks to SDCDevice through ioctl; tests can install a FakeSDCDriver with
+// SetSDCDriver.
+type SDCDriver interface {
+	// IsInstalled reports whether the SDC kernel module is loaded
+	IsInstalled() bool
+	// QueryGUID returns the GUID of the SDC
+	QueryGUID() (string, error)
+	// QuerySystems returns the MDM clusters the SDC is connected to
+	QuerySystems() ([]ConfiguredCluster, error)
+	// Rescan asks the SDC to rescan for mapped volumes and returns the return code
+	Rescan() (string, error)
+	// AddMdm connects the SDC to the MDM cluster reachable at addrs
+	AddMdm(addrs []MdmAddress) error
+	// RemoveMdm disconnects the SDC from the MDM cluster with the given system ID
+	RemoveMdm(systemID string) error
+}
+
+var (
+	sdcDriverMu sync.Mutex
+	sdcDriver   SDCDriver
+)
+
+// SetSDCDriver replaces the driver used by the DrvCfg functions. Passing nil
+// restores the default ioctl driver. The cached local SDC identity is reset.
+func SetSDCDriver(d SDCDriver) {
+	sdcDriverMu.Lock()
+	sdcDriver = d
+	sdcDriverMu.Unlock()
+	ResetLocalSdcIdentity()
+}
+
+// GetSDCDriver returns the driver used by the DrvCfg functions
+func GetSDCDriver() SDCDriver {
+	sdcDriverMu.Lock()
+	defer sdcDriverMu.Unlock()
+	if sdcDriver != nil {
+		return sdcDriver
+	}
+	if SCINIMockMode == true {
+		return newLegacyMockSDCDriver()
+	}
+	return &IoctlSDCDriver{Device: SDCDevice}
+}
+
+// newLegacyMockSDCDriver returns the single-system fake used by SCINIMockMode
+func newLegacyMockSDCDriver() *FakeSDCDriver {
+	return NewFakeSDCDriver(mockGUID, ConfiguredCluster{
+		SystemID: mockSystem,
+		SdcID:    mockGUID,
+	})
+}
+
+// IoctlSDCDriver is the SDCDriver that sends ioctl requests to the SDC device
+type IoctlSDCDriver struct {
+	// Device is the SDC device, IOCTLDevice by default
+	Device string
+}
+
+func (d *IoctlSDCDriver) device() string {
+	if d.Device == "" {
+		return IOCTLDevice
+	}
+	return d.Device
+}
+
 // DrvCfgIsSDCInstalled will check to see if the SDC kernel module is loaded
 func DrvCfgIsSDCInstalled() bool {
-	if SCINIMockMode == true {
-		return true
+	return GetSDCDriver().IsInstalled()
+}
+
+// DrvCfgQueryGUID will return the GUID of the locally installed SDC
+func DrvCfgQueryGUID() (string, error) {
+	return GetSDCDriver().QueryGUID()
+}
+
+// DrvCfgQueryRescan preforms a rescan
+func DrvCfgQueryRescan() (string, error) {
+	return GetSDCDriver().Rescan()
+}
+
+// DrvCfgQuerySystems will return the configured MDM endpoints for the locally installed SDC
+func DrvCfgQuerySystems() (*[]ConfiguredCluster, error) {
+	clusters, err := GetSDCDriver().QuerySystems()
+	if err != nil {
+		return nil, err
+	}
+	return &clusters, nil
+}
+
+// DrvCfgAddMdm connects the local SDC to the MDM cluster reachable at the
+// given addresses. Addresses without a port use DefaultMdmPort. The cluster
+// is also added to DrvCfgFilePath so the SDC reconnects to it after a
+// restart. The file is read and edited before the SDC is changed, and the
+// SDC is disconnected again if the file cannot be written.
+func DrvCfgAddMdm(addrs []MdmAddress) error {
+	if len(addrs) == 0 {
+		return errNoMdmAddress
+	}
+	if len(addrs) > maxNetAddresses {
+		return fmt.Errorf("at most %d MDM addresses are supported", maxNetAddresses)
+	}
+	withPorts := make([]MdmAddress, len(addrs))
+	for i, a := range addrs {
+		if a.Port == 0 {
+			a.Port = DefaultMdmPort
+		}
+		withPorts[i] = a
+	}
+	if err := checkMdmAddresses(withPorts); err != nil {
+		return err
+	}
+
+	cfg, err := ReadDrvCfgFile(DrvCfgFilePath)
+	if err != nil {
+		return err
+	}
+	changed, err := cfg.AddMdm(withPorts)
+	if err != nil {
+		return err
+	}
+
+	driver := GetSDCDriver()
+	if err := driver.AddMdm(withPorts); err != nil {
+		return err
+	}
+	if !changed {
+		return nil
+	}
+	if err := cfg.Write(DrvCfgFilePath); err != nil {
+		return rollbackAddMdm(driver, withPorts, err)
+	}
+	return nil
+}
+
+// rollbackAddMdm disconnects the SDC from the cluster DrvCfgAddMdm connected
+// it to after writing DrvCfgFilePath failed with err
+func rollbackAddMdm(driver SDCDriver, addrs []MdmAddress, err error) error {
+	clusters, qerr := driver.QuerySystems()
+	if qerr != nil {
+		return fmt.Errorf("%v, and the MDM could not be removed again: %v", err, qerr)
+	}
+	for _, c := range clusters {
+		if mdmAddressesOverlap(c.Addresses, addrs) {
+			if rerr := driver.RemoveMdm(c.SystemID); rerr != nil {
+				return fmt.Errorf("%v, and the MDM could not be removed again: %v", err, rerr)
+			}
+			return err
+		}
+	}
+	return fmt.Errorf("%v, and the MDM could not be removed again: no connected system has its addresses", err)
+}
+
+// DrvCfgRemoveMdm disconnects the local SDC from the MDM cluster with the
+// given system ID. The cluster is also removed from DrvCfgFilePath, found by
+// the MDM addresses the SDC reports for it, so the SDC does not reconnect
+// after a restart. The addresses are resolved and the file is read and
+// edited before the SDC is changed, and the SDC is reconnected if the file
+// cannot be written.
+func DrvCfgRemoveMdm(systemID string) error {
+	id, err := parseSystemID(systemID)
+	if err != nil {
+		return err
+	}
+	systemID = formatSystemID(id)
+
+	driver := GetSDCDriver()
+	clusters, err := driver.QuerySystems()
+	if err != nil {
+		return err
+	}
+	connected := false
+	var addrs []MdmAddress
+	for _, c := range clusters {
+		if cid, err := parseSystemID(c.SystemID); err == nil && cid == id {
+			connected = true
+			addrs = append(addrs, c.Addresses...)
+		}
+	}
+	if !connected {
+		return fmt.Errorf("system %s is not connected", systemID)
+	}
+	if len(addrs) == 0 {
+		return fmt.Errorf("the MDM addresses of system %s are unknown, %s cannot be updated",
+			systemID, DrvCfgFilePath)
+	}
+
+	cfg, err := ReadDrvCfgFile(DrvCfgFilePath)
+	if err != nil {
+		return err
 	}
-	// Check to see if the SDC device is available
-	info, err := os.Stat(SDCDevice)
+	changed := cfg.RemoveMdm(addrs)
+
+	if err := driver.RemoveMdm(systemID); err != nil {
+		return err
+	}
+	if !changed {
+		return nil
+	}
+	if err := cfg.Write(DrvCfgFilePath); err != nil {
+		if aerr := driver.AddMdm(addrs); aerr != nil {
+			return fmt.Errorf("%v, and the MDM could not be added again: %v", err, aerr)
+		}
+		return err
+	}
+	return nil
+}
+
+// checkMdmAddresses returns an error if the SDC cannot connect to any of
+// addrs. The SDC only connects to MDMs on DefaultMdmPort.
+func checkMdmAddresses(addrs []MdmAddress) error {
+	for _, a := range addrs {
+		if a.IP == nil {
+			return fmt.Errorf("invalid MDM address %v", a)
+		}
+		if a.Port != DefaultMdmPort {
+			return fmt.Errorf("cannot connect to MDM %v, only port %d is supported",
+				a, DefaultMdmPort)
+		}
+	}
+	return nil
+}
+
+// mdmAddressesOverlap reports whether a and b share an MDM IP
+func mdmAddressesOverlap(a, b []MdmAddress) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x.IP.Equal(y.IP) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseSystemID returns the numeric value of a hex system ID of up to 16
+// digits. Shorter IDs are zero-padded.
+func parseSystemID(systemID string) (uint64, error) {
+	id, err := strconv.ParseUint(systemID, 16, 64)
+	if err != nil || len(systemID) > 16 {
+		return 0, fmt.Errorf("invalid system ID %q", systemID)
+	}
+	return id, nil
+}
+
+// formatSystemID returns the 16 digit form of a system ID, as the SDC
+// reports it
+func formatSystemID(id uint64) string {
+	return fmt.Sprintf("%016x", id)
+}
+
+// errRC is the error returned when the SDC answers a request with a return
+// code other than 65 (success)
+func errRC(request string, rc int64) error {
+	return fmt.Errorf("Request to %s failed, RC=%d", request, rc)
+}
+
+// IsInstalled will check to see if the SDC device is available
+func (d *IoctlSDCDriver) IsInstalled() bool {
+	info, err := os.Stat(d.device())
 	if err != nil {
 		return false
 	}
 	return !info.IsDir()
 }
 
-// DrvCfgQueryGUID will return the GUID of the locally installed SDC
-func DrvCfgQueryGUID() (string, error) {
-	if SCINIMockMode == true {
-		return mockGUID, nil
-	}
-	f, err := os.Open(SDCDevice)
+// QueryGUID will return the GUID of the locally installed SDC
+func (d *IoctlSDCDriver) QueryGUID() (string, error) {
+	f, err := os.Open(d.device())
 	if err != nil {
 		return "", err
 	}
@@ -89,7 +357,7 @@ func DrvCfgQueryGUID() (string, error) {
 
 	rc, err := strconv.ParseInt(hex.EncodeToString(buf[0].rc[0:1]), 16, 64)
 	if rc != 65 {
-		return "", fmt.Errorf("Request to query GUID failed, RC=%d", rc)
+		return "", errRC("query GUID", rc)
 	}
 
 	g := hex.EncodeToString(buf[0].uuid[:len(buf[0].uuid)])
@@ -98,10 +366,10 @@ func DrvCfgQueryGUID() (string, error) {
 	return discoveredGUID, nil
 }
 
-// DrvCfgQueryRescan preforms a rescan
-func DrvCfgQueryRescan() (string, error) {
+// Rescan preforms a rescan
+func (d *IoctlSDCDriver) Rescan() (string, error) {
 
-	f, err := os.Open(SDCDevice)
+	f, err := os.Open(d.device())
 	if err != nil {
 		return "", fmt.Errorf("Powerflex SDC is not installed")
 	}
@@ -124,11 +392,84 @@ func DrvCfgQueryRescan() (string, error) {
 	return rcCode, err
 }
 
-// internal, opaque to us, struct of IP addresses
+// AddMdm connects the SDC to the MDM cluster reachable at addrs by running
+// drv_cfg --add_mdm. The SDC's ioctls for adding and removing an MDM
+// cluster are not documented, so the supported utility is used instead.
+func (d *IoctlSDCDriver) AddMdm(addrs []MdmAddress) error {
+	if err := checkMdmAddresses(addrs); err != nil {
+		return err
+	}
+
+	ips := make([]string, len(addrs))
+	for i, a := range addrs {
+		ips[i] = a.IP.String()
+	}
+	out, err := drvCfgCommand("--add_mdm", "--ip", strings.Join(ips, ","))
+	if err != nil {
+		return fmt.Errorf("addMDM error: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// RemoveMdm disconnects the SDC from the MDM cluster with the given system
+// ID by running drv_cfg --remove_mdm
+func (d *IoctlSDCDriver) RemoveMdm(systemID string) error {
+	id, err := parseSystemID(systemID)
+	if err != nil {
+		return err
+	}
+
+	out, err := drvCfgCommand("--remove_mdm", "--mdm_id", formatSystemID(id))
+	if err != nil {
+		return fmt.Errorf("removeMDM error: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// netAddress is one MDM socket address as reported by the SDC: the address
+// family in host byte order, the port in network byte order and the address
+// itself, of which an IPv4 address uses the first four and an IPv6 address
+// all sixteen bytes, followed by four bytes of padding
 type netAddress struct {
 	opaque [24]byte
 }
 
+const (
+	netAddressFamilyIPv4 = 2  // AF_INET
+	netAddressFamilyIPv6 = 10 // AF_INET6
+	maxNetAddresses      = 16
+)
+
+// MdmAddress is a socket address the SDC uses to reach an MDM
+type MdmAddress struct {
+	// IP is the IPv4 or IPv6 address of the MDM
+	IP net.IP
+	// Port is the MDM port
+	Port int
+}
+
+func (a MdmAddress) String() string {
+	return net.JoinHostPort(a.IP.String(), strconv.Itoa(a.Port))
+}
+
+// decode returns the address, or false if the family is not IPv4 or IPv6
+func (a *netAddress) decode() (MdmAddress, bool) {
+	family := nativeEndian.Uint16(a.opaque[0:2])
+	port := int(binary.BigEndian.Uint16(a.opaque[2:4]))
+
+	switch family {
+	case netAddressFamilyIPv4:
+		ip := make(net.IP, net.IPv4len)
+		copy(ip, a.opaque[4:4+net.IPv4len])
+		return MdmAddress{IP: ip, Port: port}, true
+	case netAddressFamilyIPv6:
+		ip := make(net.IP, net.IPv6len)
+		copy(ip, a.opaque[4:4+net.IPv6len])
+		return MdmAddress{IP: ip, Port: port}, true
+	}
+	return MdmAddress{}, false
+}
+
 type ioctlMdmInfo struct {
 	filler     [4]byte
 	mdmIDL     uint32
@@ -140,7 +481,7 @@ type ioctlMdmInfo struct {
 	/*Total amount of socket addresses*/
 	numSockAddrs uint64
 	/*The MDM socket addresses*/
-	addresses [16]netAddress
+	addresses [maxNetAddresses]netAddress
 }
 
 // ConfiguredCluster contains configuration information for one connected system
@@ -149,6 +490,30 @@ type ConfiguredCluster struct {
 	SystemID string
 	// SdcID is the ID of the SDC as known to the MDM cluster
 	SdcID string
+	// InstallID is the installation ID of the MDM cluster
+	InstallID string
+	// Addresses are the MDM socket addresses the SDC is configured with
+	Addresses []MdmAddress
+}
+
+// cluster decodes the MDM information returned by the SDC
+func (m *ioctlMdmInfo) cluster() ConfiguredCluster {
+	c := ConfiguredCluster{
+		SystemID:  fmt.Sprintf("%8.8x%8.8x", m.mdmIDH, m.mdmIDL),
+		SdcID:     fmt.Sprintf("%8.8x%8.8x", m.sdcIDH, m.sdcIDL),
+		InstallID: fmt.Sprintf("%8.8x%8.8x", m.installIDH, m.installIDL),
+	}
+
+	n := m.numSockAddrs
+	if n > maxNetAddresses {
+		n = maxNetAddresses
+	}
+	for i := uint64(0); i < n; i++ {
+		if addr, ok := m.addresses[i].decode(); ok {
+			c.Addresses = append(c.Addresses, addr)
+		}
+	}
+	return c
 }
 
 type ioctlQueryMDMs struct {
@@ -160,22 +525,11 @@ type ioctlQueryMDMs struct {
 	mdms [20]ioctlMdmInfo
 }
 
-//DrvCfgQuerySystems will return the configured MDM endpoints for the locally installed SDC
-func DrvCfgQuerySystems() (*[]ConfiguredCluster, error) {
+// QuerySystems will return the configured MDM endpoints for the locally installed SDC
+func (d *IoctlSDCDriver) QuerySystems() ([]ConfiguredCluster, error) {
 	clusters := make([]ConfiguredCluster, 0)
 
-	if SCINIMockMode == true {
-		systemID := mockSystem
-		sdcID := mockGUID
-		aCluster := ConfiguredCluster{
-			SystemID: systemID,
-			SdcID:    sdcID,
-		}
-		clusters = append(clusters, aCluster)
-		return &clusters, nil
-	}
-
-	f, err := os.Open(SDCDevice)
+	f, err := os.Open(d.device())
 	if err != nil {
 		return nil, err
 	}
@@ -199,22 +553,14 @@ func DrvCfgQuerySystems() (*[]ConfiguredCluster, error) {
 
 	rc, err := strconv.ParseInt(hex.EncodeToString(buf.rc[0:1]), 16, 64)
 	if rc != 65 {
-		return nil, fmt.Errorf("Request to query MDM failed, RC=%d", rc)
+		return nil, errRC("query MDM", rc)
 	}
 
-	for i := uint16(0); i < buf.numMdms; i++ {
-		systemID := fmt.Sprintf("%8.8x%8.8x",
-			buf.mdms[i].mdmIDH, buf.mdms[i].mdmIDL)
-		sdcID := fmt.Sprintf("%8.8x%8.8x",
-			buf.mdms[i].sdcIDH, buf.mdms[i].sdcIDL)
-		aCluster := ConfiguredCluster{
-			SystemID: systemID,
-			SdcID:    sdcID,
-		}
-		clusters = append(clusters, aCluster)
+	for i := uint16(0); i < buf.numMdms && int(i) < len(buf.mdms); i++ {
+		clusters = append(clusters, buf.mdms[i].cluster())
 	}
 
-	return &clusters, nil
+	return clusters, nil
 }
 
 func ioctl(fd, op, arg uintptr) error {
@@ -225,6 +571,16 @@ func ioctl(fd, op, arg uintptr) error {
 	return nil
 }
 
+// nativeEndian is the byte order of the kernel structures
+var nativeEndian binary.ByteOrder = func() binary.ByteOrder {
+	x := uint16(1)
+	// #nosec G103, only used to detect the byte order
+	if *(*byte)(unsafe.Pointer(&x)) == 1 {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}()
+
 func _IO(t uintptr, nr uintptr) uintptr {
 	return _IOC(0x0, t, nr, 0)
 }
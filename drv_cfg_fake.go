@@ -0,0 +1,258 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goscaleio
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// SDCOperation names a request sent to the SDC kernel module
+type SDCOperation string
+
+const (
+	// SDCOpQueryGUID is the query GUID request
+	SDCOpQueryGUID SDCOperation = "QueryGUID"
+	// SDCOpQuerySystems is the query MDM request
+	SDCOpQuerySystems SDCOperation = "QuerySystems"
+	// SDCOpRescan is the rescan request
+	SDCOpRescan SDCOperation = "Rescan"
+	// SDCOpAddMdm is the add MDM request
+	SDCOpAddMdm SDCOperation = "AddMdm"
+	// SDCOpRemoveMdm is the remove MDM request
+	SDCOpRemoveMdm SDCOperation = "RemoveMdm"
+)
+
+// rcSuccess is the return code of a successful SDC request
+const rcSuccess = 65
+
+// FakeSDCDriver is an in-memory SDCDriver for testing code that talks to
+// the SDC. It can report any number of MDM clusters, fail any operation with
+// an error or with a non-success return code, and counts calls per operation.
+//
+// The exported fields must be set before the driver is in use. Once it may
+// be called concurrently, change its behaviour with SetInstalled, SetError
+// and SetRC, which hold the driver's lock.
+type FakeSDCDriver struct {
+	mu sync.Mutex
+
+	// Installed is returned by IsInstalled
+	Installed bool
+	// GUID is returned by QueryGUID
+	GUID string
+	// Systems is returned by QuerySystems
+	Systems []ConfiguredCluster
+	// Errors makes an operation fail with the given error
+	Errors map[SDCOperation]error
+	// RC makes an operation return the given kernel return code instead of
+	// 65 (success). Like the ioctl driver, a rescan reports its return code
+	// rather than failing.
+	RC map[SDCOperation]int64
+	// NextSystemID, if set, is the system ID given to the next MDM cluster
+	// added with AddMdm; otherwise one is generated
+	NextSystemID string
+	// OnRescan, if set, is called on every successful rescan, for example to
+	// create the /dev/disk/by-id links of newly mapped volumes
+	OnRescan func()
+
+	calls map[SDCOperation]int
+}
+
+// NewFakeSDCDriver returns an installed FakeSDCDriver with the given GUID
+// connected to the given systems
+func NewFakeSDCDriver(guid string, systems ...ConfiguredCluster) *FakeSDCDriver {
+	return &FakeSDCDriver{
+		Installed: true,
+		GUID:      guid,
+		Systems:   systems,
+		Errors:    make(map[SDCOperation]error),
+		RC:        make(map[SDCOperation]int64),
+	}
+}
+
+// SetInstalled sets the value returned by IsInstalled
+func (f *FakeSDCDriver) SetInstalled(installed bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Installed = installed
+}
+
+// SetError makes op fail with err, or stops it failing if err is nil
+func (f *FakeSDCDriver) SetError(op SDCOperation, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err == nil {
+		delete(f.Errors, op)
+		return
+	}
+	if f.Errors == nil {
+		f.Errors = make(map[SDCOperation]error)
+	}
+	f.Errors[op] = err
+}
+
+// SetRC makes op answer with the given kernel return code; 65 (success)
+// restores the default
+func (f *FakeSDCDriver) SetRC(op SDCOperation, rc int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if rc == rcSuccess {
+		delete(f.RC, op)
+		return
+	}
+	if f.RC == nil {
+		f.RC = make(map[SDCOperation]int64)
+	}
+	f.RC[op] = rc
+}
+
+// Calls returns how many times op was requested
+func (f *FakeSDCDriver) Calls(op SDCOperation) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[op]
+}
+
+// begin records a call to op and returns the configured return code and error
+func (f *FakeSDCDriver) begin(op SDCOperation) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.calls == nil {
+		f.calls = make(map[SDCOperation]int)
+	}
+	f.calls[op]++
+
+	rc := int64(rcSuccess)
+	if v, ok := f.RC[op]; ok {
+		rc = v
+	}
+	return rc, f.Errors[op]
+}
+
+// IsInstalled returns Installed
+func (f *FakeSDCDriver) IsInstalled() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.Installed
+}
+
+// QueryGUID returns GUID
+func (f *FakeSDCDriver) QueryGUID() (string, error) {
+	rc, err := f.begin(SDCOpQueryGUID)
+	if err != nil {
+		return "", err
+	}
+	if rc != rcSuccess {
+		return "", errRC("query GUID", rc)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.GUID, nil
+}
+
+// QuerySystems returns a copy of Systems
+func (f *FakeSDCDriver) QuerySystems() ([]ConfiguredCluster, error) {
+	rc, err := f.begin(SDCOpQuerySystems)
+	if err != nil {
+		return nil, err
+	}
+	if rc != rcSuccess {
+		return nil, errRC("query MDM", rc)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	clusters := make([]ConfiguredCluster, len(f.Systems))
+	copy(clusters, f.Systems)
+	return clusters, nil
+}
+
+// Rescan returns the configured return code and, if it is 65 (success),
+// calls OnRescan
+func (f *FakeSDCDriver) Rescan() (string, error) {
+	rc, err := f.begin(SDCOpRescan)
+	if err != nil || rc != rcSuccess {
+		return strconv.FormatInt(rc, 10), err
+	}
+
+	f.mu.Lock()
+	onRescan := f.OnRescan
+	f.mu.Unlock()
+	if onRescan != nil {
+		onRescan()
+	}
+	return strconv.FormatInt(rc, 10), nil
+}
+
+// AddMdm adds a connected system with the given addresses to Systems. Like
+// the ioctl driver, it rejects addresses the SDC cannot connect to, and it
+// fails if any of the addresses already belongs to a connected system.
+func (f *FakeSDCDriver) AddMdm(addrs []MdmAddress) error {
+	if err := checkMdmAddresses(addrs); err != nil {
+		return err
+	}
+	rc, err := f.begin(SDCOpAddMdm)
+	if err != nil {
+		return err
+	}
+	if rc != rcSuccess {
+		return errRC("add MDM", rc)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, c := range f.Systems {
+		for _, existing := range c.Addresses {
+			for _, a := range addrs {
+				if existing.IP.Equal(a.IP) {
+					return fmt.Errorf("MDM %s already belongs to system %s", a.IP, c.SystemID)
+				}
+			}
+		}
+	}
+
+	systemID := f.NextSystemID
+	f.NextSystemID = ""
+	if systemID == "" {
+		systemID = fmt.Sprintf("%016x", len(f.Systems)+1)
+	}
+	f.Systems = append(f.Systems, ConfiguredCluster{
+		SystemID:  systemID,
+		SdcID:     fmt.Sprintf("%016x", len(f.Systems)+1),
+		Addresses: append([]MdmAddress{}, addrs...),
+	})
+	return nil
+}
+
+// RemoveMdm removes the system with the given ID from Systems
+func (f *FakeSDCDriver) RemoveMdm(systemID string) error {
+	rc, err := f.begin(SDCOpRemoveMdm)
+	if err != nil {
+		return err
+	}
+	if rc != rcSuccess {
+		return errRC("remove MDM", rc)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, c := range f.Systems {
+		if c.SystemID == systemID {
+			f.Systems = append(f.Systems[:i], f.Systems[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("system %s is not connected", systemID)
+}
@@ -0,0 +1,298 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goscaleio
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultDrvCfgFile is where the SDC reads its persistent configuration at boot
+const DefaultDrvCfgFile = "/etc/emc/scaleio/drv_cfg.txt"
+
+const (
+	drvCfgGUIDKey = "ini_guid"
+	drvCfgMdmKey  = "mdm"
+)
+
+// DrvCfgFilePath is the drv_cfg.txt that DrvCfgAddMdm and DrvCfgRemoveMdm
+// keep up to date so MDM changes survive a restart of the SDC
+var DrvCfgFilePath = DefaultDrvCfgFile
+
+// DrvCfgMdm is one MDM cluster line of drv_cfg.txt
+type DrvCfgMdm struct {
+	// Addresses are the MDM addresses of the cluster. drv_cfg.txt lists bare
+	// IPs, as the SDC only connects to MDMs on DefaultMdmPort.
+	Addresses []MdmAddress
+	// Options are the drv_cfg options that follow the address list, such as --file
+	Options []string
+}
+
+// drvCfgLine is one line of drv_cfg.txt. Lines that were read are written
+// back as they were unless they are edited.
+type drvCfgLine struct {
+	// text is the line as read, empty once the line is edited or if it was
+	// added
+	text string
+	// key is drvCfgGUIDKey or drvCfgMdmKey, empty for every other line
+	key string
+	// guid is the GUID of an ini_guid line
+	guid string
+	// mdm is the cluster of an mdm line
+	mdm DrvCfgMdm
+}
+
+func (l *drvCfgLine) String() string {
+	if l.text != "" {
+		return l.text
+	}
+	switch l.key {
+	case drvCfgGUIDKey:
+		return drvCfgGUIDKey + " " + l.guid
+	case drvCfgMdmKey:
+		fields := append([]string{drvCfgMdmKey, formatMdmAddresses(l.mdm.Addresses)}, l.mdm.Options...)
+		return strings.Join(fields, " ")
+	}
+	return ""
+}
+
+// DrvCfgFile is the persistent SDC configuration, drv_cfg.txt. It holds the
+// SDC GUID and one line per MDM cluster listing the cluster's MDM addresses
+// and drv_cfg options. Lines are written back in the order they were read, and
+// lines the library does not understand, including comments, are kept as
+// they are.
+type DrvCfgFile struct {
+	// GUID is the SDC GUID
+	GUID string
+
+	lines []*drvCfgLine
+}
+
+// ReadDrvCfgFile reads the SDC configuration from path. A missing file reads
+// as an empty configuration.
+func ReadDrvCfgFile(path string) (*DrvCfgFile, error) {
+	cfg := &DrvCfgFile{}
+
+	data, err := ioutil.ReadFile(filepath.Clean(path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := &drvCfgLine{text: scanner.Text()}
+		cfg.lines = append(cfg.lines, line)
+
+		fields := strings.Fields(line.text)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		switch fields[0] {
+		case drvCfgGUIDKey:
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("%s:%d: malformed %s line", path, n, drvCfgGUIDKey)
+			}
+			line.key = drvCfgGUIDKey
+			line.guid = fields[1]
+			cfg.GUID = fields[1]
+		case drvCfgMdmKey:
+			if len(fields) < 2 {
+				return nil, fmt.Errorf("%s:%d: malformed %s line", path, n, drvCfgMdmKey)
+			}
+			addrs, err := parseMdmAddresses(fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %v", path, n, err)
+			}
+			line.key = drvCfgMdmKey
+			line.mdm = DrvCfgMdm{Addresses: addrs, Options: fields[2:]}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// parseMdmAddresses parses a comma separated list of MDM IPs
+func parseMdmAddresses(list string) ([]MdmAddress, error) {
+	var addrs []MdmAddress
+	for _, entry := range strings.Split(list, ",") {
+		ip := net.ParseIP(entry)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid MDM address %q", entry)
+		}
+		addrs = append(addrs, MdmAddress{IP: ip, Port: DefaultMdmPort})
+	}
+	return addrs, nil
+}
+
+// formatMdmAddresses returns the drv_cfg.txt form of addrs, their IPs
+func formatMdmAddresses(addrs []MdmAddress) string {
+	entries := make([]string, len(addrs))
+	for i, a := range addrs {
+		entries[i] = a.IP.String()
+	}
+	return strings.Join(entries, ",")
+}
+
+// Mdms returns the configured MDM clusters in file order
+func (c *DrvCfgFile) Mdms() []DrvCfgMdm {
+	var mdms []DrvCfgMdm
+	for _, l := range c.lines {
+		if l.key == drvCfgMdmKey {
+			mdms = append(mdms, DrvCfgMdm{
+				Addresses: append([]MdmAddress{}, l.mdm.Addresses...),
+				Options:   append([]string{}, l.mdm.Options...),
+			})
+		}
+	}
+	return mdms
+}
+
+// AddMdm adds an MDM cluster with the given addresses after the last
+// configured cluster. Addresses without a port use DefaultMdmPort, the only
+// port the SDC connects to; any other port is an error. It returns false if
+// the IP of any of the addresses already belongs to a configured cluster.
+func (c *DrvCfgFile) AddMdm(addrs []MdmAddress) (bool, error) {
+	if len(addrs) == 0 {
+		return false, errNoMdmAddress
+	}
+	withPorts := make([]MdmAddress, len(addrs))
+	for i, a := range addrs {
+		if a.Port == 0 {
+			a.Port = DefaultMdmPort
+		}
+		withPorts[i] = a
+	}
+	if err := checkMdmAddresses(withPorts); err != nil {
+		return false, err
+	}
+	if c.findMdm(withPorts) >= 0 {
+		return false, nil
+	}
+
+	line := &drvCfgLine{
+		key: drvCfgMdmKey,
+		mdm: DrvCfgMdm{Addresses: withPorts},
+	}
+	at := len(c.lines)
+	for i, l := range c.lines {
+		if l.key == drvCfgMdmKey {
+			at = i + 1
+		}
+	}
+	c.lines = append(c.lines[:at], append([]*drvCfgLine{line}, c.lines[at:]...)...)
+	return true, nil
+}
+
+// RemoveMdm removes the MDM cluster that the IP of any of the given addresses
+// belongs to. It returns false if no configured cluster matched.
+func (c *DrvCfgFile) RemoveMdm(addrs []MdmAddress) bool {
+	i := c.findMdm(addrs)
+	if i < 0 {
+		return false
+	}
+	c.lines = append(c.lines[:i], c.lines[i+1:]...)
+	return true
+}
+
+// findMdm returns the index of the line of the cluster holding the IP of
+// any of addrs, or -1
+func (c *DrvCfgFile) findMdm(addrs []MdmAddress) int {
+	for i, l := range c.lines {
+		if l.key == drvCfgMdmKey && mdmAddressesOverlap(l.mdm.Addresses, addrs) {
+			return i
+		}
+	}
+	return -1
+}
+
+// syncGUID updates the ini_guid line to GUID, adding one before the first
+// MDM cluster if there is none
+func (c *DrvCfgFile) syncGUID() {
+	for _, l := range c.lines {
+		if l.key == drvCfgGUIDKey {
+			if l.guid != c.GUID {
+				l.guid = c.GUID
+				l.text = ""
+			}
+			return
+		}
+	}
+	if c.GUID == "" {
+		return
+	}
+
+	line := &drvCfgLine{key: drvCfgGUIDKey, guid: c.GUID}
+	at := len(c.lines)
+	for i, l := range c.lines {
+		if l.key == drvCfgMdmKey {
+			at = i
+			break
+		}
+	}
+	c.lines = append(c.lines[:at], append([]*drvCfgLine{line}, c.lines[at:]...)...)
+}
+
+// Write saves the configuration to path. The file is replaced atomically so
+// the SDC never sees a partially written configuration, and keeps the mode
+// of the file it replaces.
+func (c *DrvCfgFile) Write(path string) error {
+	c.syncGUID()
+
+	var b bytes.Buffer
+	for _, l := range c.lines {
+		fmt.Fprintln(&b, l)
+	}
+
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,407 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goscaleio
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SDCDriverSelection(t *testing.T) {
+	defer SetSDCDriver(nil)
+
+	_, ok := GetSDCDriver().(*IoctlSDCDriver)
+	assert.True(t, ok)
+
+	SCINIMockMode = true
+	guid, err := DrvCfgQueryGUID()
+	SCINIMockMode = false
+	assert.Nil(t, err)
+	assert.Equal(t, mockGUID, guid)
+
+	fake := NewFakeSDCDriver("guid")
+	SetSDCDriver(fake)
+	assert.Equal(t, fake, GetSDCDriver())
+	assert.True(t, DrvCfgIsSDCInstalled())
+}
+
+func Test_FakeSDCDriver(t *testing.T) {
+	fake := NewFakeSDCDriver(mockGUID,
+		ConfiguredCluster{SystemID: "1111111111111111", SdcID: "aaaaaaaa00000001"},
+		ConfiguredCluster{SystemID: "2222222222222222", SdcID: "bbbbbbbb00000002"},
+	)
+	SetSDCDriver(fake)
+	defer SetSDCDriver(nil)
+
+	systems, err := DrvCfgQuerySystems()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(*systems))
+	assert.Equal(t, "2222222222222222", (*systems)[1].SystemID)
+
+	fake.SetRC(SDCOpQuerySystems, 3)
+	_, err = DrvCfgQuerySystems()
+	assert.EqualError(t, err, "Request to query MDM failed, RC=3")
+
+	fake.SetRC(SDCOpQueryGUID, 0)
+	_, err = DrvCfgQueryGUID()
+	assert.EqualError(t, err, "Request to query GUID failed, RC=0")
+
+	fake.SetError(SDCOpQueryGUID, errors.New("QueryGUID error: bad file descriptor"))
+	_, err = DrvCfgQueryGUID()
+	assert.EqualError(t, err, "QueryGUID error: bad file descriptor")
+	assert.Equal(t, 2, fake.Calls(SDCOpQueryGUID))
+
+	rc, err := DrvCfgQueryRescan()
+	assert.Nil(t, err)
+	assert.Equal(t, "65", rc)
+
+	fake.SetRC(SDCOpRescan, 12)
+	rc, err = DrvCfgQueryRescan()
+	assert.Nil(t, err)
+	assert.Equal(t, "12", rc)
+
+	fake.SetInstalled(false)
+	assert.False(t, DrvCfgIsSDCInstalled())
+}
+
+func Test_IoctlSDCDriverNotInstalled(t *testing.T) {
+	d := &IoctlSDCDriver{Device: "/nonexistent/scini"}
+	assert.False(t, d.IsInstalled())
+
+	_, err := d.QueryGUID()
+	assert.NotNil(t, err)
+	_, err = d.QuerySystems()
+	assert.NotNil(t, err)
+	_, err = d.Rescan()
+	assert.EqualError(t, err, "Powerflex SDC is not installed")
+}
+
+func Test_NetAddressDecode(t *testing.T) {
+	if nativeEndian != binary.LittleEndian {
+		t.Skip("golden bytes are little-endian")
+	}
+
+	tests := map[string]struct {
+		golden string
+		want   string
+		ok     bool
+	}{
+		"ipv4": {
+			golden: "0200" + "19d3" + "0a00012a" + "000000000000000000000000" + "00000000",
+			want:   "10.0.1.42:6611",
+			ok:     true,
+		},
+		"ipv6": {
+			golden: "0a00" + "19d3" + "fd000000000000000000000000000001" + "00000000",
+			want:   "[fd00::1]:6611",
+			ok:     true,
+		},
+		"ipv6 port": {
+			golden: "0a00" + "1a0b" + "fe800000000000000202b3fffe1e8329" + "00000000",
+			want:   "[fe80::202:b3ff:fe1e:8329]:6667",
+			ok:     true,
+		},
+		"unknown family": {
+			golden: "0100" + "19d3" + "0a00012a" + "000000000000000000000000" + "00000000",
+		},
+		"unused slot": {
+			golden: "000000000000000000000000000000000000000000000000",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var a netAddress
+			b, err := hex.DecodeString(tc.golden)
+			assert.Nil(t, err)
+			assert.Equal(t, len(a.opaque), len(b))
+			copy(a.opaque[:], b)
+
+			addr, ok := a.decode()
+			assert.Equal(t, tc.ok, ok)
+			if tc.ok {
+				assert.Equal(t, tc.want, addr.String())
+			}
+		})
+	}
+}
+
+func Test_MdmInfoDecode(t *testing.T) {
+	if nativeEndian != binary.LittleEndian {
+		t.Skip("golden bytes are little-endian")
+	}
+
+	// filler, mdm ID, SDC ID and install ID (low word first), padding,
+	// the number of addresses and three address slots
+	golden := "00000000" +
+		"44332211" + "88776655" +
+		"0df0adde" + "efbeadde" +
+		"78563412" + "21436587" +
+		"00000000" +
+		"0300000000000000" +
+		"0200" + "19d3" + "c0a80a0b" + "000000000000000000000000" + "00000000" +
+		"0a00" + "19d3" + "fd000000000000000000000000000002" + "00000000" +
+		"0000" + "0000" + "00000000000000000000000000000000" + "00000000"
+
+	b, err := hex.DecodeString(golden)
+	assert.Nil(t, err)
+
+	var info ioctlMdmInfo
+	raw := (*[unsafe.Sizeof(info)]byte)(unsafe.Pointer(&info))
+	copy(raw[:], b)
+
+	c := info.cluster()
+	assert.Equal(t, "5566778811223344", c.SystemID)
+	assert.Equal(t, "deadbeefdeadf00d", c.SdcID)
+	assert.Equal(t, "8765432112345678", c.InstallID)
+	if assert.Equal(t, 2, len(c.Addresses)) {
+		assert.Equal(t, "192.168.10.11:6611", c.Addresses[0].String())
+		assert.Equal(t, "[fd00::2]:6611", c.Addresses[1].String())
+	}
+}
+
+// setDrvCfgFilePath points DrvCfgFilePath at a drv_cfg.txt in a temporary
+// directory for the test
+func setDrvCfgFilePath(t *testing.T) string {
+	t.Helper()
+	old := DrvCfgFilePath
+	DrvCfgFilePath = filepath.Join(t.TempDir(), "drv_cfg.txt")
+	t.Cleanup(func() { DrvCfgFilePath = old })
+	return DrvCfgFilePath
+}
+
+func Test_DrvCfgAddRemoveMdm(t *testing.T) {
+	fake := NewFakeSDCDriver(mockGUID)
+	fake.NextSystemID = "1111111111111111"
+	SetSDCDriver(fake)
+	defer SetSDCDriver(nil)
+	path := setDrvCfgFilePath(t)
+	assert.Nil(t, ioutil.WriteFile(path, []byte("ini_guid "+mockGUID+"\n"), 0600))
+
+	err := DrvCfgAddMdm(nil)
+	assert.Equal(t, errNoMdmAddress, err)
+
+	// the SDC only connects to DefaultMdmPort, and neither the fake nor the
+	// file accept anything else
+	assert.EqualError(t, DrvCfgAddMdm([]MdmAddress{{IP: net.ParseIP("10.0.0.2"), Port: 7611}}),
+		"cannot connect to MDM 10.0.0.2:7611, only port 6611 is supported")
+	assert.EqualError(t, fake.AddMdm([]MdmAddress{{IP: net.ParseIP("10.0.0.2"), Port: 7611}}),
+		"cannot connect to MDM 10.0.0.2:7611, only port 6611 is supported")
+	assert.Equal(t, 0, fake.Calls(SDCOpAddMdm))
+
+	addrs := []MdmAddress{{IP: net.ParseIP("10.0.0.1")}, {IP: net.ParseIP("fd00::1"), Port: DefaultMdmPort}}
+	assert.Nil(t, DrvCfgAddMdm(addrs))
+	assert.Equal(t, 0, addrs[0].Port)
+
+	systems, err := DrvCfgQuerySystems()
+	assert.Nil(t, err)
+	if assert.Equal(t, 1, len(*systems)) {
+		assert.Equal(t, "1111111111111111", (*systems)[0].SystemID)
+		assert.Equal(t, "10.0.0.1:6611", (*systems)[0].Addresses[0].String())
+		assert.Equal(t, "[fd00::1]:6611", (*systems)[0].Addresses[1].String())
+	}
+
+	// the cluster survives a restart of the SDC
+	data, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "ini_guid "+mockGUID+"\nmdm 10.0.0.1,fd00::1\n", string(data))
+
+	// the same MDM cannot be added twice
+	assert.NotNil(t, DrvCfgAddMdm(addrs[1:]))
+
+	assert.EqualError(t, DrvCfgRemoveMdm("xyz"), `invalid system ID "xyz"`)
+	assert.EqualError(t, DrvCfgRemoveMdm("2222222222222222"), "system 2222222222222222 is not connected")
+	fake.SetRC(SDCOpRemoveMdm, 7)
+	assert.EqualError(t, DrvCfgRemoveMdm("1111111111111111"), "Request to remove MDM failed, RC=7")
+	fake.SetRC(SDCOpRemoveMdm, rcSuccess)
+	assert.Nil(t, DrvCfgRemoveMdm("1111111111111111"))
+
+	systems, err = DrvCfgQuerySystems()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(*systems))
+	data, err = ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "ini_guid "+mockGUID+"\n", string(data))
+
+	// short system IDs are zero-padded
+	fake.NextSystemID = "0000000000000001"
+	assert.Nil(t, DrvCfgAddMdm(addrs[:1]))
+	assert.Nil(t, DrvCfgRemoveMdm("000000000001"))
+	assert.EqualError(t, DrvCfgRemoveMdm("11111111111111111"), `invalid system ID "11111111111111111"`)
+}
+
+func Test_DrvCfgMdmConsistency(t *testing.T) {
+	fake := NewFakeSDCDriver(mockGUID, ConfiguredCluster{SystemID: "3333333333333333"})
+	SetSDCDriver(fake)
+	defer SetSDCDriver(nil)
+	path := setDrvCfgFilePath(t)
+	original := "ini_guid " + mockGUID + "\nmdm 10.3.0.1\n"
+	assert.Nil(t, ioutil.WriteFile(path, []byte(original), 0600))
+
+	// a system whose addresses are unknown is left connected
+	assert.EqualError(t, DrvCfgRemoveMdm("3333333333333333"),
+		"the MDM addresses of system 3333333333333333 are unknown, "+path+" cannot be updated")
+	assert.Equal(t, 0, fake.Calls(SDCOpRemoveMdm))
+
+	// as is one whose drv_cfg.txt cannot be read
+	fake.Systems[0].Addresses = []MdmAddress{{IP: net.ParseIP("10.3.0.1"), Port: DefaultMdmPort}}
+	assert.Nil(t, ioutil.WriteFile(path, []byte("mdm 10.3.0\n"), 0600))
+	assert.NotNil(t, DrvCfgRemoveMdm("3333333333333333"))
+	assert.Equal(t, 0, fake.Calls(SDCOpRemoveMdm))
+	assert.NotNil(t, DrvCfgAddMdm([]MdmAddress{{IP: net.ParseIP("10.4.0.1")}}))
+	assert.Equal(t, 0, fake.Calls(SDCOpAddMdm))
+
+	// the SDC is disconnected again if drv_cfg.txt cannot be written
+	DrvCfgFilePath = filepath.Join(t.TempDir(), "missing", "drv_cfg.txt")
+	assert.NotNil(t, DrvCfgAddMdm([]MdmAddress{{IP: net.ParseIP("10.4.0.1")}}))
+	assert.Equal(t, 1, fake.Calls(SDCOpAddMdm))
+	systems, err := DrvCfgQuerySystems()
+	assert.Nil(t, err)
+	if assert.Equal(t, 1, len(*systems)) {
+		assert.Equal(t, "3333333333333333", (*systems)[0].SystemID)
+	}
+}
+
+func Test_IoctlSDCDriverAddRemoveMdm(t *testing.T) {
+	var commands []string
+	fail := false
+	oldCommand := drvCfgCommand
+	drvCfgCommand = func(args ...string) ([]byte, error) {
+		commands = append(commands, strings.Join(args, " "))
+		if fail {
+			return []byte("Failed to connect to MDM\n"), errors.New("exit status 1")
+		}
+		return nil, nil
+	}
+	defer func() { drvCfgCommand = oldCommand }()
+
+	d := &IoctlSDCDriver{}
+	assert.Nil(t, d.AddMdm([]MdmAddress{
+		{IP: net.ParseIP("10.0.0.1"), Port: DefaultMdmPort},
+		{IP: net.ParseIP("fd00::1"), Port: DefaultMdmPort},
+	}))
+	assert.Nil(t, d.RemoveMdm("000000000001"))
+	assert.Equal(t, []string{
+		"--add_mdm --ip 10.0.0.1,fd00::1",
+		"--remove_mdm --mdm_id 0000000000000001",
+	}, commands)
+
+	// addresses and IDs are checked before drv_cfg is run
+	commands = nil
+	assert.EqualError(t, d.AddMdm([]MdmAddress{{IP: net.ParseIP("10.0.0.1"), Port: 7611}}),
+		"cannot connect to MDM 10.0.0.1:7611, only port 6611 is supported")
+	assert.EqualError(t, d.AddMdm([]MdmAddress{{Port: DefaultMdmPort}}),
+		"invalid MDM address <nil>:6611")
+	assert.EqualError(t, d.RemoveMdm("xyz"), `invalid system ID "xyz"`)
+	assert.Empty(t, commands)
+
+	fail = true
+	assert.EqualError(t, d.RemoveMdm("1"), "removeMDM error: exit status 1: Failed to connect to MDM")
+}
+
+func Test_DrvCfgFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "drv_cfg.txt")
+
+	cfg, err := ReadDrvCfgFile(path)
+	assert.Nil(t, err)
+	assert.Empty(t, cfg.Mdms())
+
+	original := "# SDC configuration\n" +
+		"ini_guid 9E56672F-2F4B-4A42-BFF4-88B6846FBFDA\n" +
+		"mdm 10.0.0.1,10.0.0.2\n" +
+		"# secondary cluster\n" +
+		"mdm 10.1.0.1  --file /etc/emc/scaleio/drv_cfg.txt\n" +
+		"mdm fd00::3 --file /etc/emc/scaleio/drv_cfg.txt\n" +
+		"\n" +
+		"# end\n"
+	assert.Nil(t, ioutil.WriteFile(path, []byte(original), 0640))
+
+	cfg, err = ReadDrvCfgFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "9E56672F-2F4B-4A42-BFF4-88B6846FBFDA", cfg.GUID)
+	assert.Equal(t, []DrvCfgMdm{
+		{Addresses: []MdmAddress{mdmAddress("10.0.0.1", 6611), mdmAddress("10.0.0.2", 6611)}, Options: []string{}},
+		{Addresses: []MdmAddress{mdmAddress("10.1.0.1", 6611)}, Options: []string{"--file", "/etc/emc/scaleio/drv_cfg.txt"}},
+		{Addresses: []MdmAddress{mdmAddress("fd00::3", 6611)}, Options: []string{"--file", "/etc/emc/scaleio/drv_cfg.txt"}},
+	}, cfg.Mdms())
+
+	// an unchanged configuration is written back as it was read
+	assert.Nil(t, cfg.Write(path))
+	data, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, original, string(data))
+
+	added, err := cfg.AddMdm([]MdmAddress{mdmAddress("10.0.0.2", 0)})
+	assert.Nil(t, err)
+	assert.False(t, added)
+	added, err = cfg.AddMdm([]MdmAddress{mdmAddress("10.2.0.1", 0), mdmAddress("fd00::2", 6611)})
+	assert.Nil(t, err)
+	assert.True(t, added)
+	_, err = cfg.AddMdm([]MdmAddress{{}})
+	assert.NotNil(t, err)
+	// the SDC only connects to DefaultMdmPort
+	_, err = cfg.AddMdm([]MdmAddress{mdmAddress("10.4.0.1", 7611)})
+	assert.EqualError(t, err, "cannot connect to MDM 10.4.0.1:7611, only port 6611 is supported")
+
+	assert.True(t, cfg.RemoveMdm([]MdmAddress{mdmAddress("10.1.0.1", 6611)}))
+	assert.False(t, cfg.RemoveMdm([]MdmAddress{mdmAddress("10.9.9.9", 6611)}))
+
+	assert.Nil(t, cfg.Write(path))
+	data, err = ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "# SDC configuration\n"+
+		"ini_guid 9E56672F-2F4B-4A42-BFF4-88B6846FBFDA\n"+
+		"mdm 10.0.0.1,10.0.0.2\n"+
+		"# secondary cluster\n"+
+		"mdm fd00::3 --file /etc/emc/scaleio/drv_cfg.txt\n"+
+		"mdm 10.2.0.1,fd00::2\n"+
+		"\n"+
+		"# end\n", string(data))
+
+	// the file keeps its mode
+	info, err := os.Stat(path)
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0640), info.Mode().Perm())
+
+	// a GUID is added before the MDM clusters
+	path = filepath.Join(t.TempDir(), "drv_cfg.txt")
+	assert.Nil(t, ioutil.WriteFile(path, []byte("# SDC\nmdm 10.0.0.1\n"), 0600))
+	cfg, err = ReadDrvCfgFile(path)
+	assert.Nil(t, err)
+	cfg.GUID = "9E56672F-2F4B-4A42-BFF4-88B6846FBFDA"
+	assert.Nil(t, cfg.Write(path))
+	data, err = ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "# SDC\nini_guid 9E56672F-2F4B-4A42-BFF4-88B6846FBFDA\nmdm 10.0.0.1\n", string(data))
+
+	for _, bad := range []string{"mdm 10.0.0\n", "mdm 10.0.0.1:6611\n", "mdm [fd00::1]:6611\n"} {
+		assert.Nil(t, ioutil.WriteFile(path, []byte(bad), 0600))
+		_, err = ReadDrvCfgFile(path)
+		assert.NotNil(t, err, bad)
+	}
+}
+
+// mdmAddress returns the MDM address of ip and port
+func mdmAddress(ip string, port int) MdmAddress {
+	return MdmAddress{IP: net.ParseIP(ip), Port: port}
+}
@@ -0,0 +1,356 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package exporter exposes PowerFlex statistics as Prometheus metrics.
+//
+// A Collector fetches the statistics of every configured object type with a
+// single querySelectedStatistics request per scrape, and converts the
+// gateway's BWC counters to IOPS, bandwidth and average latency.
+package exporter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	goscaleio "github.com/AnshumanPradipPatil1506/goscaleio"
+	types "github.com/AnshumanPradipPatil1506/goscaleio/types/v1"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// DefaultNamespace prefixes every metric name
+	DefaultNamespace = "powerflex"
+	// DefaultScrapeTimeout is the default time budget of a scrape
+	DefaultScrapeTimeout = 10 * time.Second
+)
+
+// Options defines the options of a Collector
+type Options struct {
+	// Namespace prefixes every metric name, DefaultNamespace if empty
+	Namespace string
+	// ObjectTypes are the goscaleio.StatisticsType object types to export,
+	// every supported type if empty
+	ObjectTypes []string
+	// IDs restricts the objects of a type that are queried, every object of
+	// the type if it has no entry
+	IDs map[string][]string
+	// Filter, if set, is called for every object returned by the gateway
+	// and drops those it returns false for
+	Filter func(objectType, id string) bool
+	// ScrapeTimeout is the time budget of a scrape, DefaultScrapeTimeout if
+	// zero. A scrape that exceeds it reports no object metrics, and the
+	// statistics query it started is cancelled.
+	ScrapeTimeout time.Duration
+}
+
+// conversion is how a statistics property is turned into metrics
+type conversion int
+
+const (
+	// rate turns a BWC counter into IOPS and bandwidth
+	rate conversion = iota
+	// latency turns a latency BWC counter into an average latency
+	latency
+	// kilobytes turns a capacity in KiB into bytes
+	kilobytes
+)
+
+// property is one statistics property exported for an object type
+type property struct {
+	name       string
+	metric     string
+	help       string
+	conversion conversion
+}
+
+var (
+	poolProperties = []property{
+		{"primaryReadBwc", "read", "reads", rate},
+		{"primaryWriteBwc", "write", "writes", rate},
+		{"maxCapacityInKb", "capacity_max_bytes", "Maximum capacity in bytes.", kilobytes},
+		{"capacityInUseInKb", "capacity_in_use_bytes", "Capacity in use in bytes.", kilobytes},
+	}
+	backendProperties = []property{
+		{"totalReadBwc", "read", "reads", rate},
+		{"totalWriteBwc", "write", "writes", rate},
+		{"maxCapacityInKb", "capacity_max_bytes", "Maximum capacity in bytes.", kilobytes},
+		{"capacityInUseInKb", "capacity_in_use_bytes", "Capacity in use in bytes.", kilobytes},
+	}
+	frontendProperties = []property{
+		{"userDataReadBwc", "read", "reads", rate},
+		{"userDataWriteBwc", "write", "writes", rate},
+		{"userDataSdcReadLatency", "read_latency_seconds", "Average read latency in seconds.", latency},
+		{"userDataSdcWriteLatency", "write_latency_seconds", "Average write latency in seconds.", latency},
+	}
+
+	// objectProperties are the supported object types, their metric
+	// subsystem and their properties
+	objectProperties = []struct {
+		objectType string
+		subsystem  string
+		properties []property
+	}{
+		{goscaleio.StatisticsTypeSystem, "system", poolProperties},
+		{goscaleio.StatisticsTypeProtectionDomain, "protection_domain", poolProperties},
+		{goscaleio.StatisticsTypeStoragePool, "storage_pool", poolProperties},
+		{goscaleio.StatisticsTypeSds, "sds", backendProperties},
+		{goscaleio.StatisticsTypeDevice, "device", backendProperties},
+		{goscaleio.StatisticsTypeSdc, "sdc", frontendProperties},
+		{goscaleio.StatisticsTypeVolume, "volume", frontendProperties},
+	}
+)
+
+// metric is one exported metric of a property
+type metric struct {
+	desc  *prometheus.Desc
+	value func(raw json.RawMessage) (float64, error)
+}
+
+// objectMetrics are the metrics of one object type
+type objectMetrics struct {
+	objectType string
+	query      goscaleio.StatisticsQuery
+	metrics    map[string][]metric
+}
+
+// Collector implements prometheus.Collector for the statistics of a system
+type Collector struct {
+	system  *goscaleio.System
+	filter  func(objectType, id string) bool
+	timeout time.Duration
+	objects []*objectMetrics
+
+	// mu guards inflight, the running statistics query if any
+	mu       sync.Mutex
+	inflight *pendingQuery
+
+	scrapeDuration *prometheus.Desc
+	scrapeSuccess  *prometheus.Desc
+}
+
+// New returns a Collector for the given system
+func New(system *goscaleio.System, opts *Options) (*Collector, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	namespace := opts.Namespace
+	if namespace == "" {
+		namespace = DefaultNamespace
+	}
+
+	c := &Collector{
+		system:  system,
+		filter:  opts.Filter,
+		timeout: opts.ScrapeTimeout,
+		scrapeDuration: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "scrape", "duration_seconds"),
+			"Time the statistics scrape took in seconds.", nil, nil),
+		scrapeSuccess: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "scrape", "success"),
+			"Whether the statistics scrape succeeded within its time budget.", nil, nil),
+	}
+	if c.timeout == 0 {
+		c.timeout = DefaultScrapeTimeout
+	}
+
+	wanted := make(map[string]bool, len(opts.ObjectTypes))
+	for _, t := range opts.ObjectTypes {
+		wanted[t] = true
+	}
+
+	for _, o := range objectProperties {
+		if len(wanted) > 0 && !wanted[o.objectType] {
+			continue
+		}
+		delete(wanted, o.objectType)
+
+		om := &objectMetrics{
+			objectType: o.objectType,
+			query: goscaleio.StatisticsQuery{
+				Type: o.objectType,
+				IDs:  opts.IDs[o.objectType],
+			},
+			metrics: make(map[string][]metric),
+		}
+		for _, p := range o.properties {
+			om.query.Properties = append(om.query.Properties, p.name)
+			om.metrics[p.name] = newMetrics(namespace, o.subsystem, p)
+		}
+		c.objects = append(c.objects, om)
+	}
+
+	for t := range wanted {
+		return nil, fmt.Errorf("unsupported object type %q", t)
+	}
+
+	return c, nil
+}
+
+func newMetrics(namespace, subsystem string, p property) []metric {
+	labels := []string{"id"}
+	switch p.conversion {
+	case rate:
+		return []metric{
+			{
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, p.metric+"_iops"),
+					fmt.Sprintf("Average %s per second.", p.help), labels, nil),
+				value: bwcValue(types.BWC.IOPS),
+			},
+			{
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, p.metric+"_bandwidth_bytes_per_second"),
+					fmt.Sprintf("Average bandwidth of %s in bytes per second.", p.help), labels, nil),
+				value: bwcValue(types.BWC.BandwidthBytesPerSecond),
+			},
+		}
+	case latency:
+		return []metric{{
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, p.metric), p.help, labels, nil),
+			value: bwcValue(func(b types.BWC) float64 {
+				return b.AverageLatency().Seconds()
+			}),
+		}}
+	default:
+		return []metric{{
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, p.metric), p.help, labels, nil),
+			value: func(raw json.RawMessage) (float64, error) {
+				var kb float64
+				err := json.Unmarshal(raw, &kb)
+				return kb * 1024, err
+			},
+		}}
+	}
+}
+
+func bwcValue(f func(types.BWC) float64) func(json.RawMessage) (float64, error) {
+	return func(raw json.RawMessage) (float64, error) {
+		var b types.BWC
+		if err := json.Unmarshal(raw, &b); err != nil {
+			return 0, err
+		}
+		return f(b), nil
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.scrapeDuration
+	ch <- c.scrapeSuccess
+	for _, om := range c.objects {
+		for _, metrics := range om.metrics {
+			for _, m := range metrics {
+				ch <- m.desc
+			}
+		}
+	}
+}
+
+// Collect implements prometheus.Collector
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	start := time.Now()
+	stats, err := c.query()
+	ch <- prometheus.MustNewConstMetric(c.scrapeDuration, prometheus.GaugeValue,
+		time.Since(start).Seconds())
+
+	success := 0.0
+	if err == nil {
+		success = 1
+		c.collectObjects(ch, stats)
+	}
+	ch <- prometheus.MustNewConstMetric(c.scrapeSuccess, prometheus.GaugeValue, success)
+}
+
+// pendingQuery is a statistics query shared by the scrapes that wait for it
+type pendingQuery struct {
+	done  chan struct{}
+	stats *goscaleio.SelectedStatistics
+	err   error
+}
+
+// query fetches the statistics within the scrape time budget. At most one
+// query runs at a time: a scrape that starts while the query of another
+// scrape is running waits for that query instead of sending another one.
+// A query is cancelled once the budget of the scrape that started it runs
+// out, so a hung gateway does not keep it running.
+func (c *Collector) query() (*goscaleio.SelectedStatistics, error) {
+	p := c.startQuery()
+
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+	select {
+	case <-p.done:
+		return p.stats, p.err
+	case <-timer.C:
+		return nil, fmt.Errorf("statistics query exceeded %v", c.timeout)
+	}
+}
+
+// startQuery returns the running statistics query, starting one that is
+// cancelled after the scrape time budget if there is none
+func (c *Collector) startQuery() *pendingQuery {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.inflight != nil {
+		return c.inflight
+	}
+
+	queries := make([]goscaleio.StatisticsQuery, 0, len(c.objects))
+	for _, om := range c.objects {
+		queries = append(queries, om.query)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	p := &pendingQuery{done: make(chan struct{})}
+	c.inflight = p
+	go func() {
+		defer cancel()
+		p.stats, p.err = c.system.WithContext(ctx).QuerySelectedStatistics(queries...)
+		c.mu.Lock()
+		c.inflight = nil
+		c.mu.Unlock()
+		close(p.done)
+	}()
+	return p
+}
+
+func (c *Collector) collectObjects(ch chan<- prometheus.Metric, stats *goscaleio.SelectedStatistics) {
+	for _, om := range c.objects {
+		for _, id := range stats.IDs(om.objectType) {
+			if c.filter != nil && !c.filter(om.objectType, id) {
+				continue
+			}
+			props, err := stats.Properties(om.objectType, id)
+			if err != nil {
+				continue
+			}
+			for name, metrics := range om.metrics {
+				raw, ok := props[name]
+				if !ok {
+					continue
+				}
+				for _, m := range metrics {
+					v, err := m.value(raw)
+					if err != nil {
+						continue
+					}
+					ch <- prometheus.MustNewConstMetric(m.desc, prometheus.GaugeValue, v, id)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	goscaleio "github.com/AnshumanPradipPatil1506/goscaleio"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+const testStatistics = `{
+	"System": {
+		"primaryReadBwc": {"totalWeightInKb": 2048, "numOccured": 100, "numSeconds": 2},
+		"maxCapacityInKb": 1024
+	},
+	"Volume": {
+		"v1": {
+			"userDataReadBwc": {"totalWeightInKb": 40, "numOccured": 10, "numSeconds": 5},
+			"userDataSdcReadLatency": {"totalWeightInKb": 5000, "numOccured": 10, "numSeconds": 5}
+		},
+		"v2": {
+			"userDataReadBwc": {"totalWeightInKb": 8, "numOccured": 2, "numSeconds": 1}
+		}
+	}
+}`
+
+func newTestSystem(t *testing.T, handler http.HandlerFunc) (*goscaleio.System, func()) {
+	ts := httptest.NewServer(handler)
+	client, err := goscaleio.NewClientWithArgs(ts.URL, "3.5", true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	system := goscaleio.NewSystem(client)
+	system.System.ID = "sys1"
+	return system, ts.Close
+}
+
+// runningQuery returns the statistics query in flight, if any
+func (c *Collector) runningQuery() *pendingQuery {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.inflight
+}
+
+// gather registers the collector and returns the gathered values by metric
+// name and id label
+func gather(t *testing.T, c prometheus.Collector) map[string]map[string]float64 {
+	reg := prometheus.NewPedanticRegistry()
+	if err := reg.Register(c); err != nil {
+		t.Fatal(err)
+	}
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := make(map[string]map[string]float64)
+	for _, f := range families {
+		out[f.GetName()] = make(map[string]float64)
+		for _, m := range f.GetMetric() {
+			id := ""
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "id" {
+					id = l.GetValue()
+				}
+			}
+			out[f.GetName()][id] = m.GetGauge().GetValue()
+		}
+	}
+	return out
+}
+
+func TestCollector(t *testing.T) {
+	system, cleanup := newTestSystem(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/instances/querySelectedStatistics" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		fmt.Fprint(w, testStatistics)
+	})
+	defer cleanup()
+
+	_, err := New(system, &Options{ObjectTypes: []string{"Snapshot"}})
+	assert.NotNil(t, err)
+
+	c, err := New(system, &Options{
+		ObjectTypes: []string{goscaleio.StatisticsTypeSystem, goscaleio.StatisticsTypeVolume},
+		Filter: func(objectType, id string) bool {
+			return id != "v2"
+		},
+	})
+	assert.Nil(t, err)
+
+	metrics := gather(t, c)
+	assert.Equal(t, 1.0, metrics["powerflex_scrape_success"][""])
+	assert.Equal(t, 50.0, metrics["powerflex_system_read_iops"]["sys1"])
+	assert.Equal(t, 1024.0*1024, metrics["powerflex_system_read_bandwidth_bytes_per_second"]["sys1"])
+	assert.Equal(t, 1024.0*1024, metrics["powerflex_system_capacity_max_bytes"]["sys1"])
+	assert.Equal(t, 2.0, metrics["powerflex_volume_read_iops"]["v1"])
+	assert.Equal(t, 0.0005, metrics["powerflex_volume_read_latency_seconds"]["v1"])
+	_, ok := metrics["powerflex_volume_read_iops"]["v2"]
+	assert.False(t, ok)
+	_, ok = metrics["powerflex_sdc_read_iops"]
+	assert.False(t, ok)
+}
+
+func TestCollectorScrapeTimeout(t *testing.T) {
+	release := make(chan struct{})
+	system, cleanup := newTestSystem(t, func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		fmt.Fprint(w, testStatistics)
+	})
+	defer cleanup()
+	defer close(release)
+
+	c, err := New(system, &Options{
+		Namespace:     "pf",
+		ScrapeTimeout: 50 * time.Millisecond,
+	})
+	assert.Nil(t, err)
+
+	metrics := gather(t, c)
+	assert.Equal(t, 0.0, metrics["pf_scrape_success"][""])
+	_, ok := metrics["pf_system_read_iops"]
+	assert.False(t, ok)
+}
+
+func TestCollectorSingleQueryInFlight(t *testing.T) {
+	var requests int32
+	release := make(chan struct{})
+	system, cleanup := newTestSystem(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		<-release
+		fmt.Fprint(w, testStatistics)
+	})
+	defer cleanup()
+
+	c, err := New(system, &Options{
+		Namespace:     "pf",
+		ScrapeTimeout: time.Second,
+	})
+	assert.Nil(t, err)
+
+	// concurrent scrapes share one query
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, 1.0, gather(t, c)["pf_scrape_success"][""])
+		}()
+	}
+	for c.runningQuery() == nil {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
+func TestCollectorCancelsTimedOutQuery(t *testing.T) {
+	cancelled := make(chan struct{})
+	system, cleanup := newTestSystem(t, func(w http.ResponseWriter, r *http.Request) {
+		// a hung gateway, which notices the cancellation once it has read
+		// the request
+		ioutil.ReadAll(r.Body)
+		select {
+		case <-r.Context().Done():
+			close(cancelled)
+		case <-time.After(5 * time.Second):
+		}
+	})
+	defer cleanup()
+
+	c, err := New(system, &Options{
+		Namespace:     "pf",
+		ScrapeTimeout: 50 * time.Millisecond,
+	})
+	assert.Nil(t, err)
+
+	assert.Equal(t, 0.0, gather(t, c)["pf_scrape_success"][""])
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the timed out query was not cancelled")
+	}
+	for c.runningQuery() != nil {
+		time.Sleep(time.Millisecond)
+	}
+}
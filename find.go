@@ -0,0 +1,275 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goscaleio
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/google/uuid"
+
+	types "github.com/AnshumanPradipPatil1506/goscaleio/types/v1"
+)
+
+var errNoFindOption = errors.New("at least one lookup option is required")
+
+// FindOption is a typed lookup criterion used by FindSdc, FindSds,
+// FindDevice, FindScsiInitiator, FindSdt and FindPeerMdm and their FindAll
+// variants. Options are built with the By*
+// constructors, which validate their input up front. Each option knows which
+// object types it applies to; using it with any other type is an error.
+type FindOption struct {
+	name   string
+	err    error
+	sdc    func(*types.Sdc) bool
+	sds    func(*types.Sds) bool
+	device func(*types.Device) bool
+
+	scsiInitiator func(*types.ScsiInitiator) bool
+	sdt           func(*types.Sdt) bool
+	peerMdm       func(*types.PeerMdm) bool
+}
+
+func (o FindOption) String() string {
+	return o.name
+}
+
+func invalidFindOption(name string, err error) FindOption {
+	return FindOption{name: name, err: fmt.Errorf("invalid %s lookup: %v", name, err)}
+}
+
+func requireValue(name, value string) error {
+	if strings.TrimSpace(value) == "" {
+		return fmt.Errorf("invalid %s lookup: value must not be empty", name)
+	}
+	return nil
+}
+
+// ByID matches an Sdc, Sds, Device, ScsiInitiator, Sdt or PeerMdm by its ID
+func ByID(id string) FindOption {
+	if err := requireValue("ID", id); err != nil {
+		return FindOption{name: "ID", err: err}
+	}
+	return FindOption{
+		name:          "ID",
+		sdc:           func(s *types.Sdc) bool { return s.ID == id },
+		sds:           func(s *types.Sds) bool { return s.ID == id },
+		device:        func(d *types.Device) bool { return d.ID == id },
+		scsiInitiator: func(si *types.ScsiInitiator) bool { return si.ID == id },
+		sdt:           func(t *types.Sdt) bool { return t.ID == id },
+		peerMdm:       func(p *types.PeerMdm) bool { return p.ID == id },
+	}
+}
+
+// ByName matches an Sdc, Sds, Device, ScsiInitiator, Sdt or PeerMdm by its
+// name
+func ByName(name string) FindOption {
+	if err := requireValue("Name", name); err != nil {
+		return FindOption{name: "Name", err: err}
+	}
+	return FindOption{
+		name:          "Name",
+		sdc:           func(s *types.Sdc) bool { return s.Name == name },
+		sds:           func(s *types.Sds) bool { return s.Name == name },
+		device:        func(d *types.Device) bool { return d.Name == name },
+		scsiInitiator: func(si *types.ScsiInitiator) bool { return si.Name == name },
+		sdt:           func(t *types.Sdt) bool { return t.Name == name },
+		peerMdm:       func(p *types.PeerMdm) bool { return p.Name == name },
+	}
+}
+
+// ByGUID matches an Sdc by its GUID. The comparison ignores case, so a GUID
+// returned by DrvCfgQueryGUID can be used directly.
+func ByGUID(guid string) FindOption {
+	if err := requireValue("GUID", guid); err != nil {
+		return FindOption{name: "GUID", err: err}
+	}
+	if _, err := uuid.Parse(guid); err != nil {
+		return invalidFindOption("GUID", err)
+	}
+	return FindOption{
+		name: "GUID",
+		sdc:  func(s *types.Sdc) bool { return strings.EqualFold(s.SdcGUID, guid) },
+	}
+}
+
+// ByIP matches an Sdc by its IP, or an Sds, Sdt or PeerMdm by any of its
+// configured IPs
+func ByIP(ip string) FindOption {
+	if err := requireValue("IP", ip); err != nil {
+		return FindOption{name: "IP", err: err}
+	}
+	want := net.ParseIP(ip)
+	if want == nil {
+		return invalidFindOption("IP", fmt.Errorf("%q is not an IP address", ip))
+	}
+	return FindOption{
+		name: "IP",
+		sdc:  func(s *types.Sdc) bool { return want.Equal(net.ParseIP(s.SdcIP)) },
+		sds: func(s *types.Sds) bool {
+			for _, l := range s.IPList {
+				if l != nil && want.Equal(net.ParseIP(l.SdsIP.IP)) {
+					return true
+				}
+			}
+			return false
+		},
+		sdt: func(t *types.Sdt) bool {
+			for _, l := range t.IPList {
+				if l != nil && want.Equal(net.ParseIP(l.IP)) {
+					return true
+				}
+			}
+			return false
+		},
+		peerMdm: func(p *types.PeerMdm) bool {
+			for _, l := range p.IPList {
+				if l != nil && want.Equal(net.ParseIP(l.IP)) {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
+
+// ByState matches an Sdc by its MDM connection state, an Sds by its SDS
+// state, a Device by its device state or an Sdt by its SDT state
+func ByState(state string) FindOption {
+	if err := requireValue("State", state); err != nil {
+		return FindOption{name: "State", err: err}
+	}
+	return FindOption{
+		name:   "State",
+		sdc:    func(s *types.Sdc) bool { return s.MdmConnectionState == state },
+		sds:    func(s *types.Sds) bool { return s.SdsState == state },
+		device: func(d *types.Device) bool { return d.DeviceState == state },
+		sdt:    func(t *types.Sdt) bool { return t.SdtState == state },
+	}
+}
+
+// ByStoragePool matches a Device by the ID of the storage pool it belongs to
+func ByStoragePool(id string) FindOption {
+	if err := requireValue("StoragePool", id); err != nil {
+		return FindOption{name: "StoragePool", err: err}
+	}
+	return FindOption{
+		name:   "StoragePool",
+		device: func(d *types.Device) bool { return d.StoragePoolID == id },
+	}
+}
+
+// BySds matches a Device by the ID of the SDS it is attached to
+func BySds(id string) FindOption {
+	if err := requireValue("Sds", id); err != nil {
+		return FindOption{name: "Sds", err: err}
+	}
+	return FindOption{
+		name:   "Sds",
+		device: func(d *types.Device) bool { return d.SdsID == id },
+	}
+}
+
+// ByIQN matches a ScsiInitiator by its IQN. IQNs are case-insensitive.
+func ByIQN(iqn string) FindOption {
+	if err := requireValue("IQN", iqn); err != nil {
+		return FindOption{name: "IQN", err: err}
+	}
+	return FindOption{
+		name:          "IQN",
+		scsiInitiator: func(si *types.ScsiInitiator) bool { return strings.EqualFold(si.IQN, iqn) },
+	}
+}
+
+// ByPeerSystemID matches a PeerMdm by the system ID of the peer system
+func ByPeerSystemID(id string) FindOption {
+	if err := requireValue("PeerSystemID", id); err != nil {
+		return FindOption{name: "PeerSystemID", err: err}
+	}
+	return FindOption{
+		name:    "PeerSystemID",
+		peerMdm: func(p *types.PeerMdm) bool { return p.PeerSystemID == id },
+	}
+}
+
+// validateFindOptions checks that at least one option was given, that all
+// were built without error, and that each applies to the kind of object being
+// searched.
+func validateFindOptions(
+	kind string, opts []FindOption, supported func(FindOption) bool) error {
+
+	if len(opts) == 0 {
+		return errNoFindOption
+	}
+	for _, o := range opts {
+		if o.err != nil {
+			return o.err
+		}
+		if !supported(o) {
+			return fmt.Errorf("%s lookup is not supported for %s", o.name, kind)
+		}
+	}
+	return nil
+}
+
+// finder looks up objects of type T with FindOptions
+type finder[T any] struct {
+	// kind names the object type in errors
+	kind string
+	// predicate returns the option's predicate for T, nil if the option
+	// does not apply to T
+	predicate func(FindOption) func(*T) bool
+}
+
+var (
+	sdcFinder = finder[types.Sdc]{"SDC",
+		func(o FindOption) func(*types.Sdc) bool { return o.sdc }}
+	sdsFinder = finder[types.Sds]{"SDS",
+		func(o FindOption) func(*types.Sds) bool { return o.sds }}
+	deviceFinder = finder[types.Device]{"Device",
+		func(o FindOption) func(*types.Device) bool { return o.device }}
+	scsiInitiatorFinder = finder[types.ScsiInitiator]{"ScsiInitiator",
+		func(o FindOption) func(*types.ScsiInitiator) bool { return o.scsiInitiator }}
+	sdtFinder = finder[types.Sdt]{"SDT",
+		func(o FindOption) func(*types.Sdt) bool { return o.sdt }}
+	peerMdmFinder = finder[types.PeerMdm]{"PeerMdm",
+		func(o FindOption) func(*types.PeerMdm) bool { return o.peerMdm }}
+)
+
+// validate checks the options for the finder's object type. It is called
+// before the objects are listed so that invalid lookups cost no request.
+func (f finder[T]) validate(opts []FindOption) error {
+	return validateFindOptions(f.kind, opts,
+		func(o FindOption) bool { return f.predicate(o) != nil })
+}
+
+// match returns the indexes of the items that satisfy every option. The
+// options must have been validated.
+func (f finder[T]) match(items []T, opts []FindOption) []int {
+	var matches []int
+	for i := range items {
+		ok := true
+		for _, o := range opts {
+			if !f.predicate(o)(&items[i]) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			matches = append(matches, i)
+		}
+	}
+	return matches
+}
@@ -0,0 +1,107 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goscaleio
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	types "github.com/AnshumanPradipPatil1506/goscaleio/types/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FindOptionValidation(t *testing.T) {
+	tests := map[string]FindOption{
+		"empty ID":       ByID(""),
+		"blank name":     ByName("  "),
+		"malformed GUID": ByGUID("not-a-guid"),
+		"malformed IP":   ByIP("10.0.0"),
+	}
+
+	for name, opt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := sdcFinder.validate([]FindOption{opt})
+			assert.NotNil(t, err)
+		})
+	}
+
+	t.Run("no options", func(t *testing.T) {
+		err := sdsFinder.validate(nil)
+		assert.Equal(t, errNoFindOption, err)
+	})
+
+	t.Run("option not supported for type", func(t *testing.T) {
+		err := sdsFinder.validate([]FindOption{ByGUID("9E56672F-2F4B-4A42-BFF4-88B6846FBFDA")})
+		assert.EqualError(t, err, "GUID lookup is not supported for SDS")
+
+		err = deviceFinder.validate([]FindOption{ByIP("10.0.0.1")})
+		assert.EqualError(t, err, "IP lookup is not supported for Device")
+	})
+}
+
+func Test_FindSdc(t *testing.T) {
+	systemID := "0000aaabbbccc1111"
+	sdcs := []types.Sdc{
+		{ID: "sdc1", Name: "node1", SdcIP: "10.0.0.1", SdcGUID: "9E56672F-2F4B-4A42-BFF4-88B6846FBFDA", MdmConnectionState: "Connected"},
+		{ID: "sdc2", Name: "node2", SdcIP: "10.0.0.2", SdcGUID: "1B3F4F1E-7A5D-4C3B-9E2D-2F6A1C0D8E11", MdmConnectionState: "Connected"},
+		{ID: "sdc3", Name: "node3", SdcIP: "10.0.0.3", SdcGUID: "4D2C2B9A-3E1F-4A77-8C55-0B9E6F7A1234", MdmConnectionState: "Disconnected"},
+	}
+
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		href := fmt.Sprintf("/api/instances/System::%s/relationships/Sdc", systemID)
+		if r.URL.Path != href {
+			t.Fatal(fmt.Errorf("wrong path. Expected %s; but got %s", href, r.URL.Path))
+		}
+		respData, err := json.Marshal(sdcs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprintln(w, string(respData))
+	}))
+	defer ts.Close()
+
+	client, err := NewClientWithArgs(ts.URL, "", true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	system := NewSystem(client)
+	system.System.ID = systemID
+
+	found, err := system.FindSdc(ByGUID("1b3f4f1e-7a5d-4c3b-9e2d-2f6a1c0d8e11"))
+	assert.Nil(t, err)
+	assert.Equal(t, "sdc2", found.Sdc.ID)
+
+	found, err = system.FindSdc(ByIP("10.0.0.3"))
+	assert.Nil(t, err)
+	assert.Equal(t, "sdc3", found.Sdc.ID)
+
+	all, err := system.FindAllSdc(ByState("Connected"))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(all))
+
+	_, err = system.FindSdc(ByState("Connected"), ByName("node3"))
+	assert.NotNil(t, err)
+
+	// invalid options are rejected before the SDCs are listed
+	requests = 0
+	_, err = system.FindSdc(ByStoragePool("pool1"))
+	assert.EqualError(t, err, "StoragePool lookup is not supported for SDC")
+	_, err = system.FindAllSdc()
+	assert.Equal(t, errNoFindOption, err)
+	assert.Equal(t, 0, requests)
+}
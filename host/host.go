@@ -0,0 +1,373 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package host prepares mapped PowerFlex volumes for use on the local node:
+// it formats, mounts and grows the filesystem on the block device reported
+// in goscaleio.SdcMappedVolume.SdcDevice.
+package host
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// FSTypeExt4 is the ext4 filesystem
+	FSTypeExt4 = "ext4"
+	// FSTypeXFS is the xfs filesystem
+	FSTypeXFS = "xfs"
+
+	// DefaultMountInfoPath is where the mount table of the current process is read from
+	DefaultMountInfoPath = "/proc/self/mountinfo"
+)
+
+var errNoDevice = errors.New("device is required")
+
+// Executor runs an external command and returns its combined output
+type Executor interface {
+	Run(name string, args ...string) ([]byte, error)
+}
+
+type osExecutor struct{}
+
+func (osExecutor) Run(name string, args ...string) ([]byte, error) {
+	// #nosec G204, commands and arguments are built by this package
+	return exec.Command(name, args...).CombinedOutput()
+}
+
+// Host formats, mounts and expands filesystems on local block devices
+type Host struct {
+	exec Executor
+	// MountInfoPath is the mountinfo file used to report mount state
+	MountInfoPath string
+}
+
+// New returns a Host that runs commands on the local system
+func New() *Host {
+	return NewWithExecutor(osExecutor{})
+}
+
+// NewWithExecutor returns a Host that runs commands through e
+func NewWithExecutor(e Executor) *Host {
+	return &Host{
+		exec:          e,
+		MountInfoPath: DefaultMountInfoPath,
+	}
+}
+
+// MountInfo describes one entry of the mount table
+type MountInfo struct {
+	Device     string
+	MountPoint string
+	FSType     string
+	Root       string
+	Options    []string
+	// MajorMinor is the major:minor number of the mounted device
+	MajorMinor string
+}
+
+func supportedFSType(fsType string) bool {
+	return fsType == FSTypeExt4 || fsType == FSTypeXFS
+}
+
+func exitCode(err error) int {
+	if e, ok := err.(interface{ ExitCode() int }); ok {
+		return e.ExitCode()
+	}
+	return -1
+}
+
+// FSType returns the filesystem type found on device, or an empty string if
+// the device is blank. A device carrying a partition table is reported as an
+// error since it is not safe to format.
+func (h *Host) FSType(device string) (string, error) {
+	if device == "" {
+		return "", errNoDevice
+	}
+
+	out, err := h.exec.Run("blkid", "-p", "-o", "export", device)
+	if err != nil {
+		// blkid exits with 2 when no signature was found
+		if exitCode(err) == 2 {
+			return "", nil
+		}
+		return "", fmt.Errorf("blkid %s: %v: %s", device, err, out)
+	}
+
+	var fsType, ptType string
+	for _, line := range strings.Split(string(out), "\n") {
+		kv := strings.SplitN(strings.TrimSpace(line), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "TYPE":
+			fsType = kv[1]
+		case "PTTYPE":
+			ptType = kv[1]
+		}
+	}
+	if fsType == "" && ptType != "" {
+		return "", fmt.Errorf("device %s has a %s partition table", device, ptType)
+	}
+	return fsType, nil
+}
+
+// Format creates a filesystem of the given type on device, but only if the
+// device is blank. A device that already holds a filesystem of the requested
+// type is left untouched; one that holds a different filesystem is an error.
+// Extra arguments are passed to mkfs.
+func (h *Host) Format(device, fsType string, mkfsArgs ...string) error {
+	if !supportedFSType(fsType) {
+		return fmt.Errorf("unsupported filesystem type %q", fsType)
+	}
+
+	existing, err := h.FSType(device)
+	if err != nil {
+		return err
+	}
+	if existing == fsType {
+		return nil
+	}
+	if existing != "" {
+		return fmt.Errorf("device %s already formatted as %s", device, existing)
+	}
+
+	args := append([]string{}, mkfsArgs...)
+	if fsType == FSTypeExt4 {
+		args = append(args, "-F")
+	}
+	args = append(args, device)
+
+	if out, err := h.exec.Run("mkfs."+fsType, args...); err != nil {
+		return fmt.Errorf("mkfs.%s %s: %v: %s", fsType, device, err, out)
+	}
+	return nil
+}
+
+// GetMounts returns the mount table entries. If device is not empty only the
+// entries whose source is device are returned. Symbolic links such as
+// /dev/disk/by-id entries are resolved before sources are compared.
+func (h *Host) GetMounts(device string) ([]MountInfo, error) {
+	f, err := os.Open(h.MountInfoPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	mounts, err := ParseMountInfo(f)
+	if err != nil || device == "" {
+		return mounts, err
+	}
+
+	var matched []MountInfo
+	for _, m := range mounts {
+		if sameDevice(m.Device, device) {
+			matched = append(matched, m)
+		}
+	}
+	return matched, nil
+}
+
+// ParseMountInfo parses a mount table in the /proc/<pid>/mountinfo format
+func ParseMountInfo(r io.Reader) ([]MountInfo, error) {
+	var mounts []MountInfo
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		// 36 35 98:0 /root /mnt rw,noatime master:1 - ext4 /dev/sda rw
+		parts := strings.SplitN(scanner.Text(), " - ", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		head := strings.Fields(parts[0])
+		tail := strings.Fields(parts[1])
+		if len(head) < 6 || len(tail) < 2 {
+			continue
+		}
+		mounts = append(mounts, MountInfo{
+			Root:       unescapeMountPath(head[3]),
+			MountPoint: unescapeMountPath(head[4]),
+			Options:    strings.Split(head[5], ","),
+			FSType:     tail[0],
+			Device:     unescapeMountPath(tail[1]),
+			MajorMinor: head[2],
+		})
+	}
+	return mounts, scanner.Err()
+}
+
+// unescapeMountPath decodes the octal escapes the kernel uses for spaces,
+// tabs, newlines and backslashes in mountinfo paths
+func unescapeMountPath(s string) string {
+	r := strings.NewReplacer(`\040`, " ", `\011`, "\t", `\012`, "\n", `\134`, `\`)
+	return r.Replace(s)
+}
+
+// resolveDevice returns the path a device path links to, or the path itself
+// if it cannot be resolved, as for sources such as tmpfs that are not paths
+func resolveDevice(device string) string {
+	if resolved, err := filepath.EvalSymlinks(device); err == nil {
+		return resolved
+	}
+	return device
+}
+
+// sameDevice reports whether two device paths name the same device
+func sameDevice(a, b string) bool {
+	return a == b || resolveDevice(a) == resolveDevice(b)
+}
+
+// GetMountAt returns the mount at target, or nil if nothing is mounted there
+func (h *Host) GetMountAt(target string) (*MountInfo, error) {
+	mounts, err := h.GetMounts("")
+	if err != nil {
+		return nil, err
+	}
+	var found *MountInfo
+	// later entries shadow earlier ones mounted at the same place
+	for i := range mounts {
+		if mounts[i].MountPoint == target {
+			found = &mounts[i]
+		}
+	}
+	return found, nil
+}
+
+// IsMounted reports whether device is mounted anywhere
+func (h *Host) IsMounted(device string) (bool, error) {
+	mounts, err := h.GetMounts(device)
+	if err != nil {
+		return false, err
+	}
+	return len(mounts) > 0, nil
+}
+
+// readOnly reports whether mount options select a read-only mount. The last
+// of ro and rw wins, as it does for mount(8); neither means read-write.
+func readOnly(options []string) bool {
+	ro := false
+	for _, o := range options {
+		switch o {
+		case "ro":
+			ro = true
+		case "rw":
+			ro = false
+		}
+	}
+	return ro
+}
+
+// accessMode names the access mode selected by mount options
+func accessMode(options []string) string {
+	if readOnly(options) {
+		return "read-only"
+	}
+	return "read-write"
+}
+
+// Mount mounts device on target with the given filesystem type and options,
+// creating target if needed. Mounting a device where it is already mounted
+// with the same access mode is a no-op; a different device mounted on
+// target, or the device mounted read-only where read-write was requested or
+// the other way round, is an error.
+func (h *Host) Mount(device, target, fsType string, options []string) error {
+	if device == "" {
+		return errNoDevice
+	}
+
+	current, err := h.GetMountAt(target)
+	if err != nil {
+		return err
+	}
+	if current != nil {
+		if !sameDevice(current.Device, device) {
+			return fmt.Errorf("%s is already mounted on %s", current.Device, target)
+		}
+		if readOnly(current.Options) != readOnly(options) {
+			return fmt.Errorf("%s is already mounted on %s %s, %s was requested",
+				current.Device, target, accessMode(current.Options), accessMode(options))
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(target, 0750); err != nil {
+		return err
+	}
+
+	args := []string{}
+	if fsType != "" {
+		args = append(args, "-t", fsType)
+	}
+	if len(options) > 0 {
+		args = append(args, "-o", strings.Join(options, ","))
+	}
+	args = append(args, device, target)
+
+	if out, err := h.exec.Run("mount", args...); err != nil {
+		return fmt.Errorf("mount %s on %s: %v: %s", device, target, err, out)
+	}
+	return nil
+}
+
+// Unmount unmounts target. Unmounting a path that is not mounted is a no-op.
+func (h *Host) Unmount(target string) error {
+	current, err := h.GetMountAt(target)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return nil
+	}
+	if out, err := h.exec.Run("umount", target); err != nil {
+		return fmt.Errorf("umount %s: %v: %s", target, err, out)
+	}
+	return nil
+}
+
+// Expand grows the filesystem on device to fill the block device, typically
+// after Volume.SetVolumeSize. ext4 is grown through the device, xfs through
+// its mount point, so an xfs filesystem must be mounted.
+func (h *Host) Expand(device string) error {
+	fsType, err := h.FSType(device)
+	if err != nil {
+		return err
+	}
+
+	switch fsType {
+	case FSTypeExt4:
+		if out, err := h.exec.Run("resize2fs", device); err != nil {
+			return fmt.Errorf("resize2fs %s: %v: %s", device, err, out)
+		}
+	case FSTypeXFS:
+		mounts, err := h.GetMounts(device)
+		if err != nil {
+			return err
+		}
+		if len(mounts) == 0 {
+			return fmt.Errorf("xfs on %s must be mounted to be expanded", device)
+		}
+		if out, err := h.exec.Run("xfs_growfs", mounts[0].MountPoint); err != nil {
+			return fmt.Errorf("xfs_growfs %s: %v: %s", mounts[0].MountPoint, err, out)
+		}
+	case "":
+		return fmt.Errorf("device %s has no filesystem", device)
+	default:
+		return fmt.Errorf("unsupported filesystem type %q on %s", fsType, device)
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package host
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type exitError int
+
+func (e exitError) Error() string { return "exit status" }
+func (e exitError) ExitCode() int { return int(e) }
+
+type fakeResult struct {
+	out string
+	err error
+}
+
+// fakeExecutor records every command and answers from a table keyed by the
+// command name
+type fakeExecutor struct {
+	results map[string]fakeResult
+	calls   []string
+}
+
+func (f *fakeExecutor) Run(name string, args ...string) ([]byte, error) {
+	f.calls = append(f.calls, strings.Join(append([]string{name}, args...), " "))
+	r := f.results[name]
+	return []byte(r.out), r.err
+}
+
+func newTestHost(t *testing.T, mountinfo string, results map[string]fakeResult) (*Host, *fakeExecutor) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "mountinfo")
+	if err := ioutil.WriteFile(path, []byte(mountinfo), 0600); err != nil {
+		t.Fatal(err)
+	}
+	fe := &fakeExecutor{results: results}
+	h := NewWithExecutor(fe)
+	h.MountInfoPath = path
+	return h, fe
+}
+
+func TestFormat(t *testing.T) {
+	tests := map[string]struct {
+		blkid     fakeResult
+		fsType    string
+		wantCalls []string
+		wantErr   bool
+	}{
+		"blank device is formatted": {
+			blkid:  fakeResult{err: exitError(2)},
+			fsType: FSTypeExt4,
+			wantCalls: []string{
+				"blkid -p -o export /dev/scinia",
+				"mkfs.ext4 -F /dev/scinia",
+			},
+		},
+		"same filesystem is left alone": {
+			blkid:     fakeResult{out: "DEVNAME=/dev/scinia\nTYPE=xfs\n"},
+			fsType:    FSTypeXFS,
+			wantCalls: []string{"blkid -p -o export /dev/scinia"},
+		},
+		"different filesystem is an error": {
+			blkid:     fakeResult{out: "TYPE=ext4\n"},
+			fsType:    FSTypeXFS,
+			wantCalls: []string{"blkid -p -o export /dev/scinia"},
+			wantErr:   true,
+		},
+		"partition table is an error": {
+			blkid:     fakeResult{out: "PTTYPE=gpt\n"},
+			fsType:    FSTypeXFS,
+			wantCalls: []string{"blkid -p -o export /dev/scinia"},
+			wantErr:   true,
+		},
+		"blkid failure is an error": {
+			blkid:     fakeResult{err: exitError(4)},
+			fsType:    FSTypeXFS,
+			wantCalls: []string{"blkid -p -o export /dev/scinia"},
+			wantErr:   true,
+		},
+		"unsupported type": {
+			fsType:  "btrfs",
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			h, fe := newTestHost(t, "", map[string]fakeResult{"blkid": tc.blkid})
+			err := h.Format("/dev/scinia", tc.fsType)
+			if tc.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, tc.wantCalls, fe.calls)
+		})
+	}
+}
+
+func TestMount(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "data")
+	mountinfo := "22 1 8:1 / / rw,relatime - ext4 /dev/sda1 rw\n" +
+		"36 22 98:0 / " + target + " rw,noatime - xfs /dev/scinia rw\n"
+
+	h, fe := newTestHost(t, mountinfo, nil)
+
+	mounted, err := h.IsMounted("/dev/scinia")
+	assert.Nil(t, err)
+	assert.True(t, mounted)
+
+	m, err := h.GetMountAt(target)
+	assert.Nil(t, err)
+	if assert.NotNil(t, m) {
+		assert.Equal(t, "xfs", m.FSType)
+		assert.Equal(t, []string{"rw", "noatime"}, m.Options)
+	}
+
+	// already mounted there: nothing to do
+	assert.Nil(t, h.Mount("/dev/scinia", target, FSTypeXFS, nil))
+	assert.Nil(t, h.Mount("/dev/scinia", target, FSTypeXFS, []string{"rw", "noatime"}))
+	// already mounted there read-write: read-only was requested
+	assert.EqualError(t, h.Mount("/dev/scinia", target, FSTypeXFS, []string{"ro"}),
+		"/dev/scinia is already mounted on "+target+" read-write, read-only was requested")
+	// someone else is mounted there
+	assert.NotNil(t, h.Mount("/dev/scinib", target, FSTypeXFS, nil))
+	assert.Empty(t, fe.calls)
+
+	other := filepath.Join(t.TempDir(), "other")
+	assert.Nil(t, h.Mount("/dev/scinib", other, FSTypeExt4, []string{"rw", "noatime"}))
+	assert.Equal(t, []string{"mount -t ext4 -o rw,noatime /dev/scinib " + other}, fe.calls)
+
+	// already mounted there read-only
+	roTarget := filepath.Join(t.TempDir(), "ro")
+	h, fe = newTestHost(t, "37 22 98:16 / "+roTarget+" ro,noatime - xfs /dev/scinic rw\n", nil)
+	assert.Nil(t, h.Mount("/dev/scinic", roTarget, FSTypeXFS, []string{"noatime", "ro"}))
+	assert.EqualError(t, h.Mount("/dev/scinic", roTarget, FSTypeXFS, nil),
+		"/dev/scinic is already mounted on "+roTarget+" read-only, read-write was requested")
+	assert.NotNil(t, h.Mount("/dev/scinic", roTarget, FSTypeXFS, []string{"ro", "rw"}))
+	assert.Empty(t, fe.calls)
+
+	// unmounting something that is not mounted is a no-op
+	h, fe = newTestHost(t, mountinfo, nil)
+	assert.Nil(t, h.Unmount(other))
+	assert.Nil(t, h.Unmount(target))
+	assert.Equal(t, []string{"umount " + target}, fe.calls)
+}
+
+func TestExpand(t *testing.T) {
+	mountinfo := "36 22 98:0 / /mnt/data rw - xfs /dev/scinia rw\n"
+
+	h, fe := newTestHost(t, mountinfo, map[string]fakeResult{"blkid": {out: "TYPE=xfs\n"}})
+	assert.Nil(t, h.Expand("/dev/scinia"))
+	assert.Equal(t, "xfs_growfs /mnt/data", fe.calls[1])
+
+	// xfs can only be grown while mounted
+	assert.NotNil(t, h.Expand("/dev/scinib"))
+
+	h, fe = newTestHost(t, "", map[string]fakeResult{
+		"blkid":     {out: "TYPE=ext4\n"},
+		"resize2fs": {err: errors.New("boom")},
+	})
+	assert.NotNil(t, h.Expand("/dev/scinia"))
+	assert.Equal(t, "resize2fs /dev/scinia", fe.calls[1])
+
+	h, _ = newTestHost(t, "", map[string]fakeResult{"blkid": {err: exitError(2)}})
+	assert.NotNil(t, h.Expand("/dev/scinia"))
+}
+
+func TestMountByIDLink(t *testing.T) {
+	dev := t.TempDir()
+	device := filepath.Join(dev, "scinia")
+	if err := ioutil.WriteFile(device, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dev, "emc-vol-1a2b3c4d5e6f7a8b-c0ffee0000000001")
+	if err := os.Symlink("scinia", link); err != nil {
+		t.Fatal(err)
+	}
+
+	target := filepath.Join(t.TempDir(), "data")
+	mountinfo := "36 22 98:0 / " + target + " rw - xfs " + device + " rw\n"
+	h, fe := newTestHost(t, mountinfo, map[string]fakeResult{"blkid": {out: "TYPE=xfs\n"}})
+
+	// the by-id link names the mounted device
+	assert.Nil(t, h.Mount(link, target, FSTypeXFS, nil))
+	assert.Empty(t, fe.calls)
+
+	mounted, err := h.IsMounted(link)
+	assert.Nil(t, err)
+	assert.True(t, mounted)
+
+	assert.Nil(t, h.Expand(link))
+	assert.Equal(t, "xfs_growfs "+target, fe.calls[1])
+}
+
+func TestParseMountInfo(t *testing.T) {
+	mounts, err := ParseMountInfo(strings.NewReader(
+		"36 22 98:0 / /mnt/my\\040data rw - ext4 /dev/disk/by-id/a\\134b rw\n" +
+			"malformed line\n"))
+	assert.Nil(t, err)
+	if assert.Len(t, mounts, 1) {
+		assert.Equal(t, "/mnt/my data", mounts[0].MountPoint)
+		assert.Equal(t, `/dev/disk/by-id/a\b`, mounts[0].Device)
+		assert.Equal(t, "98:0", mounts[0].MajorMinor)
+	}
+}
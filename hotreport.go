@@ -0,0 +1,232 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goscaleio
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	// DefaultHotReportTopN is the default number of volumes and SDCs in a
+	// HotReport
+	DefaultHotReportTopN = 10
+	// DefaultHotReportInterval is the default time between the polls of a
+	// HotReport
+	DefaultHotReportInterval = 5 * time.Second
+)
+
+// HotReportOptions defines the options of GetHotReport
+type HotReportOptions struct {
+	// Metric ranks the volumes and SDCs, SampleIOPS if zero
+	Metric SampleMetric
+	// TopN is the number of volumes and SDCs reported,
+	// DefaultHotReportTopN if zero
+	TopN int
+	// Samples is the number of statistics polls averaged, 1 if zero
+	Samples int
+	// Interval is the time between polls, DefaultHotReportInterval if zero
+	Interval time.Duration
+}
+
+// HotVolume is a volume ranked by GetHotReport
+type HotVolume struct {
+	VolumeID string
+	// Value is the ranked metric: the sum of reads and writes for IOPS and
+	// bandwidth, the higher of the read and write latency for latency
+	Value float64
+	Read  float64
+	Write float64
+	// SdcIDs are the SDCs the volume is mapped to, busiest first
+	SdcIDs []string
+}
+
+// HotSdc is an SDC ranked by GetHotReport
+type HotSdc struct {
+	SdcID string
+	// Value is the ranked metric, as for HotVolume
+	Value float64
+	Read  float64
+	Write float64
+	// VolumeIDs are the volumes mapped to the SDC, busiest first
+	VolumeIDs []string
+}
+
+// HotReport lists the busiest volumes and SDCs of a system
+type HotReport struct {
+	Metric  SampleMetric
+	Samples int
+	Volumes []*HotVolume
+	Sdcs    []*HotSdc
+}
+
+// hotEntry is the ranked metric of one volume or SDC
+type hotEntry struct {
+	id                 string
+	value, read, write float64
+}
+
+// GetHotReport samples the statistics of every volume and SDC and returns
+// the busiest ones by the given metric averaged over the samples. Each
+// volume lists the SDCs driving it and each SDC the volumes it drives.
+func (s *System) GetHotReport(ctx context.Context, opts *HotReportOptions) (_ *HotReport, err error) {
+	c, end := s.client.trace("System.GetHotReport")
+	defer end(&err)
+
+	if opts == nil {
+		opts = &HotReportOptions{}
+	}
+	metric, topN, samples, interval := opts.Metric, opts.TopN, opts.Samples, opts.Interval
+	if metric == 0 {
+		metric = SampleIOPS
+	}
+	if topN == 0 {
+		topN = DefaultHotReportTopN
+	}
+	if samples == 0 {
+		samples = 1
+	}
+	if interval == 0 {
+		interval = DefaultHotReportInterval
+	}
+
+	readProperty, writeProperty := "userDataReadBwc", "userDataWriteBwc"
+	switch metric {
+	case SampleIOPS, SampleBandwidth:
+	case SampleLatency:
+		readProperty, writeProperty = "userDataSdcReadLatency", "userDataSdcWriteLatency"
+	default:
+		return nil, fmt.Errorf("unsupported metric %v", metric)
+	}
+	properties := []string{readProperty, writeProperty}
+
+	sampler, err := NewSampler(s, &SamplerOptions{
+		Queries: []StatisticsQuery{
+			{Type: StatisticsTypeVolume, Properties: properties},
+			{Type: StatisticsTypeSdc, Properties: properties},
+		},
+		Capacity: samples,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < samples; i++ {
+		if i > 0 {
+			select {
+			case <-time.After(interval):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		if err := sampler.Poll(); err != nil {
+			return nil, err
+		}
+	}
+
+	stats, err := s.withClient(c).QuerySelectedStatistics(
+		StatisticsQuery{Type: StatisticsTypeVolume, Properties: []string{"mappedSdcIds"}},
+		StatisticsQuery{Type: StatisticsTypeSdc, Properties: []string{"volumeIds"}},
+	)
+	if err != nil {
+		return nil, err
+	}
+	volumeStats, err := stats.Volumes()
+	if err != nil {
+		return nil, err
+	}
+	sdcStats, err := stats.Sdcs()
+	if err != nil {
+		return nil, err
+	}
+
+	rank := func(objectType string) ([]*hotEntry, map[string]float64) {
+		var entries []*hotEntry
+		values := make(map[string]float64)
+		for _, key := range sampler.Keys() {
+			// keys are sorted, an object's read and write keys are adjacent
+			if key.ObjectType != objectType ||
+				(len(entries) > 0 && entries[len(entries)-1].id == key.ID) {
+				continue
+			}
+			e := &hotEntry{id: key.ID}
+			e.read, _ = sampler.Mean(SampleKey{objectType, key.ID, readProperty}, metric)
+			e.write, _ = sampler.Mean(SampleKey{objectType, key.ID, writeProperty}, metric)
+			if metric == SampleLatency {
+				e.value = e.read
+				if e.write > e.value {
+					e.value = e.write
+				}
+			} else {
+				e.value = e.read + e.write
+			}
+			entries = append(entries, e)
+			values[e.id] = e.value
+		}
+		sort.SliceStable(entries, func(i, j int) bool {
+			if entries[i].value != entries[j].value {
+				return entries[i].value > entries[j].value
+			}
+			return entries[i].id < entries[j].id
+		})
+		if len(entries) > topN {
+			entries = entries[:topN]
+		}
+		return entries, values
+	}
+	volumes, volumeValues := rank(StatisticsTypeVolume)
+	sdcs, sdcValues := rank(StatisticsTypeSdc)
+
+	report := &HotReport{Metric: metric, Samples: samples}
+	for _, e := range volumes {
+		var sdcIDs []string
+		if vs, ok := volumeStats[e.id]; ok {
+			sdcIDs = busiestFirst(vs.MappedSdcIds, sdcValues)
+		}
+		report.Volumes = append(report.Volumes, &HotVolume{
+			VolumeID: e.id,
+			Value:    e.value,
+			Read:     e.read,
+			Write:    e.write,
+			SdcIDs:   sdcIDs,
+		})
+	}
+	for _, e := range sdcs {
+		var volumeIDs []string
+		if ss, ok := sdcStats[e.id]; ok {
+			volumeIDs = busiestFirst(ss.VolumeIds, volumeValues)
+		}
+		report.Sdcs = append(report.Sdcs, &HotSdc{
+			SdcID:     e.id,
+			Value:     e.value,
+			Read:      e.read,
+			Write:     e.write,
+			VolumeIDs: volumeIDs,
+		})
+	}
+
+	return report, nil
+}
+
+// busiestFirst returns a copy of ids sorted by descending value
+func busiestFirst(ids []string, values map[string]float64) []string {
+	out := append([]string(nil), ids...)
+	sort.SliceStable(out, func(i, j int) bool {
+		if values[out[i]] != values[out[j]] {
+			return values[out[i]] > values[out[j]]
+		}
+		return out[i] < out[j]
+	})
+	return out
+}
@@ -0,0 +1,110 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goscaleio
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GetHotReport(t *testing.T) {
+	polls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := string(testReadAll(t, r.Body))
+		if strings.Contains(body, "mappedSdcIds") {
+			fmt.Fprint(w, `{
+				"Volume": {
+					"v1": {"mappedSdcIds": ["sdc1", "sdc2"]},
+					"v2": {"mappedSdcIds": ["sdc1"]},
+					"v3": {"mappedSdcIds": []}
+				},
+				"Sdc": {
+					"sdc1": {"volumeIds": ["v2", "v1"]},
+					"sdc2": {"volumeIds": ["v1"]}
+				}
+			}`)
+			return
+		}
+		polls++
+		fmt.Fprintf(w, `{
+			"Volume": {
+				"v1": {
+					"userDataReadBwc": {"totalWeightInKb": 10, "numOccured": %d, "numSeconds": 1},
+					"userDataWriteBwc": {"totalWeightInKb": 10, "numOccured": 10, "numSeconds": 1},
+					"userDataSdcReadLatency": {"totalWeightInKb": 1000, "numOccured": 1, "numSeconds": 1}
+				},
+				"v2": {
+					"userDataReadBwc": {"totalWeightInKb": 10, "numOccured": 5, "numSeconds": 1},
+					"userDataWriteBwc": {"totalWeightInKb": 10, "numOccured": 0, "numSeconds": 1},
+					"userDataSdcWriteLatency": {"totalWeightInKb": 4000, "numOccured": 1, "numSeconds": 1}
+				},
+				"v3": {
+					"userDataReadBwc": {"totalWeightInKb": 0, "numOccured": 0, "numSeconds": 1}
+				}
+			},
+			"Sdc": {
+				"sdc1": {"userDataReadBwc": {"totalWeightInKb": 10, "numOccured": 15, "numSeconds": 1}},
+				"sdc2": {"userDataReadBwc": {"totalWeightInKb": 10, "numOccured": 30, "numSeconds": 1}}
+			}
+		}`, polls*20)
+	}))
+	defer ts.Close()
+
+	client, err := NewClientWithArgs(ts.URL, "3.5", true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	system := NewSystem(client)
+
+	report, err := system.GetHotReport(context.Background(), &HotReportOptions{
+		TopN:     2,
+		Samples:  2,
+		Interval: time.Millisecond,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, polls)
+	assert.Equal(t, SampleIOPS, report.Metric)
+
+	// v1 reads 20 then 40 IOPS and writes 10
+	assert.Len(t, report.Volumes, 2)
+	assert.Equal(t, &HotVolume{VolumeID: "v1", Value: 40, Read: 30, Write: 10,
+		SdcIDs: []string{"sdc2", "sdc1"}}, report.Volumes[0])
+	assert.Equal(t, &HotVolume{VolumeID: "v2", Value: 5, Read: 5, Write: 0,
+		SdcIDs: []string{"sdc1"}}, report.Volumes[1])
+
+	assert.Len(t, report.Sdcs, 2)
+	assert.Equal(t, "sdc2", report.Sdcs[0].SdcID)
+	assert.Equal(t, []string{"v1", "v2"}, report.Sdcs[1].VolumeIDs)
+
+	report, err = system.GetHotReport(context.Background(), &HotReportOptions{Metric: SampleLatency})
+	assert.Nil(t, err)
+	assert.Equal(t, "v2", report.Volumes[0].VolumeID)
+	assert.Equal(t, 0.004, report.Volumes[0].Value)
+	assert.Equal(t, "v1", report.Volumes[1].VolumeID)
+	assert.Equal(t, 0.001, report.Volumes[1].Read)
+
+	_, err = system.GetHotReport(context.Background(), &HotReportOptions{Metric: SampleMetric(9)})
+	assert.NotNil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = system.GetHotReport(ctx, &HotReportOptions{Samples: 2, Interval: time.Hour})
+	assert.Equal(t, context.Canceled, err)
+}
@@ -16,17 +16,16 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"time"
 
 	types "github.com/AnshumanPradipPatil1506/goscaleio/types/v1"
 )
 
 // GetInstance returns an instance
-func (c *Client) GetInstance(systemhref string) ([]*types.System, error) {
-	defer TimeSpent("GetInstance", time.Now())
+func (c *Client) GetInstance(systemhref string) (_ []*types.System, err error) {
+	c, end := c.trace("Client.GetInstance")
+	defer end(&err)
 
 	var (
-		err     error
 		system  = &types.System{}
 		systems []*types.System
 	)
@@ -52,11 +51,11 @@ func (c *Client) GetInstance(systemhref string) ([]*types.System, error) {
 // GetVolume returns a volume
 func (c *Client) GetVolume(
 	volumehref, volumeid, ancestorvolumeid, volumename string,
-	getSnapshots bool) ([]*types.Volume, error) {
-	defer TimeSpent("GetVolume", time.Now())
+	getSnapshots bool) (_ []*types.Volume, err error) {
+	c, end := c.trace("Client.GetVolume")
+	defer end(&err)
 
 	var (
-		err     error
 		path    string
 		volume  = &types.Volume{}
 		volumes []*types.Volume
@@ -107,8 +106,9 @@ func (c *Client) GetVolume(
 }
 
 // FindVolumeID returns a VolumeID
-func (c *Client) FindVolumeID(volumename string) (string, error) {
-	defer TimeSpent("FindVolumeID", time.Now())
+func (c *Client) FindVolumeID(volumename string) (_ string, err error) {
+	c, end := c.trace("Client.FindVolumeID")
+	defer end(&err)
 
 	volumeQeryIDByKeyParam := &types.VolumeQeryIDByKeyParam{
 		Name: volumename,
@@ -129,8 +129,15 @@ func (c *Client) FindVolumeID(volumename string) (string, error) {
 // CreateVolume creates a volume
 func (c *Client) CreateVolume(
 	volume *types.VolumeParam,
-	storagePoolName, protectionDomain string) (*types.VolumeResp, error) {
-	defer TimeSpent("CreateVolume", time.Now())
+	storagePoolName, protectionDomain string) (_ *types.VolumeResp, err error) {
+	c, end := c.trace("Client.CreateVolume")
+	defer end(&err)
+
+	c.audit("Volume", "")
+
+	if err := c.checkCapability(OpVolumeCreate); err != nil {
+		return nil, err
+	}
 
 	path := "/api/types/Volume/instances"
 
@@ -154,11 +161,11 @@ func (c *Client) CreateVolume(
 
 // GetStoragePool returns a storagepool
 func (c *Client) GetStoragePool(
-	storagepoolhref string) ([]*types.StoragePool, error) {
-	defer TimeSpent("GetStoragePool", time.Now())
+	storagepoolhref string) (_ []*types.StoragePool, err error) {
+	c, end := c.trace("Client.GetStoragePool")
+	defer end(&err)
 
 	var (
-		err          error
 		storagePool  = &types.StoragePool{}
 		storagePools []*types.StoragePool
 	)
@@ -183,8 +190,9 @@ func (c *Client) GetStoragePool(
 
 // FindStoragePool returns a StoragePool
 func (c *Client) FindStoragePool(
-	id, name, href, protectionDomain string) (*types.StoragePool, error) {
-	defer TimeSpent("FindStoragePool", time.Now())
+	id, name, href, protectionDomain string) (_ *types.StoragePool, err error) {
+	c, end := c.trace("Client.FindStoragePool")
+	defer end(&err)
 
 	storagePools, err := c.GetStoragePool(href)
 	if err != nil {
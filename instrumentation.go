@@ -0,0 +1,256 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goscaleio
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/AnshumanPradipPatil1506/goscaleio/api"
+	types "github.com/AnshumanPradipPatil1506/goscaleio/types/v1"
+)
+
+// Attribute keys set on spans and measurements. They follow the
+// OpenTelemetry semantic conventions where one exists.
+const (
+	AttributeHTTPMethod     = "http.method"
+	AttributeHTTPRoute      = "http.route"
+	AttributeHTTPStatusCode = "http.status_code"
+	AttributeErrorCode      = "powerflex.error_code"
+	AttributeRetryCount     = "powerflex.retry_count"
+)
+
+// Span names of the spans created by the library. Library calls are named
+// after the called method, such as "Volume.RemoveVolume".
+const (
+	SpanHTTPRequest = "HTTP request"
+)
+
+// Attribute is a key and value attached to a span or measurement
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Span is an operation traced by an Instrumentation
+type Span interface {
+	// SetAttributes adds attributes to the span
+	SetAttributes(attrs ...Attribute)
+	// RecordError marks the span as failed
+	RecordError(err error)
+	// End completes the span
+	End()
+}
+
+// Instrumentation receives the spans and measurements of a Client. Its
+// methods map onto an OpenTelemetry tracer and meter: Start onto
+// Tracer.Start, and RecordRequest onto a latency histogram and an error
+// counter. The otelgoscaleio module implements it with OpenTelemetry.
+type Instrumentation interface {
+	// Start starts a span, which is a child of the span in ctx if any
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+	// RecordRequest records the latency and result of an HTTP request
+	RecordRequest(ctx context.Context, duration time.Duration, err error, attrs ...Attribute)
+}
+
+// noopInstrumentation is the default Instrumentation, which discards
+// everything
+type noopInstrumentation struct{}
+
+type noopSpan struct{}
+
+func (noopInstrumentation) Start(
+	ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+func (noopInstrumentation) RecordRequest(
+	ctx context.Context, duration time.Duration, err error, attrs ...Attribute) {
+}
+
+func (noopSpan) SetAttributes(attrs ...Attribute) {}
+
+func (noopSpan) RecordError(err error) {}
+
+func (noopSpan) End() {}
+
+// SetInstrumentation sets the Instrumentation of the client, or restores the
+// no-op default if i is nil
+func (c *Client) SetInstrumentation(i Instrumentation) {
+	c.instrumentation = i
+}
+
+// WithContext returns a copy of the client whose library calls and
+// requests use ctx. Their spans are children of the span in ctx, if any,
+// and their requests are cancelled with ctx. Objects made with the copy,
+// such as NewSystem(client.WithContext(ctx)), use ctx too. The copy shares
+// its authentication with the client, so a copy that authenticates again
+// or changes the password also updates the client.
+func (c *Client) WithContext(ctx context.Context) *Client {
+	if ctx == nil {
+		panic("nil context")
+	}
+	c2 := *c
+	c2.ctx = ctx
+	c2.origin = nil
+	c2.operation = ""
+	c2.audited = nil
+	return &c2
+}
+
+// base returns the client the current library call was made on. Objects
+// returned by a call are made with it rather than with the copy carrying
+// the span of the call, which ends when the call returns.
+func (c *Client) base() *Client {
+	if c.origin != nil {
+		return c.origin
+	}
+	return c
+}
+
+// requestContext returns the context of the client's calls, set by
+// WithContext
+func (c *Client) requestContext() context.Context {
+	if c == nil || c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+func (c *Client) getInstrumentation() Instrumentation {
+	if c == nil || c.instrumentation == nil {
+		return noopInstrumentation{}
+	}
+	return c.instrumentation
+}
+
+// trace starts the span of a library call. It returns the copy of the
+// client to make the call with, whose requests and nested calls are
+// children of the span, and the func that ends the span with the error
+// the call returns, meant to be deferred:
+//
+//	c, end := s.client.trace("System.GetUser")
+//	defer end(&err)
+//
+// The call is also reported to ExternalTimeRecorder under its method name.
+func (c *Client) trace(name string) (*Client, func(errp *error)) {
+	start := time.Now()
+	ctx, span := c.getInstrumentation().Start(c.requestContext(), name)
+	call := c.WithContext(ctx)
+	call.origin = c.base()
+	call.operation = name
+	return call, func(errp *error) {
+		if errp != nil && *errp != nil {
+			if e, ok := (*errp).(*types.Error); ok {
+				span.SetAttributes(
+					Attribute{AttributeHTTPStatusCode, e.HTTPStatusCode},
+					Attribute{AttributeErrorCode, e.ErrorCode})
+			}
+			span.RecordError(*errp)
+		}
+		span.End()
+		TimeSpent(name[strings.LastIndex(name, ".")+1:], start)
+	}
+}
+
+// idSegmentRX matches the object IDs in instance paths, such as the
+// "::e7b3f5b000000001" of /api/instances/Volume::e7b3f5b000000001
+var idSegmentRX = regexp.MustCompile(`::[^/]+`)
+
+// routeTemplate returns the path of a request without object IDs and query
+func routeTemplate(uri string) string {
+	if i := strings.IndexByte(uri, '?'); i >= 0 {
+		uri = uri[:i]
+	}
+	return idSegmentRX.ReplaceAllString(uri, "::{id}")
+}
+
+// requestTrace traces one REST request including its retries
+type requestTrace struct {
+	instrumentation Instrumentation
+	ctx             context.Context
+	span            Span
+	start           time.Time
+	attrs           []Attribute
+	retries         int
+	// statusCode is the status of the last response, recorded by
+	// recordStatus
+	statusCode int
+}
+
+// requestTraceKey is the context key of the requestTrace of a request
+type requestTraceKey struct{}
+
+// recordStatus is the api.Interceptor that records the status code of every
+// response on the requestTrace of its request
+func recordStatus(next api.RoundTripFunc) api.RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		res, err := next(req)
+		if t, ok := req.Context().Value(requestTraceKey{}).(*requestTrace); ok && res != nil {
+			t.statusCode = res.StatusCode
+		}
+		return res, err
+	}
+}
+
+// traceRequest starts the span of an HTTP request. The returned context
+// carries the span and the trace, and is used for the request.
+func (c *Client) traceRequest(method, uri string) *requestTrace {
+	i := c.getInstrumentation()
+	attrs := []Attribute{
+		{AttributeHTTPMethod, method},
+		{AttributeHTTPRoute, routeTemplate(uri)},
+	}
+	ctx, span := i.Start(c.requestContext(), SpanHTTPRequest, attrs...)
+	t := &requestTrace{
+		instrumentation: i,
+		span:            span,
+		start:           time.Now(),
+		attrs:           attrs,
+	}
+	t.ctx = context.WithValue(ctx, requestTraceKey{}, t)
+	return t
+}
+
+// retry counts a retry of the request
+func (t *requestTrace) retry() {
+	t.retries++
+}
+
+// end ends the span of the request and records its measurements. The
+// status code is taken from err if the gateway returned an error, and is
+// statusCode otherwise, or the status of the last response if statusCode
+// is zero.
+func (t *requestTrace) end(statusCode int, err error) {
+	if statusCode == 0 {
+		statusCode = t.statusCode
+	}
+	if e, ok := err.(*types.Error); ok {
+		statusCode = e.HTTPStatusCode
+		t.attrs = append(t.attrs, Attribute{AttributeErrorCode, e.ErrorCode})
+	}
+	if statusCode != 0 {
+		t.attrs = append(t.attrs, Attribute{AttributeHTTPStatusCode, statusCode})
+	}
+	t.attrs = append(t.attrs, Attribute{AttributeRetryCount, t.retries})
+
+	t.span.SetAttributes(t.attrs[2:]...)
+	if err != nil {
+		t.span.RecordError(err)
+	}
+	t.span.End()
+	t.instrumentation.RecordRequest(t.ctx, time.Since(t.start), err, t.attrs...)
+}
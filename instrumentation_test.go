@@ -0,0 +1,313 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goscaleio
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	types "github.com/AnshumanPradipPatil1506/goscaleio/types/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordedSpan is a span captured by recordingInstrumentation
+type recordedSpan struct {
+	name   string
+	parent context.Context
+	attrs  map[string]interface{}
+	err    error
+	ended  bool
+}
+
+// recordedSpanKey is the context key of the recorded span started last
+type recordedSpanKey struct{}
+
+// parentSpan returns the recorded span the span was started under, if any
+func (s *recordedSpan) parentSpan() *recordedSpan {
+	parent, _ := s.parent.Value(recordedSpanKey{}).(*recordedSpan)
+	return parent
+}
+
+func (s *recordedSpan) SetAttributes(attrs ...Attribute) {
+	for _, a := range attrs {
+		s.attrs[a.Key] = a.Value
+	}
+}
+
+func (s *recordedSpan) RecordError(err error) {
+	s.err = err
+}
+
+func (s *recordedSpan) End() {
+	s.ended = true
+}
+
+// recordingInstrumentation captures the spans and requests of a client
+type recordingInstrumentation struct {
+	mu       sync.Mutex
+	spans    []*recordedSpan
+	requests []map[string]interface{}
+}
+
+func (r *recordingInstrumentation) Start(
+	ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	span := &recordedSpan{name: name, parent: ctx, attrs: map[string]interface{}{}}
+	span.SetAttributes(attrs...)
+	r.spans = append(r.spans, span)
+	return context.WithValue(ctx, recordedSpanKey{}, span), span
+}
+
+func (r *recordingInstrumentation) RecordRequest(
+	ctx context.Context, duration time.Duration, err error, attrs ...Attribute) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m := map[string]interface{}{"error": err != nil}
+	for _, a := range attrs {
+		m[a.Key] = a.Value
+	}
+	r.requests = append(r.requests, m)
+}
+
+func Test_Instrumentation(t *testing.T) {
+	unauthorized := true
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/login":
+			fmt.Fprint(w, `"token"`)
+		case "/api/instances/Volume::vol1/action/removeVolume":
+			if unauthorized {
+				unauthorized = false
+				w.WriteHeader(http.StatusUnauthorized)
+				fmt.Fprint(w, `{"message": "unauthorized", "errorCode": 0}`)
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"message": "volume is mapped", "errorCode": 94}`)
+		case "/api/instances/Volume::vol1/relationships/Statistics":
+			fmt.Fprint(w, `{}`)
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	client, err := NewClientWithArgs(ts.URL, "3.5", true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := &recordingInstrumentation{}
+	client.SetInstrumentation(recorder)
+
+	var recorded []string
+	ExternalTimeRecorder = func(name string, d time.Duration) {
+		recorded = append(recorded, name)
+	}
+	defer func() { ExternalTimeRecorder = nil }()
+
+	volume := NewVolume(client)
+	volume.Volume = &types.Volume{ID: "vol1", Links: []*types.Link{
+		{Rel: "self", HREF: "/api/instances/Volume::vol1"},
+		{Rel: "/api/Volume/relationship/Statistics", HREF: "/api/instances/Volume::vol1/relationships/Statistics"},
+	}}
+
+	_, err = volume.GetVolumeStatistics()
+	assert.Nil(t, err)
+	err = volume.RemoveVolume("ONLY_ME")
+	assert.NotNil(t, err)
+
+	names := make([]string, 0, len(recorder.spans))
+	for _, s := range recorder.spans {
+		assert.True(t, s.ended)
+		names = append(names, s.name)
+	}
+	assert.Equal(t, []string{
+		"Volume.GetVolumeStatistics", SpanHTTPRequest,
+		"Volume.RemoveVolume", SpanHTTPRequest,
+	}, names)
+	assert.Equal(t, []string{"GetVolumeStatistics", "RemoveVolume"}, recorded)
+
+	// requests are children of the call that sent them
+	assert.Nil(t, recorder.spans[0].parentSpan())
+	assert.Equal(t, recorder.spans[0], recorder.spans[1].parentSpan())
+	assert.Nil(t, recorder.spans[2].parentSpan())
+	assert.Equal(t, recorder.spans[2], recorder.spans[3].parentSpan())
+
+	// the call records the error it returned
+	assert.Nil(t, recorder.spans[0].err)
+	assert.Equal(t, err, recorder.spans[2].err)
+	assert.Equal(t, http.StatusInternalServerError, recorder.spans[2].attrs[AttributeHTTPStatusCode])
+	assert.Equal(t, 94, recorder.spans[2].attrs[AttributeErrorCode])
+
+	assert.Equal(t, map[string]interface{}{
+		AttributeHTTPMethod:     http.MethodGet,
+		AttributeHTTPRoute:      "/api/instances/Volume::{id}/relationships/Statistics",
+		AttributeHTTPStatusCode: http.StatusOK,
+		AttributeRetryCount:     0,
+	}, recorder.spans[1].attrs)
+	assert.Nil(t, recorder.spans[1].err)
+
+	assert.Equal(t, map[string]interface{}{
+		AttributeHTTPMethod:     http.MethodPost,
+		AttributeHTTPRoute:      "/api/instances/Volume::{id}/action/removeVolume",
+		AttributeHTTPStatusCode: http.StatusInternalServerError,
+		AttributeErrorCode:      94,
+		AttributeRetryCount:     1,
+	}, recorder.spans[3].attrs)
+	assert.NotNil(t, recorder.spans[3].err)
+
+	assert.Len(t, recorder.requests, 2)
+	assert.Equal(t, false, recorder.requests[0]["error"])
+	assert.Equal(t, true, recorder.requests[1]["error"])
+	assert.Equal(t, 94, recorder.requests[1][AttributeErrorCode])
+
+	// the default instrumentation discards everything
+	client.SetInstrumentation(nil)
+	_, err = volume.GetVolumeStatistics()
+	assert.Nil(t, err)
+	assert.Len(t, recorder.spans, 4)
+}
+
+type testContextKey struct{}
+
+func Test_InstrumentationContext(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/ServiceTemplate" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprint(w, `[]`)
+	}))
+	defer ts.Close()
+
+	client, err := NewClientWithArgs(ts.URL, "3.5", true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := &recordingInstrumentation{}
+	client.SetInstrumentation(recorder)
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), testContextKey{}, "caller"))
+	_, err = client.WithContext(ctx).GetTemplate("tmpl")
+	assert.Nil(t, err)
+
+	// the call is a child of the caller's span and its request a child of
+	// the call, and the request of the template call is traced with its
+	// status
+	assert.Len(t, recorder.spans, 2)
+	assert.Equal(t, "Client.GetTemplate", recorder.spans[0].name)
+	assert.Equal(t, SpanHTTPRequest, recorder.spans[1].name)
+	for _, s := range recorder.spans {
+		assert.Equal(t, "caller", s.parent.Value(testContextKey{}))
+	}
+	assert.Nil(t, recorder.spans[0].parentSpan())
+	assert.Equal(t, recorder.spans[0], recorder.spans[1].parentSpan())
+	assert.Equal(t, http.StatusAccepted, recorder.spans[1].attrs[AttributeHTTPStatusCode])
+	assert.Equal(t, "/api/v1/ServiceTemplate", recorder.spans[1].attrs[AttributeHTTPRoute])
+
+	// requests are cancelled with the context
+	cancel()
+	_, err = client.WithContext(ctx).GetTemplate("tmpl")
+	assert.NotNil(t, err)
+	assert.NotNil(t, recorder.spans[3].err)
+
+	// the client itself is not bound to the context
+	_, err = client.GetTemplate("tmpl")
+	assert.Nil(t, err)
+}
+
+func Test_WithContextAuthentication(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/login" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		_, password, _ := r.BasicAuth()
+		fmt.Fprintf(w, `"token-%s"`, password)
+	}))
+	defer ts.Close()
+
+	client, err := NewClientWithArgs(ts.URL, "3.5", true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.Authenticate(&ConfigConnect{Username: "admin", Password: "old"})
+	assert.Nil(t, err)
+
+	// a copy that authenticates again updates the client and other copies
+	copy1 := client.WithContext(context.Background())
+	copy2 := client.WithContext(context.Background())
+	_, err = copy1.Authenticate(&ConfigConnect{Username: "admin", Password: "new"})
+	assert.Nil(t, err)
+	for _, c := range []*Client{client, copy2} {
+		assert.Equal(t, "new", c.auth.get().Password)
+		assert.Equal(t, "3.5", c.GetVersion())
+		assert.Equal(t, "token-new", c.GetToken())
+	}
+}
+
+func Test_InstrumentationNesting(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/types/System/instances":
+			fmt.Fprint(w, `[{"id": "sys1"}]`)
+		case "/api/instances/System::sys1/relationships/User":
+			fmt.Fprint(w, `[]`)
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	client, err := NewClientWithArgs(ts.URL, "3.5", true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := &recordingInstrumentation{}
+	client.SetInstrumentation(recorder)
+
+	system, err := client.FindSystem("sys1", "", "")
+	assert.Nil(t, err)
+	_, err = system.GetUser()
+	assert.Nil(t, err)
+
+	names := make([]string, 0, len(recorder.spans))
+	for _, s := range recorder.spans {
+		names = append(names, s.name)
+	}
+	assert.Equal(t, []string{
+		"Client.FindSystem", "Client.GetInstance", SpanHTTPRequest,
+		"System.GetUser", SpanHTTPRequest,
+	}, names)
+
+	// calls made by a call are its children
+	assert.Nil(t, recorder.spans[0].parentSpan())
+	assert.Equal(t, recorder.spans[0], recorder.spans[1].parentSpan())
+	assert.Equal(t, recorder.spans[1], recorder.spans[2].parentSpan())
+
+	// the returned system is not bound to the span of the call that found it
+	assert.Nil(t, recorder.spans[3].parentSpan())
+	assert.Equal(t, recorder.spans[3], recorder.spans[4].parentSpan())
+}
+
+func Test_routeTemplate(t *testing.T) {
+	assert.Equal(t, "/api/types/Volume/instances", routeTemplate("/api/types/Volume/instances"))
+	assert.Equal(t, "api/instances/Sdc::{id}", routeTemplate("api/instances/Sdc::abc"))
+	assert.Equal(t, "/api/instances/System::{id}/relationships/Sdc",
+		routeTemplate("/api/instances/System::1234/relationships/Sdc?x=y"))
+}
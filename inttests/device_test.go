@@ -64,12 +64,12 @@ func TestGetDeviceByAttribute(t *testing.T) {
 		return
 	}
 
-	found, err := pool.FindDevice("Name", devices[0].Device.Name)
+	found, err := pool.FindDevice(goscaleio.ByName(devices[0].Device.Name))
 	assert.Nil(t, err)
 	assert.NotNil(t, found)
 	assert.Equal(t, devices[0].Device.Name, found.Name)
 
-	found, err = pool.FindDevice("ID", devices[0].Device.ID)
+	found, err = pool.FindDevice(goscaleio.ByID(devices[0].Device.ID))
 	assert.Nil(t, err)
 	assert.NotNil(t, found)
 	assert.Equal(t, devices[0].Device.ID, found.ID)
@@ -90,11 +90,11 @@ func TestGetDeviceByAttributeInvalid(t *testing.T) {
 		return
 	}
 
-	found, err := pool.FindDevice("Name", invalidIdentifier)
+	found, err := pool.FindDevice(goscaleio.ByName(invalidIdentifier))
 	assert.NotNil(t, err)
 	assert.Nil(t, found)
 
-	found, err = pool.FindDevice("ID", invalidIdentifier)
+	found, err = pool.FindDevice(goscaleio.ByID(invalidIdentifier))
 	assert.NotNil(t, err)
 	assert.Nil(t, found)
 }
@@ -57,17 +57,17 @@ func TestGetSdcByAttribute(t *testing.T) {
 		return
 	}
 
-	found, err := system.FindSdc("Name", Sdc[0].Sdc.Name)
+	found, err := system.FindSdc(goscaleio.ByName(Sdc[0].Sdc.Name))
 	assert.Nil(t, err)
 	assert.NotNil(t, found)
 	assert.Equal(t, Sdc[0].Sdc.Name, found.Sdc.Name)
 
-	found, err = system.FindSdc("ID", Sdc[0].Sdc.ID)
+	found, err = system.FindSdc(goscaleio.ByID(Sdc[0].Sdc.ID))
 	assert.Nil(t, err)
 	assert.NotNil(t, found)
 	assert.Equal(t, Sdc[0].Sdc.ID, found.Sdc.ID)
 
-	found, err = system.FindSdc("SdcGUID", Sdc[0].Sdc.SdcGUID)
+	found, err = system.FindSdc(goscaleio.ByGUID(Sdc[0].Sdc.SdcGUID))
 	assert.Nil(t, err)
 	assert.NotNil(t, found)
 	assert.Equal(t, Sdc[0].Sdc.SdcGUID, found.Sdc.SdcGUID)
@@ -87,11 +87,11 @@ func TestGetSdcByAttributeInvalid(t *testing.T) {
 		return
 	}
 
-	found, err := system.FindSdc("Name", invalidIdentifier)
+	found, err := system.FindSdc(goscaleio.ByName(invalidIdentifier))
 	assert.NotNil(t, err)
 	assert.Nil(t, found)
 
-	found, err = system.FindSdc("ID", invalidIdentifier)
+	found, err = system.FindSdc(goscaleio.ByID(invalidIdentifier))
 	assert.NotNil(t, err)
 	assert.Nil(t, found)
 }
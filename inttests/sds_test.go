@@ -72,12 +72,12 @@ func TestGetSDSByAttribute(t *testing.T) {
 		return
 	}
 
-	found, err := pd.FindSds("Name", sds[0].Sds.Name)
+	found, err := pd.FindSds(goscaleio.ByName(sds[0].Sds.Name))
 	assert.Nil(t, err)
 	assert.NotNil(t, found)
 	assert.Equal(t, sds[0].Sds.Name, found.Name)
 
-	found, err = pd.FindSds("ID", sds[0].Sds.ID)
+	found, err = pd.FindSds(goscaleio.ByID(sds[0].Sds.ID))
 	assert.Nil(t, err)
 	assert.NotNil(t, found)
 	assert.Equal(t, sds[0].Sds.ID, found.ID)
@@ -97,11 +97,11 @@ func TestGetSDSByAttributeInvalid(t *testing.T) {
 		return
 	}
 
-	found, err := pd.FindSds("Name", invalidIdentifier)
+	found, err := pd.FindSds(goscaleio.ByName(invalidIdentifier))
 	assert.NotNil(t, err)
 	assert.Nil(t, found)
 
-	found, err = pd.FindSds("ID", invalidIdentifier)
+	found, err = pd.FindSds(goscaleio.ByID(invalidIdentifier))
 	assert.NotNil(t, err)
 	assert.Nil(t, found)
 }
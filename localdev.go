@@ -0,0 +1,180 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goscaleio
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/AnshumanPradipPatil1506/goscaleio/host"
+)
+
+// sysfs reports block device sizes in 512 byte sectors
+const sectorSize = 512
+
+// mountEntry is one line of /proc/self/mountinfo
+type mountEntry struct {
+	source     string
+	mountPoint string
+	majorMinor string
+}
+
+// readMountInfo returns the mounts of the current process. A missing
+// mountinfo file, as on a test tree without one, reads as no mounts.
+func readMountInfo() ([]mountEntry, error) {
+	f, err := os.Open(FSDevDirectoryPrefix + "/proc/self/mountinfo")
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	infos, err := host.ParseMountInfo(f)
+	if err != nil {
+		return nil, err
+	}
+	mounts := make([]mountEntry, 0, len(infos))
+	for _, m := range infos {
+		mounts = append(mounts, mountEntry{
+			source:     m.Device,
+			mountPoint: m.MountPoint,
+			majorMinor: m.MajorMinor,
+		})
+	}
+	return mounts, nil
+}
+
+// trimDevPrefix strips FSDevDirectoryPrefix so a device path can be compared
+// with the paths the kernel reports
+func trimDevPrefix(device string) string {
+	if FSDevDirectoryPrefix == "" {
+		return device
+	}
+	return strings.TrimPrefix(device, filepath.Clean(FSDevDirectoryPrefix))
+}
+
+// resolveDevicePath returns the device a mount source links to, as the
+// kernel names it, or the source itself if it cannot be resolved
+func resolveDevicePath(source string) string {
+	if !strings.HasPrefix(source, "/") {
+		return source
+	}
+	resolved, err := filepath.EvalSymlinks(FSDevDirectoryPrefix + source)
+	if err != nil {
+		return source
+	}
+	return trimDevPrefix(resolved)
+}
+
+func readSysfsString(path string) string {
+	data, err := ioutil.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func readSysfsInt(path string) (int64, error) {
+	data, err := ioutil.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+}
+
+func listDir(path string) []string {
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+// populateDeviceInfo fills in the size, read-only flag, partitions, holders,
+// in-flight I/O and mount points of the volume's block device from
+// /sys/block and the given mounts. Mounts are matched on the major:minor
+// number of the device or a partition, or on the source path with symbolic
+// links such as /dev/disk/by-id entries resolved. Missing sysfs entries leave the corresponding fields empty.
+func populateDeviceInfo(vol *SdcMappedVolume, mounts []mountEntry) {
+	if vol.SdcDevice == "" {
+		return
+	}
+	name := filepath.Base(vol.SdcDevice)
+	sysDir := fmt.Sprintf("%s/sys/block/%s", FSDevDirectoryPrefix, name)
+
+	if sectors, err := readSysfsInt(sysDir + "/size"); err == nil {
+		vol.SizeInBytes = sectors * sectorSize
+	}
+	if ro, err := readSysfsInt(sysDir + "/ro"); err == nil {
+		vol.ReadOnly = ro != 0
+	}
+	if data, err := ioutil.ReadFile(filepath.Clean(sysDir + "/inflight")); err == nil {
+		fields := strings.Fields(string(data))
+		if len(fields) == 2 {
+			vol.InFlightReads, _ = strconv.ParseInt(fields[0], 10, 64)
+			vol.InFlightWrites, _ = strconv.ParseInt(fields[1], 10, 64)
+		}
+	}
+
+	devices := []string{name}
+	devNums := make(map[string]bool)
+	if dev := readSysfsString(sysDir + "/dev"); dev != "" {
+		devNums[dev] = true
+	}
+	vol.Holders = append(vol.Holders, listDir(sysDir+"/holders")...)
+	for _, entry := range listDir(sysDir) {
+		if !strings.HasPrefix(entry, name) {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(sysDir, entry, "partition")); err != nil {
+			continue
+		}
+		vol.Partitions = append(vol.Partitions, entry)
+		devices = append(devices, entry)
+		if dev := readSysfsString(filepath.Join(sysDir, entry, "dev")); dev != "" {
+			devNums[dev] = true
+		}
+		vol.Holders = append(vol.Holders, listDir(filepath.Join(sysDir, entry, "holders"))...)
+	}
+
+	for _, m := range mounts {
+		if mountsDevice(m, devNums, devices) {
+			vol.MountPoints = append(vol.MountPoints, m.mountPoint)
+		}
+	}
+}
+
+// mountsDevice reports whether m is a mount of one of the named devices,
+// whose major:minor numbers are devNums
+func mountsDevice(m mountEntry, devNums map[string]bool, devices []string) bool {
+	if devNums[m.majorMinor] {
+		return true
+	}
+	source := resolveDevicePath(m.source)
+	for _, d := range devices {
+		if source == "/dev/"+d {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,282 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goscaleio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	types "github.com/AnshumanPradipPatil1506/goscaleio/types/v1"
+)
+
+const (
+	// DefaultNodeAttachTimeout is how long NodeAttach waits for the block device to appear
+	DefaultNodeAttachTimeout = 60 * time.Second
+	// DefaultNodeDetachTimeout is how long NodeDetach waits for the block device to disappear
+	DefaultNodeDetachTimeout = 60 * time.Second
+	// DefaultNodePollInterval is how often the local device list is checked while waiting
+	DefaultNodePollInterval = time.Second
+)
+
+var (
+	errNoSdcID = errors.New("the ID of the local SDC is required")
+	// errNodeWaitTimeout is returned by waitForLocalVolume on timeout
+	errNodeWaitTimeout = errors.New("timed out")
+)
+
+// NodeAttachOptions defines the options for NodeAttach
+type NodeAttachOptions struct {
+	// SdcID is the ID of the local SDC as known to the MDM cluster
+	SdcID string
+	// SystemID restricts the device lookup to one system, any system if empty
+	SystemID string
+	// AllowMultipleMappings permits the volume to be mapped to other SDCs as well
+	AllowMultipleMappings bool
+	// Timeout is how long to wait for the device, DefaultNodeAttachTimeout if zero
+	Timeout time.Duration
+	// PollInterval is how often to look for the device, DefaultNodePollInterval if zero
+	PollInterval time.Duration
+}
+
+// NodeDetachOptions defines the options for NodeDetach
+type NodeDetachOptions struct {
+	// SdcID is the ID of the local SDC as known to the MDM cluster
+	SdcID string
+	// SystemID restricts the device lookup to one system, any system if empty
+	SystemID string
+	// Timeout is how long to wait for the device to go away, DefaultNodeDetachTimeout if zero
+	Timeout time.Duration
+	// PollInterval is how often to look for the device, DefaultNodePollInterval if zero
+	PollInterval time.Duration
+}
+
+// NodeAttach maps the volume to the local SDC, asks the SDC to rescan and waits
+// until the volume's /dev/disk/by-id entry appears. It returns the mapped
+// volume with the resolved block device in SdcDevice. A volume that is
+// already mapped to the SDC is not mapped again. The requests and the wait
+// are cancelled with ctx. If NodeAttach mapped the volume and the device does
+// not appear, the volume is unmapped again.
+func (v *Volume) NodeAttach(
+	ctx context.Context, opts *NodeAttachOptions) (_ *SdcMappedVolume, err error) {
+	c, end := v.client.WithContext(ctx).trace("Volume.NodeAttach")
+	defer end(&err)
+
+	if opts == nil || opts.SdcID == "" {
+		return nil, errNoSdcID
+	}
+
+	if !isMappedToSdc(v.Volume, opts.SdcID) {
+		mapParam := &types.MapVolumeSdcParam{
+			SdcID: opts.SdcID,
+		}
+		if opts.AllowMultipleMappings {
+			mapParam.AllowMultipleMappings = "TRUE"
+		}
+		if err := v.withClient(c).MapVolumeSdc(mapParam); err != nil {
+			return nil, fmt.Errorf("NodeAttach: map volume %s: %v", v.Volume.ID, err)
+		}
+		v.Volume.MappedSdcInfo = append(v.Volume.MappedSdcInfo,
+			&types.MappedSdcInfo{SdcID: opts.SdcID})
+
+		defer func() {
+			if err == nil {
+				return
+			}
+			// unmapped with the client of the volume, as ctx may be done
+			unmapParam := &types.UnmapVolumeSdcParam{
+				SdcID: opts.SdcID,
+			}
+			if uerr := v.UnmapVolumeSdc(unmapParam); uerr != nil {
+				err = fmt.Errorf("%v, and unmapping it failed: %v", err, uerr)
+				return
+			}
+			removeSdcMapping(v.Volume, opts.SdcID)
+		}()
+	}
+
+	rc, err := DrvCfgQueryRescan()
+	if err != nil {
+		return nil, fmt.Errorf("NodeAttach: rescan: %v", err)
+	}
+	// DrvCfgQueryRescan reports a failure code without an error
+	if code, _ := strconv.ParseInt(rc, 10, 64); code != rcSuccess {
+		return nil, fmt.Errorf("NodeAttach: rescan: %v", errRC("rescan", code))
+	}
+
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = DefaultNodeAttachTimeout
+	}
+	interval := opts.PollInterval
+	if interval == 0 {
+		interval = DefaultNodePollInterval
+	}
+
+	mapped, err := waitForLocalVolume(ctx, opts.SystemID, v.Volume.ID, true,
+		timeout, interval)
+	if err == errNodeWaitTimeout {
+		return nil, fmt.Errorf(
+			"NodeAttach: device for volume %s did not appear within %v",
+			v.Volume.ID, timeout)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("NodeAttach: volume %s: %v", v.Volume.ID, err)
+	}
+	return mapped, nil
+}
+
+// NodeDetach unmaps the volume from the local SDC and waits for its
+// /dev/disk/by-id entry to disappear. A volume that is not mapped to the SDC
+// is not unmapped again. The volume is not unmapped if its block device is
+// still mounted or held by another device such as a device-mapper target.
+// The requests and the wait are cancelled with ctx.
+func (v *Volume) NodeDetach(ctx context.Context, opts *NodeDetachOptions) (err error) {
+	c, end := v.client.WithContext(ctx).trace("Volume.NodeDetach")
+	defer end(&err)
+
+	if opts == nil || opts.SdcID == "" {
+		return errNoSdcID
+	}
+
+	mapped, err := findLocalVolume(opts.SystemID, v.Volume.ID, true)
+	if err != nil {
+		return err
+	}
+	if mapped != nil {
+		if err := checkDeviceIdle(mapped); err != nil {
+			return fmt.Errorf("NodeDetach: volume %s: %v", v.Volume.ID, err)
+		}
+	}
+
+	if isMappedToSdc(v.Volume, opts.SdcID) {
+		unmapParam := &types.UnmapVolumeSdcParam{
+			SdcID: opts.SdcID,
+		}
+		if err := v.withClient(c).UnmapVolumeSdc(unmapParam); err != nil {
+			return fmt.Errorf("NodeDetach: unmap volume %s: %v", v.Volume.ID, err)
+		}
+		removeSdcMapping(v.Volume, opts.SdcID)
+	}
+
+	if mapped == nil {
+		return nil
+	}
+
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = DefaultNodeDetachTimeout
+	}
+	interval := opts.PollInterval
+	if interval == 0 {
+		interval = DefaultNodePollInterval
+	}
+
+	_, err = waitForLocalVolume(ctx, opts.SystemID, v.Volume.ID, false,
+		timeout, interval)
+	if err == errNodeWaitTimeout {
+		return fmt.Errorf(
+			"NodeDetach: device for volume %s still present after %v",
+			v.Volume.ID, timeout)
+	}
+	if err != nil {
+		return fmt.Errorf("NodeDetach: volume %s: %v", v.Volume.ID, err)
+	}
+	return nil
+}
+
+// waitForLocalVolume checks every interval whether the device of the volume
+// is present, until it is present or, if present is false, gone. It returns
+// errNodeWaitTimeout after timeout and the error of ctx once ctx is done.
+func waitForLocalVolume(
+	ctx context.Context, systemID, volumeID string, present bool,
+	timeout, interval time.Duration) (*SdcMappedVolume, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		mapped, err := findLocalVolume(systemID, volumeID, false)
+		if err != nil {
+			return nil, err
+		}
+		if (mapped != nil) == present {
+			return mapped, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timer.C:
+			return nil, errNodeWaitTimeout
+		case <-ticker.C:
+		}
+	}
+}
+
+func isMappedToSdc(vol *types.Volume, sdcID string) bool {
+	for _, info := range vol.MappedSdcInfo {
+		if info != nil && info.SdcID == sdcID {
+			return true
+		}
+	}
+	return false
+}
+
+// removeSdcMapping drops the SDC from the volume's mapping info
+func removeSdcMapping(vol *types.Volume, sdcID string) {
+	kept := vol.MappedSdcInfo[:0]
+	for _, info := range vol.MappedSdcInfo {
+		if info == nil || info.SdcID != sdcID {
+			kept = append(kept, info)
+		}
+	}
+	vol.MappedSdcInfo = kept
+}
+
+// findLocalVolume returns the local mapping of a volume, or nil if the
+// volume's device is not present. The device information is only read if
+// withDeviceInfo is set.
+func findLocalVolume(systemID, volumeID string, withDeviceInfo bool) (*SdcMappedVolume, error) {
+	find := GetLocalVolumeMapByRegex
+	if withDeviceInfo {
+		find = GetLocalVolumeMapWithDeviceInfo
+	}
+	vols, err := find(systemID, volumeID)
+	if err != nil {
+		return nil, err
+	}
+	for _, vol := range vols {
+		if vol.VolumeID == volumeID {
+			return vol, nil
+		}
+	}
+	return nil, nil
+}
+
+// checkDeviceIdle returns an error if the device is mounted or has holders
+func checkDeviceIdle(vol *SdcMappedVolume) error {
+	device := trimDevPrefix(vol.SdcDevice)
+	if vol.Mounted() {
+		return fmt.Errorf("device %s is mounted on %s",
+			device, strings.Join(vol.MountPoints, ","))
+	}
+	if len(vol.Holders) > 0 {
+		return fmt.Errorf("device %s is held by %s",
+			device, strings.Join(vol.Holders, ","))
+	}
+	return nil
+}
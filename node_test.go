@@ -0,0 +1,263 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goscaleio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	types "github.com/AnshumanPradipPatil1506/goscaleio/types/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testNodeSystemID = "1a2b3c4d5e6f7a8b"
+	testNodeVolumeID = "c0ffee0000000001"
+	testNodeSdcID    = "d00d000000000001"
+)
+
+// setupFakeDev creates an empty /dev, /proc and /sys tree under a temporary
+// FSDevDirectoryPrefix and installs a FakeSDCDriver for the test
+func setupFakeDev(t *testing.T) (string, *FakeSDCDriver) {
+	t.Helper()
+	prefix := t.TempDir()
+	for _, dir := range []string{"dev/disk/by-id", "proc/self", "sys/block/scinia/holders"} {
+		if err := os.MkdirAll(filepath.Join(prefix, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(prefix, "dev/scinia"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	driver := NewFakeSDCDriver(mockGUID, ConfiguredCluster{
+		SystemID: testNodeSystemID,
+		SdcID:    testNodeSdcID,
+	})
+	oldPrefix := FSDevDirectoryPrefix
+	FSDevDirectoryPrefix = prefix
+	SetSDCDriver(driver)
+	t.Cleanup(func() {
+		FSDevDirectoryPrefix = oldPrefix
+		SetSDCDriver(nil)
+	})
+	return prefix, driver
+}
+
+func fakeVolumeLink(prefix string) string {
+	return filepath.Join(prefix, "dev/disk/by-id",
+		fmt.Sprintf("emc-vol-%s-%s", testNodeSystemID, testNodeVolumeID))
+}
+
+func Test_NodeAttachDetach(t *testing.T) {
+	prefix, driver := setupFakeDev(t)
+	// the device shows up once the SDC rescans
+	driver.OnRescan = func() {
+		if err := os.Symlink("../../scinia", fakeVolumeLink(prefix)); err != nil {
+			t.Error(err)
+		}
+	}
+
+	var paths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		switch r.URL.Path {
+		case fmt.Sprintf("/api/instances/Volume::%s/action/addMappedSdc", testNodeVolumeID):
+		case fmt.Sprintf("/api/instances/Volume::%s/action/removeMappedSdc", testNodeVolumeID):
+			if err := os.Remove(fakeVolumeLink(prefix)); err != nil {
+				t.Error(err)
+			}
+		default:
+			t.Errorf("unexpected path: %q", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client, err := NewClientWithArgs(ts.URL, "", true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vol := NewVolume(client)
+	vol.Volume = &types.Volume{ID: testNodeVolumeID}
+
+	mapped, err := vol.NodeAttach(context.Background(), &NodeAttachOptions{
+		SdcID:        testNodeSdcID,
+		SystemID:     testNodeSystemID,
+		Timeout:      time.Second,
+		PollInterval: 10 * time.Millisecond,
+	})
+	assert.Nil(t, err)
+	if assert.NotNil(t, mapped) {
+		assert.Equal(t, testNodeSystemID, mapped.MdmID)
+		assert.Equal(t, testNodeVolumeID, mapped.VolumeID)
+		assert.Equal(t, "scinia", filepath.Base(mapped.SdcDevice))
+	}
+	assert.Equal(t, 1, driver.Calls(SDCOpRescan))
+
+	// a mounted device must not be unmapped
+	mountinfo := "36 35 98:0 / /mnt/data rw,noatime master:1 - ext4 /dev/scinia rw\n"
+	if err := ioutil.WriteFile(filepath.Join(prefix, "proc/self/mountinfo"), []byte(mountinfo), 0600); err != nil {
+		t.Fatal(err)
+	}
+	err = vol.NodeDetach(context.Background(), &NodeDetachOptions{SdcID: testNodeSdcID, SystemID: testNodeSystemID})
+	assert.EqualError(t, err, fmt.Sprintf("NodeDetach: volume %s: device /dev/scinia is mounted on /mnt/data", testNodeVolumeID))
+
+	// nor one mounted through its /dev/disk/by-id link
+	mountinfo = fmt.Sprintf("36 35 98:0 / /mnt/byid rw,noatime master:1 - ext4 /dev/disk/by-id/emc-vol-%s-%s rw\n",
+		testNodeSystemID, testNodeVolumeID)
+	if err := ioutil.WriteFile(filepath.Join(prefix, "proc/self/mountinfo"), []byte(mountinfo), 0600); err != nil {
+		t.Fatal(err)
+	}
+	err = vol.NodeDetach(context.Background(), &NodeDetachOptions{SdcID: testNodeSdcID, SystemID: testNodeSystemID})
+	assert.EqualError(t, err, fmt.Sprintf("NodeDetach: volume %s: device /dev/scinia is mounted on /mnt/byid", testNodeVolumeID))
+
+	// nor one that is held by device-mapper
+	os.Remove(filepath.Join(prefix, "proc/self/mountinfo"))
+	holder := filepath.Join(prefix, "sys/block/scinia/holders/dm-0")
+	if err := ioutil.WriteFile(holder, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	err = vol.NodeDetach(context.Background(), &NodeDetachOptions{SdcID: testNodeSdcID, SystemID: testNodeSystemID})
+	assert.EqualError(t, err, fmt.Sprintf("NodeDetach: volume %s: device /dev/scinia is held by dm-0", testNodeVolumeID))
+
+	os.Remove(holder)
+	err = vol.NodeDetach(context.Background(), &NodeDetachOptions{
+		SdcID:        testNodeSdcID,
+		SystemID:     testNodeSystemID,
+		Timeout:      time.Second,
+		PollInterval: 10 * time.Millisecond,
+	})
+	assert.Nil(t, err)
+
+	// detaching again does not unmap again
+	err = vol.NodeDetach(context.Background(), &NodeDetachOptions{SdcID: testNodeSdcID, SystemID: testNodeSystemID})
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{
+		fmt.Sprintf("/api/instances/Volume::%s/action/addMappedSdc", testNodeVolumeID),
+		fmt.Sprintf("/api/instances/Volume::%s/action/removeMappedSdc", testNodeVolumeID),
+	}, paths)
+}
+
+func Test_NodeAttachTimeout(t *testing.T) {
+	_, driver := setupFakeDev(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("volume is already mapped, unexpected path: %q", r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	client, err := NewClientWithArgs(ts.URL, "", true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vol := NewVolume(client)
+	vol.Volume = &types.Volume{
+		ID:            testNodeVolumeID,
+		MappedSdcInfo: []*types.MappedSdcInfo{{SdcID: testNodeSdcID}},
+	}
+
+	_, err = vol.NodeAttach(context.Background(), &NodeAttachOptions{
+		SdcID:        testNodeSdcID,
+		Timeout:      30 * time.Millisecond,
+		PollInterval: 10 * time.Millisecond,
+	})
+	assert.NotNil(t, err)
+
+	// a failing rescan stops the attach
+	driver.SetError(SDCOpRescan, errors.New("Rescan error: no such device"))
+	_, err = vol.NodeAttach(context.Background(), &NodeAttachOptions{SdcID: testNodeSdcID})
+	assert.EqualError(t, err, "NodeAttach: rescan: Rescan error: no such device")
+
+	// as does one that returns a failure code, which DrvCfgQueryRescan
+	// does not report as an error
+	driver.SetError(SDCOpRescan, nil)
+	driver.SetRC(SDCOpRescan, 3)
+	rc, err := DrvCfgQueryRescan()
+	assert.Nil(t, err)
+	assert.Equal(t, "3", rc)
+	_, err = vol.NodeAttach(context.Background(), &NodeAttachOptions{SdcID: testNodeSdcID})
+	assert.EqualError(t, err, "NodeAttach: rescan: Request to rescan failed, RC=3")
+
+	_, err = vol.NodeAttach(context.Background(), &NodeAttachOptions{})
+	assert.Equal(t, errNoSdcID, err)
+
+	// the wait ends with the context
+	driver.SetRC(SDCOpRescan, rcSuccess)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	_, err = vol.NodeAttach(ctx, &NodeAttachOptions{
+		SdcID:        testNodeSdcID,
+		Timeout:      time.Minute,
+		PollInterval: 10 * time.Millisecond,
+	})
+	assert.EqualError(t, err, fmt.Sprintf("NodeAttach: volume %s: context canceled", testNodeVolumeID))
+}
+
+func Test_NodeAttachUnmapsOnFailure(t *testing.T) {
+	_, driver := setupFakeDev(t)
+
+	var paths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		switch r.URL.Path {
+		case fmt.Sprintf("/api/instances/Volume::%s/action/addMappedSdc", testNodeVolumeID),
+			fmt.Sprintf("/api/instances/Volume::%s/action/removeMappedSdc", testNodeVolumeID):
+		default:
+			t.Errorf("unexpected path: %q", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client, err := NewClientWithArgs(ts.URL, "", true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vol := NewVolume(client)
+	vol.Volume = &types.Volume{ID: testNodeVolumeID}
+
+	// a volume mapped by NodeAttach is unmapped when the rescan fails
+	driver.SetRC(SDCOpRescan, 3)
+	_, err = vol.NodeAttach(context.Background(), &NodeAttachOptions{SdcID: testNodeSdcID})
+	assert.EqualError(t, err, "NodeAttach: rescan: Request to rescan failed, RC=3")
+	assert.False(t, isMappedToSdc(vol.Volume, testNodeSdcID))
+
+	// and when the device does not appear
+	driver.SetRC(SDCOpRescan, rcSuccess)
+	_, err = vol.NodeAttach(context.Background(), &NodeAttachOptions{
+		SdcID:        testNodeSdcID,
+		Timeout:      30 * time.Millisecond,
+		PollInterval: 10 * time.Millisecond,
+	})
+	assert.EqualError(t, err, fmt.Sprintf(
+		"NodeAttach: device for volume %s did not appear within 30ms", testNodeVolumeID))
+	assert.False(t, isMappedToSdc(vol.Volume, testNodeSdcID))
+
+	assert.Equal(t, []string{
+		fmt.Sprintf("/api/instances/Volume::%s/action/addMappedSdc", testNodeVolumeID),
+		fmt.Sprintf("/api/instances/Volume::%s/action/removeMappedSdc", testNodeVolumeID),
+		fmt.Sprintf("/api/instances/Volume::%s/action/addMappedSdc", testNodeVolumeID),
+		fmt.Sprintf("/api/instances/Volume::%s/action/removeMappedSdc", testNodeVolumeID),
+	}, paths)
+}
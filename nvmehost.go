@@ -0,0 +1,273 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goscaleio
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	types "github.com/AnshumanPradipPatil1506/goscaleio/types/v1"
+)
+
+const (
+	// NvmeHostType is the host type of NVMe over TCP hosts
+	NvmeHostType = "NVMeHost"
+
+	// nvmeMinVersion is the first API version with NVMe over TCP hosts
+	nvmeMinVersion = "4.0"
+)
+
+// NVMe hosts are listed, renamed and removed through the Sdc resource the
+// gateway keeps for every host, and created and limited through Host.
+
+// GetAllNvmeHosts returns the NVMe over TCP hosts of the system
+func (s *System) GetAllNvmeHosts() (_ []types.NvmeHost, err error) {
+	c, end := s.client.trace("System.GetAllNvmeHosts")
+	defer end(&err)
+
+	if err := c.checkMinVersion("NVMe hosts", nvmeMinVersion); err != nil {
+		return nil, err
+	}
+
+	path := fmt.Sprintf("/api/instances/System::%v/relationships/Sdc",
+		s.System.ID)
+
+	var hosts []types.NvmeHost
+	err = c.getJSONWithRetry(
+		http.MethodGet, path, nil, &hosts)
+	if err != nil {
+		return nil, err
+	}
+
+	nvmeHosts := make([]types.NvmeHost, 0, len(hosts))
+	for _, h := range hosts {
+		if h.HostType == NvmeHostType {
+			nvmeHosts = append(nvmeHosts, h)
+		}
+	}
+
+	return nvmeHosts, nil
+}
+
+// GetNvmeHostByID returns the NVMe over TCP host with the given ID
+func (s *System) GetNvmeHostByID(id string) (_ *types.NvmeHost, err error) {
+	c, end := s.client.trace("System.GetNvmeHostByID")
+	defer end(&err)
+
+	if err := c.checkMinVersion("NVMe hosts", nvmeMinVersion); err != nil {
+		return nil, err
+	}
+
+	path := fmt.Sprintf("/api/instances/Sdc::%v", id)
+
+	var host types.NvmeHost
+	err = c.getJSONWithRetry(
+		http.MethodGet, path, nil, &host)
+	if err != nil {
+		return nil, err
+	}
+	if host.HostType != NvmeHostType {
+		return nil, fmt.Errorf("host %s is not an NVMe host", id)
+	}
+
+	return &host, nil
+}
+
+// CreateNvmeHost creates an NVMe over TCP host identified by its NQN and
+// returns its ID
+func (s *System) CreateNvmeHost(hostParam *types.NvmeHostParam) (_ string, err error) {
+	c, end := s.client.trace("System.CreateNvmeHost")
+	defer end(&err)
+
+	c.audit("Host", "")
+
+	if err := c.checkMinVersion("NVMe hosts", nvmeMinVersion); err != nil {
+		return "", err
+	}
+	if err := c.checkCapability(OpHostAdmin); err != nil {
+		return "", err
+	}
+
+	if hostParam.Nqn == "" {
+		return "", errors.New("an NQN is required to create an NVMe host")
+	}
+
+	path := "/api/types/Host/instances"
+
+	var resp types.NvmeHostResp
+	err = c.getJSONWithRetry(
+		http.MethodPost, path, hostParam, &resp)
+	if err != nil {
+		return "", err
+	}
+
+	return resp.ID, nil
+}
+
+// ChangeNvmeHostName renames the NVMe over TCP host with the given ID
+func (s *System) ChangeNvmeHostName(id, name string) (err error) {
+	c, end := s.client.trace("System.ChangeNvmeHostName")
+	defer end(&err)
+
+	c.audit("Host", id)
+
+	if err := c.checkMinVersion("NVMe hosts", nvmeMinVersion); err != nil {
+		return err
+	}
+	if err := c.checkCapability(OpHostAdmin); err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/api/instances/Sdc::%v/action/setSdcName", id)
+
+	body := types.ChangeSdcNameParam{
+		SdcName: name,
+	}
+	return c.getJSONWithRetry(
+		http.MethodPost, path, body, nil)
+}
+
+// ChangeNvmeHostMaxNumPaths sets the maximum number of paths the NVMe over
+// TCP host with the given ID may use
+func (s *System) ChangeNvmeHostMaxNumPaths(id string, maxNumPaths int) (err error) {
+	c, end := s.client.trace("System.ChangeNvmeHostMaxNumPaths")
+	defer end(&err)
+
+	c.audit("Host", id)
+
+	if err := c.checkMinVersion("NVMe hosts", nvmeMinVersion); err != nil {
+		return err
+	}
+	if err := c.checkCapability(OpHostAdmin); err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/api/instances/Host::%v/action/modifyMaxNumPaths", id)
+
+	body := types.SetNvmeHostMaxNumPathsParam{
+		MaxNumPaths: fmt.Sprint(maxNumPaths),
+	}
+	return c.getJSONWithRetry(
+		http.MethodPost, path, body, nil)
+}
+
+// ChangeNvmeHostMaxNumSysPorts sets the maximum number of system ports the
+// NVMe over TCP host with the given ID may connect to
+func (s *System) ChangeNvmeHostMaxNumSysPorts(id string, maxNumSysPorts int) (err error) {
+	c, end := s.client.trace("System.ChangeNvmeHostMaxNumSysPorts")
+	defer end(&err)
+
+	c.audit("Host", id)
+
+	if err := c.checkMinVersion("NVMe hosts", nvmeMinVersion); err != nil {
+		return err
+	}
+	if err := c.checkCapability(OpHostAdmin); err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/api/instances/Host::%v/action/modifyMaxNumSysPorts", id)
+
+	body := types.SetNvmeHostMaxNumSysPortsParam{
+		MaxNumSysPorts: fmt.Sprint(maxNumSysPorts),
+	}
+	return c.getJSONWithRetry(
+		http.MethodPost, path, body, nil)
+}
+
+// RemoveNvmeHost removes the NVMe over TCP host with the given ID
+func (s *System) RemoveNvmeHost(id string) (err error) {
+	c, end := s.client.trace("System.RemoveNvmeHost")
+	defer end(&err)
+
+	c.audit("Host", id)
+
+	if err := c.checkMinVersion("NVMe hosts", nvmeMinVersion); err != nil {
+		return err
+	}
+	if err := c.checkCapability(OpHostAdmin); err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/api/instances/Sdc::%v/action/removeSdc", id)
+
+	return c.getJSONWithRetry(
+		http.MethodPost, path, types.EmptyPayload{}, nil)
+}
+
+// GetNvmeHostVolumes returns the volumes mapped to the NVMe over TCP host
+// with the given ID
+func (s *System) GetNvmeHostVolumes(id string) (_ []*types.Volume, err error) {
+	c, end := s.client.trace("System.GetNvmeHostVolumes")
+	defer end(&err)
+
+	if err := c.checkMinVersion("NVMe hosts", nvmeMinVersion); err != nil {
+		return nil, err
+	}
+
+	path := fmt.Sprintf("/api/instances/Sdc::%v/relationships/Volume", id)
+
+	var vols []*types.Volume
+	err = c.getJSONWithRetry(
+		http.MethodGet, path, nil, &vols)
+	if err != nil {
+		return nil, err
+	}
+
+	return vols, nil
+}
+
+// MapVolumeNvmeHost maps a volume to an NVMe over TCP host
+func (v *Volume) MapVolumeNvmeHost(
+	mapParam *types.MapVolumeNvmeHostParam) (err error) {
+	c, end := v.client.trace("Volume.MapVolumeNvmeHost")
+	defer end(&err)
+
+	c.audit("Volume", v.Volume.ID, mapParam.HostID)
+
+	if err := c.checkMinVersion("NVMe host mapping", nvmeMinVersion); err != nil {
+		return err
+	}
+	if err := c.checkCapability(OpVolumeMap); err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/api/instances/Volume::%s/action/addMappedHost",
+		v.Volume.ID)
+
+	return c.getJSONWithRetry(
+		http.MethodPost, path, mapParam, nil)
+}
+
+// UnmapVolumeNvmeHost unmaps a volume from an NVMe over TCP host
+func (v *Volume) UnmapVolumeNvmeHost(
+	unmapParam *types.UnmapVolumeNvmeHostParam) (err error) {
+	c, end := v.client.trace("Volume.UnmapVolumeNvmeHost")
+	defer end(&err)
+
+	c.audit("Volume", v.Volume.ID, unmapParam.HostID)
+
+	if err := c.checkMinVersion("NVMe host mapping", nvmeMinVersion); err != nil {
+		return err
+	}
+	if err := c.checkCapability(OpVolumeMap); err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/api/instances/Volume::%s/action/removeMappedHost",
+		v.Volume.ID)
+
+	return c.getJSONWithRetry(
+		http.MethodPost, path, unmapParam, nil)
+}
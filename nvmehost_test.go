@@ -0,0 +1,174 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goscaleio
+
+import (
+	"testing"
+
+	types "github.com/AnshumanPradipPatil1506/goscaleio/types/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NvmeHostManagement(t *testing.T) {
+	const nqn = "nqn.2014-08.org.nvmexpress:uuid:1234"
+	responses := map[string]interface{}{
+		"GET /api/instances/System::sys1/relationships/Sdc": []types.NvmeHost{
+			{ID: "sdc1", Name: "sdc", HostType: "SdcHost"},
+			{ID: "h1", Name: "nvme", HostType: NvmeHostType, Nqn: nqn},
+		},
+		"GET /api/instances/Sdc::h1":                               types.NvmeHost{ID: "h1", HostType: NvmeHostType, Nqn: nqn},
+		"GET /api/instances/Sdc::h1/relationships/Volume":          []types.Volume{{ID: "v1"}},
+		"POST /api/types/Host/instances":                           types.NvmeHostResp{ID: "h1"},
+		"POST /api/instances/Sdc::h1/action/setSdcName":            nil,
+		"POST /api/instances/Host::h1/action/modifyMaxNumPaths":    nil,
+		"POST /api/instances/Host::h1/action/modifyMaxNumSysPorts": nil,
+		"POST /api/instances/Sdc::h1/action/removeSdc":             nil,
+		"POST /api/instances/Volume::v1/action/addMappedHost":      nil,
+		"POST /api/instances/Volume::v1/action/removeMappedHost":   nil,
+	}
+
+	tests := map[string]struct {
+		call     func(t *testing.T, system *System, vol *Volume) error
+		requests []string
+	}{
+		"create": {
+			call: func(t *testing.T, system *System, vol *Volume) error {
+				id, err := system.CreateNvmeHost(&types.NvmeHostParam{Name: "nvme", Nqn: nqn})
+				if err == nil {
+					assert.Equal(t, "h1", id)
+				}
+				return err
+			},
+			requests: []string{
+				`POST /api/types/Host/instances {"name":"nvme","nqn":"` + nqn + `"}`,
+			},
+		},
+		"list": {
+			call: func(t *testing.T, system *System, vol *Volume) error {
+				hosts, err := system.GetAllNvmeHosts()
+				if err == nil && assert.Len(t, hosts, 1) {
+					assert.Equal(t, nqn, hosts[0].Nqn)
+				}
+				return err
+			},
+			requests: []string{"GET /api/instances/System::sys1/relationships/Sdc"},
+		},
+		"get by ID": {
+			call: func(t *testing.T, system *System, vol *Volume) error {
+				host, err := system.GetNvmeHostByID("h1")
+				if err == nil {
+					assert.Equal(t, "h1", host.ID)
+				}
+				return err
+			},
+			requests: []string{"GET /api/instances/Sdc::h1"},
+		},
+		"rename": {
+			call: func(t *testing.T, system *System, vol *Volume) error {
+				return system.ChangeNvmeHostName("h1", "nvme2")
+			},
+			requests: []string{`POST /api/instances/Sdc::h1/action/setSdcName {"sdcName":"nvme2"}`},
+		},
+		"set max paths": {
+			call: func(t *testing.T, system *System, vol *Volume) error {
+				return system.ChangeNvmeHostMaxNumPaths("h1", 4)
+			},
+			requests: []string{`POST /api/instances/Host::h1/action/modifyMaxNumPaths {"maxNumPaths":"4"}`},
+		},
+		"set max system ports": {
+			call: func(t *testing.T, system *System, vol *Volume) error {
+				return system.ChangeNvmeHostMaxNumSysPorts("h1", 10)
+			},
+			requests: []string{`POST /api/instances/Host::h1/action/modifyMaxNumSysPorts {"maxNumSysPorts":"10"}`},
+		},
+		"map volume": {
+			call: func(t *testing.T, system *System, vol *Volume) error {
+				return vol.MapVolumeNvmeHost(&types.MapVolumeNvmeHostParam{HostID: "h1"})
+			},
+			requests: []string{`POST /api/instances/Volume::v1/action/addMappedHost {"hostId":"h1"}`},
+		},
+		"mapped volumes": {
+			call: func(t *testing.T, system *System, vol *Volume) error {
+				vols, err := system.GetNvmeHostVolumes("h1")
+				assert.Len(t, vols, 1)
+				return err
+			},
+			requests: []string{"GET /api/instances/Sdc::h1/relationships/Volume"},
+		},
+		"unmap volume": {
+			call: func(t *testing.T, system *System, vol *Volume) error {
+				return vol.UnmapVolumeNvmeHost(&types.UnmapVolumeNvmeHostParam{HostID: "h1"})
+			},
+			requests: []string{`POST /api/instances/Volume::v1/action/removeMappedHost {"hostId":"h1"}`},
+		},
+		"remove": {
+			call: func(t *testing.T, system *System, vol *Volume) error {
+				return system.RemoveNvmeHost("h1")
+			},
+			requests: []string{`POST /api/instances/Sdc::h1/action/removeSdc {}`},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			client, gateway := newTestGateway(t, "4.0", responses)
+			system := NewSystem(client)
+			system.System.ID = "sys1"
+			vol := NewVolume(client)
+			vol.Volume = &types.Volume{ID: "v1"}
+
+			assert.Nil(t, tt.call(t, system, vol))
+			assert.Equal(t, tt.requests, gateway.Requests())
+		})
+	}
+}
+
+func Test_NvmeHostRequiresVersion(t *testing.T) {
+	for _, version := range []string{"3.6", ""} {
+		client, err := NewClientWithArgs("https://gateway.invalid", version, true, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		system := NewSystem(client)
+
+		_, err = system.CreateNvmeHost(&types.NvmeHostParam{Nqn: "nqn.x"})
+		assert.NotNil(t, err, version)
+		_, err = system.GetAllNvmeHosts()
+		assert.NotNil(t, err, version)
+		vol := NewVolume(client)
+		vol.Volume = &types.Volume{ID: "v1"}
+		assert.NotNil(t, vol.MapVolumeNvmeHost(&types.MapVolumeNvmeHostParam{HostID: "h1"}))
+	}
+	updateHeaders("")
+}
+
+func Test_versionLess(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		less  bool
+		isErr bool
+	}{
+		{"3.6", "4.0", true, false},
+		{"4.0", "4.0", false, false},
+		{"4.5", "4.0", false, false},
+		{"10.0", "4.0", false, false},
+		{"3.10", "3.9", false, false},
+		{"", "4.0", false, true},
+		{"four", "4.0", false, true},
+	}
+	for _, tt := range tests {
+		less, err := versionLess(tt.a, tt.b)
+		assert.Equal(t, tt.isErr, err != nil, tt.a)
+		assert.Equal(t, tt.less, less, tt.a)
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package otelgoscaleio reports the spans and measurements of a goscaleio
+// Client to OpenTelemetry.
+//
+//	i, err := otelgoscaleio.New(otel.GetTracerProvider(), otel.GetMeterProvider())
+//	...
+//	client.SetInstrumentation(i)
+//
+// It is a module of its own so the goscaleio module does not depend on
+// OpenTelemetry.
+package otelgoscaleio
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	goscaleio "github.com/AnshumanPradipPatil1506/goscaleio"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope of the tracer and meter
+const ScopeName = "github.com/AnshumanPradipPatil1506/goscaleio"
+
+// Names of the instruments of the request measurements
+const (
+	MetricRequestDuration = "powerflex.client.request.duration"
+	MetricRequestErrors   = "powerflex.client.request.errors"
+)
+
+// Instrumentation implements goscaleio.Instrumentation with an
+// OpenTelemetry tracer and meter
+type Instrumentation struct {
+	tracer   trace.Tracer
+	duration metric.Float64Histogram
+	errors   metric.Int64Counter
+}
+
+// New returns an Instrumentation that starts spans with a tracer of tp and
+// records request latency and errors with a meter of mp
+func New(tp trace.TracerProvider, mp metric.MeterProvider) (*Instrumentation, error) {
+	meter := mp.Meter(ScopeName)
+	duration, err := meter.Float64Histogram(MetricRequestDuration,
+		metric.WithUnit("s"),
+		metric.WithDescription("Duration of the REST requests to the gateway, including retries."))
+	if err != nil {
+		return nil, err
+	}
+	errors, err := meter.Int64Counter(MetricRequestErrors,
+		metric.WithDescription("Number of failed REST requests to the gateway."))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Instrumentation{
+		tracer:   tp.Tracer(ScopeName),
+		duration: duration,
+		errors:   errors,
+	}, nil
+}
+
+// Start implements goscaleio.Instrumentation. HTTP request spans are client
+// spans, library call spans are internal.
+func (i *Instrumentation) Start(
+	ctx context.Context, name string, attrs ...goscaleio.Attribute) (context.Context, goscaleio.Span) {
+	kind := trace.SpanKindInternal
+	if name == goscaleio.SpanHTTPRequest {
+		kind = trace.SpanKindClient
+	}
+	ctx, s := i.tracer.Start(ctx, name,
+		trace.WithSpanKind(kind), trace.WithAttributes(keyValues(attrs)...))
+	return ctx, &span{s}
+}
+
+// RecordRequest implements goscaleio.Instrumentation
+func (i *Instrumentation) RecordRequest(
+	ctx context.Context, duration time.Duration, err error, attrs ...goscaleio.Attribute) {
+	opt := metric.WithAttributes(keyValues(attrs)...)
+	i.duration.Record(ctx, duration.Seconds(), opt)
+	if err != nil {
+		i.errors.Add(ctx, 1, opt)
+	}
+}
+
+// span adapts a trace.Span to goscaleio.Span
+type span struct {
+	span trace.Span
+}
+
+func (s *span) SetAttributes(attrs ...goscaleio.Attribute) {
+	s.span.SetAttributes(keyValues(attrs)...)
+}
+
+func (s *span) RecordError(err error) {
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+func (s *span) End() {
+	s.span.End()
+}
+
+// keyValues converts attributes to OpenTelemetry attributes. Values of
+// other types than those set by goscaleio are converted to strings.
+func keyValues(attrs []goscaleio.Attribute) []attribute.KeyValue {
+	kvs := make([]attribute.KeyValue, 0, len(attrs))
+	for _, a := range attrs {
+		switch v := a.Value.(type) {
+		case string:
+			kvs = append(kvs, attribute.String(a.Key, v))
+		case int:
+			kvs = append(kvs, attribute.Int(a.Key, v))
+		case int64:
+			kvs = append(kvs, attribute.Int64(a.Key, v))
+		case bool:
+			kvs = append(kvs, attribute.Bool(a.Key, v))
+		case float64:
+			kvs = append(kvs, attribute.Float64(a.Key, v))
+		default:
+			kvs = append(kvs, attribute.String(a.Key, fmt.Sprint(v)))
+		}
+	}
+	return kvs
+}
@@ -0,0 +1,128 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelgoscaleio
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	goscaleio "github.com/AnshumanPradipPatil1506/goscaleio"
+	types "github.com/AnshumanPradipPatil1506/goscaleio/types/v1"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestInstrumentation(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/instances/Volume::vol1/relationships/Statistics":
+			fmt.Fprint(w, `{}`)
+		case "/api/instances/Volume::vol1/action/removeVolume":
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"message": "volume is mapped", "errorCode": 94}`)
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	spans := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	i, err := New(tp, mp)
+	assert.Nil(t, err)
+
+	client, err := goscaleio.NewClientWithArgs(ts.URL, "3.5", true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetInstrumentation(i)
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "caller")
+	volume := goscaleio.NewVolume(client.WithContext(ctx))
+	volume.Volume = &types.Volume{ID: "vol1", Links: []*types.Link{
+		{Rel: "self", HREF: "/api/instances/Volume::vol1"},
+		{Rel: "/api/Volume/relationship/Statistics", HREF: "/api/instances/Volume::vol1/relationships/Statistics"},
+	}}
+	_, err = volume.GetVolumeStatistics()
+	assert.Nil(t, err)
+	assert.NotNil(t, volume.RemoveVolume("ONLY_ME"))
+	parent.End()
+
+	ended := spans.Ended()
+	assert.Len(t, ended, 5)
+	// the calls are children of the caller and the requests of the calls
+	assert.Equal(t, ended[1].SpanContext().SpanID(), ended[0].Parent().SpanID())
+	assert.Equal(t, parent.SpanContext().SpanID(), ended[1].Parent().SpanID())
+	assert.Equal(t, ended[3].SpanContext().SpanID(), ended[2].Parent().SpanID())
+	assert.Equal(t, parent.SpanContext().SpanID(), ended[3].Parent().SpanID())
+	for _, s := range ended[:4] {
+		assert.Equal(t, parent.SpanContext().TraceID(), s.SpanContext().TraceID())
+	}
+	assert.Equal(t, goscaleio.SpanHTTPRequest, ended[0].Name())
+	assert.Equal(t, trace.SpanKindClient, ended[0].SpanKind())
+	assert.Contains(t, ended[0].Attributes(), attribute.Int(goscaleio.AttributeHTTPStatusCode, http.StatusOK))
+	assert.Equal(t, "Volume.GetVolumeStatistics", ended[1].Name())
+	assert.Equal(t, trace.SpanKindInternal, ended[1].SpanKind())
+	assert.Equal(t, codes.Error, ended[2].Status().Code)
+	assert.Contains(t, ended[2].Attributes(), attribute.Int(goscaleio.AttributeErrorCode, 94))
+	assert.Equal(t, "Volume.RemoveVolume", ended[3].Name())
+	assert.Equal(t, codes.Error, ended[3].Status().Code)
+
+	var rm metricdata.ResourceMetrics
+	assert.Nil(t, reader.Collect(context.Background(), &rm))
+	counts := map[string]uint64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Histogram[float64]:
+				for _, dp := range data.DataPoints {
+					counts[m.Name] += dp.Count
+				}
+			case metricdata.Sum[int64]:
+				for _, dp := range data.DataPoints {
+					counts[m.Name] += uint64(dp.Value)
+				}
+			}
+		}
+	}
+	assert.Equal(t, map[string]uint64{
+		MetricRequestDuration: 2,
+		MetricRequestErrors:   1,
+	}, counts)
+}
+
+func TestKeyValues(t *testing.T) {
+	assert.Equal(t, []attribute.KeyValue{
+		attribute.String("a", "x"),
+		attribute.Int("b", 1),
+		attribute.Bool("c", true),
+		attribute.String("d", "[1 2]"),
+	}, keyValues([]goscaleio.Attribute{
+		{Key: "a", Value: "x"},
+		{Key: "b", Value: 1},
+		{Key: "c", Value: true},
+		{Key: "d", Value: []int{1, 2}},
+	}))
+}
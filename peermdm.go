@@ -0,0 +1,269 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goscaleio
+
+import (
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+
+	types "github.com/AnshumanPradipPatil1506/goscaleio/types/v1"
+)
+
+// couplingRCSuccess is the coupling return code of a connected peer
+const couplingRCSuccess = "SUCCESS"
+
+// PeerMdm defines struct for a replication peer system
+type PeerMdm struct {
+	PeerMdm *types.PeerMdm
+	client  *Client
+}
+
+// NewPeerMdm returns a new PeerMdm
+func NewPeerMdm(client *Client, peer *types.PeerMdm) *PeerMdm {
+	return &PeerMdm{
+		PeerMdm: peer,
+		client:  client,
+	}
+}
+
+// PeerMdmHealth is the connection health of a replication peer
+type PeerMdmHealth struct {
+	// Connected reports whether the MDMs of both systems are coupled
+	Connected bool
+	// CouplingRC is the result of the last coupling attempt
+	CouplingRC string
+	// MembershipState is the peer's membership state
+	MembershipState string
+	// SoftwareVersionInfo is the software version the peer runs
+	SoftwareVersionInfo string
+}
+
+// AddPeerMdm registers a peer system for replication by its system ID and
+// MDM IPs and returns the ID of the new peer. A peer CA certificate given in
+// PeerSystemCertificate is checked to be a PEM certificate and registered
+// with the peer.
+func (s *System) AddPeerMdm(peerParam *types.PeerMdmParam) (_ string, err error) {
+	c, end := s.client.trace("System.AddPeerMdm")
+	defer end(&err)
+
+	c.audit("PeerMdm", "")
+
+	if err := c.checkMinVersion("replication", replicationMinVersion); err != nil {
+		return "", err
+	}
+	if err := c.checkCapability(OpReplicationAdmin); err != nil {
+		return "", err
+	}
+
+	if peerParam.PeerSystemID == "" || len(peerParam.PeerSystemIps) == 0 {
+		return "", errors.New("a peer system ID and at least one MDM IP are required")
+	}
+	if peerParam.PeerSystemCertificate != "" {
+		if err := checkPEMCertificate(peerParam.PeerSystemCertificate); err != nil {
+			return "", err
+		}
+	}
+
+	path := "/api/types/PeerMdm/instances"
+
+	peer := types.PeerMdmResp{}
+	err = c.getJSONWithRetry(
+		http.MethodPost, path, peerParam, &peer)
+	if err != nil {
+		return "", err
+	}
+
+	return peer.ID, nil
+}
+
+// checkPEMCertificate returns an error if cert is not a PEM encoded
+// certificate
+func checkPEMCertificate(cert string) error {
+	block, _ := pem.Decode([]byte(cert))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return errors.New("the peer system certificate is not a PEM encoded certificate")
+	}
+	return nil
+}
+
+// GetPeerMdms returns the replication peers of the system
+func (s *System) GetPeerMdms() (_ []*types.PeerMdm, err error) {
+	c, end := s.client.trace("System.GetPeerMdms")
+	defer end(&err)
+
+	if err := c.checkMinVersion("replication", replicationMinVersion); err != nil {
+		return nil, err
+	}
+
+	path := "/api/types/PeerMdm/instances"
+
+	var peers []*types.PeerMdm
+	err = c.getJSONWithRetry(
+		http.MethodGet, path, nil, &peers)
+	if err != nil {
+		return nil, err
+	}
+
+	return peers, nil
+}
+
+// FindPeerMdm returns the first replication peer matching all of the
+// supplied options
+func (s *System) FindPeerMdm(opts ...FindOption) (_ *PeerMdm, err error) {
+	c, end := s.client.trace("System.FindPeerMdm")
+	defer end(&err)
+
+	peers, err := s.withClient(c).FindAllPeerMdm(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return peers[0], nil
+}
+
+// FindAllPeerMdm returns every replication peer matching all of the
+// supplied options
+func (s *System) FindAllPeerMdm(opts ...FindOption) (_ []*PeerMdm, err error) {
+	c, end := s.client.trace("System.FindAllPeerMdm")
+	defer end(&err)
+
+	if err := peerMdmFinder.validate(opts); err != nil {
+		return nil, err
+	}
+
+	peers, err := s.GetPeerMdms()
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]types.PeerMdm, len(peers))
+	for i, peer := range peers {
+		if peer != nil {
+			items[i] = *peer
+		}
+	}
+	matches := peerMdmFinder.match(items, opts)
+	if len(matches) == 0 {
+		return nil, errors.New("Couldn't find PeerMdm")
+	}
+
+	found := make([]*PeerMdm, 0, len(matches))
+	for _, i := range matches {
+		found = append(found, NewPeerMdm(c.base(), &items[i]))
+	}
+
+	return found, nil
+}
+
+// SetIPs replaces the MDM IPs the peer is reached on
+func (p *PeerMdm) SetIPs(ips []string) (err error) {
+	c, end := p.client.trace("PeerMdm.SetIPs")
+	defer end(&err)
+
+	if len(ips) == 0 {
+		return errors.New("at least one peer MDM IP is required")
+	}
+	return p.action(c, "modifyPeerMdmIp", &types.ModifyPeerMdmIPParam{NewPeerMdmIps: ips})
+}
+
+// SetPort changes the port the peer is reached on
+func (p *PeerMdm) SetPort(port int) (err error) {
+	c, end := p.client.trace("PeerMdm.SetPort")
+	defer end(&err)
+
+	return p.action(c, "modifyPeerMdmPort",
+		&types.ModifyPeerMdmPortParam{NewPort: fmt.Sprint(port)})
+}
+
+// SetName renames the peer
+func (p *PeerMdm) SetName(name string) (err error) {
+	c, end := p.client.trace("PeerMdm.SetName")
+	defer end(&err)
+
+	return p.action(c, "modifyPeerMdmName", &types.ModifyPeerMdmNameParam{NewName: name})
+}
+
+// Pause stops communication with the peer
+func (p *PeerMdm) Pause() (err error) {
+	c, end := p.client.trace("PeerMdm.Pause")
+	defer end(&err)
+
+	return p.action(c, "pausePeerMdm", &types.EmptyPayload{})
+}
+
+// Resume restarts communication with a paused peer
+func (p *PeerMdm) Resume() (err error) {
+	c, end := p.client.trace("PeerMdm.Resume")
+	defer end(&err)
+
+	return p.action(c, "resumePeerMdm", &types.EmptyPayload{})
+}
+
+// Remove unregisters the peer. No replication consistency group may use it.
+func (p *PeerMdm) Remove() (err error) {
+	c, end := p.client.trace("PeerMdm.Remove")
+	defer end(&err)
+
+	return p.action(c, "removePeerMdm", &types.EmptyPayload{})
+}
+
+// GetHealth reloads the peer and returns its connection health
+func (p *PeerMdm) GetHealth() (_ *PeerMdmHealth, err error) {
+	c, end := p.client.trace("PeerMdm.GetHealth")
+	defer end(&err)
+
+	link, err := GetLink(p.PeerMdm.Links, "self")
+	if err != nil {
+		return nil, err
+	}
+
+	peer := &types.PeerMdm{}
+	err = c.getJSONWithRetry(
+		http.MethodGet, link.HREF, nil, peer)
+	if err != nil {
+		return nil, err
+	}
+	p.PeerMdm = peer
+
+	return &PeerMdmHealth{
+		Connected:           peer.CouplingRC == couplingRCSuccess,
+		CouplingRC:          peer.CouplingRC,
+		MembershipState:     peer.MembershipState,
+		SoftwareVersionInfo: peer.SoftwareVersionInfo,
+	}, nil
+}
+
+// action posts an action to the peer's self link with c, the client of
+// the calling method
+func (p *PeerMdm) action(c *Client, name string, body interface{}) error {
+	c.audit("PeerMdm", p.PeerMdm.ID)
+
+	if err := c.checkMinVersion("replication", replicationMinVersion); err != nil {
+		return err
+	}
+	if err := c.checkCapability(OpReplicationAdmin); err != nil {
+		return err
+	}
+
+	link, err := GetLink(p.PeerMdm.Links, "self")
+	if err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("%v/action/%s", link.HREF, name)
+
+	return c.getJSONWithRetry(
+		http.MethodPost, path, body, nil)
+}
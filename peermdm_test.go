@@ -0,0 +1,211 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goscaleio
+
+import (
+	"testing"
+
+	types "github.com/AnshumanPradipPatil1506/goscaleio/types/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PeerMdmManagement(t *testing.T) {
+	const (
+		list   = "GET /api/types/PeerMdm/instances"
+		get    = "GET /api/instances/PeerMdm::peer1"
+		prefix = "POST /api/instances/PeerMdm::peer1/action/"
+		cert   = "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n"
+	)
+	responsesFor := func(couplingRC string) map[string]interface{} {
+		peer := types.PeerMdm{
+			ID:           "peer1",
+			Name:         "dr-site",
+			PeerSystemID: "sys2",
+			CouplingRC:   couplingRC,
+			Links:        []*types.Link{{Rel: "self", HREF: "/api/instances/PeerMdm::peer1"}},
+		}
+		responses := map[string]interface{}{
+			"POST /api/types/PeerMdm/instances": types.PeerMdmResp{ID: "peer1"},
+			list:                                []types.PeerMdm{peer},
+			get:                                 peer,
+		}
+		for _, action := range []string{
+			"modifyPeerMdmIp", "modifyPeerMdmPort", "modifyPeerMdmName",
+			"pausePeerMdm", "resumePeerMdm", "removePeerMdm",
+		} {
+			responses[prefix+action] = nil
+		}
+		return responses
+	}
+
+	tests := map[string]struct {
+		couplingRC string
+		call       func(t *testing.T, system *System, p *PeerMdm) error
+		isErr      bool
+		requests   []string
+	}{
+		"add": {
+			call: func(t *testing.T, system *System, p *PeerMdm) error {
+				id, err := system.AddPeerMdm(&types.PeerMdmParam{
+					Name:                  "dr-site",
+					PeerSystemID:          "sys2",
+					PeerSystemIps:         []string{"10.1.0.1", "10.1.0.2"},
+					Port:                  "7611",
+					PeerSystemCertificate: cert,
+				})
+				if err == nil {
+					assert.Equal(t, "peer1", id)
+				}
+				return err
+			},
+			requests: []string{
+				`POST /api/types/PeerMdm/instances {"name":"dr-site","peerSystemId":"sys2","peerSystemIps":["10.1.0.1","10.1.0.2"],"port":"7611",` +
+					`"peerSystemCertificate":"-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n"}`,
+			},
+		},
+		"add without IPs": {
+			call: func(t *testing.T, system *System, p *PeerMdm) error {
+				_, err := system.AddPeerMdm(&types.PeerMdmParam{PeerSystemID: "sys2"})
+				return err
+			},
+			isErr: true,
+		},
+		// a malformed certificate is not sent
+		"add with malformed certificate": {
+			call: func(t *testing.T, system *System, p *PeerMdm) error {
+				_, err := system.AddPeerMdm(&types.PeerMdmParam{
+					PeerSystemID:          "sys2",
+					PeerSystemIps:         []string{"10.1.0.1"},
+					PeerSystemCertificate: "not a certificate",
+				})
+				assert.EqualError(t, err, "the peer system certificate is not a PEM encoded certificate")
+				return err
+			},
+			isErr: true,
+		},
+		"find by peer system": {
+			call: func(t *testing.T, system *System, p *PeerMdm) error {
+				found, err := system.FindPeerMdm(ByPeerSystemID("sys2"))
+				if err == nil {
+					assert.Equal(t, "peer1", found.PeerMdm.ID)
+				}
+				return err
+			},
+			requests: []string{list},
+		},
+		"find unknown name": {
+			call: func(t *testing.T, system *System, p *PeerMdm) error {
+				_, err := system.FindPeerMdm(ByName("other"))
+				return err
+			},
+			isErr:    true,
+			requests: []string{list},
+		},
+		"find by empty peer system": {
+			call: func(t *testing.T, system *System, p *PeerMdm) error {
+				_, err := system.FindPeerMdm(ByPeerSystemID(""))
+				assert.EqualError(t, err, "invalid PeerSystemID lookup: value must not be empty")
+				return err
+			},
+			isErr: true,
+		},
+		"find by SDS": {
+			call: func(t *testing.T, system *System, p *PeerMdm) error {
+				_, err := system.FindPeerMdm(BySds("sds1"))
+				assert.EqualError(t, err, "Sds lookup is not supported for PeerMdm")
+				return err
+			},
+			isErr: true,
+		},
+		"set no IPs": {
+			call: func(t *testing.T, system *System, p *PeerMdm) error {
+				return p.SetIPs(nil)
+			},
+			isErr: true,
+		},
+		"set IPs": {
+			call: func(t *testing.T, system *System, p *PeerMdm) error {
+				return p.SetIPs([]string{"10.1.0.3"})
+			},
+			requests: []string{prefix + `modifyPeerMdmIp {"newPeerMDMIps":["10.1.0.3"]}`},
+		},
+		"set port": {
+			call: func(t *testing.T, system *System, p *PeerMdm) error {
+				return p.SetPort(7612)
+			},
+			requests: []string{prefix + `modifyPeerMdmPort {"newPort":"7612"}`},
+		},
+		"set name": {
+			call: func(t *testing.T, system *System, p *PeerMdm) error {
+				return p.SetName("dr")
+			},
+			requests: []string{prefix + `modifyPeerMdmName {"newName":"dr"}`},
+		},
+		"pause": {
+			call: func(t *testing.T, system *System, p *PeerMdm) error {
+				return p.Pause()
+			},
+			requests: []string{prefix + `pausePeerMdm {}`},
+		},
+		"resume": {
+			call: func(t *testing.T, system *System, p *PeerMdm) error {
+				return p.Resume()
+			},
+			requests: []string{prefix + `resumePeerMdm {}`},
+		},
+		"health": {
+			couplingRC: "SUCCESS",
+			call: func(t *testing.T, system *System, p *PeerMdm) error {
+				health, err := p.GetHealth()
+				if err == nil {
+					assert.True(t, health.Connected)
+				}
+				return err
+			},
+			requests: []string{get},
+		},
+		"health of a disconnected peer": {
+			couplingRC: "PEER_MDM_NOT_CONNECTED",
+			call: func(t *testing.T, system *System, p *PeerMdm) error {
+				health, err := p.GetHealth()
+				if err == nil {
+					assert.False(t, health.Connected)
+					assert.Equal(t, "PEER_MDM_NOT_CONNECTED", health.CouplingRC)
+				}
+				return err
+			},
+			requests: []string{get},
+		},
+		"remove": {
+			call: func(t *testing.T, system *System, p *PeerMdm) error {
+				return p.Remove()
+			},
+			requests: []string{prefix + `removePeerMdm {}`},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			client, gateway := newTestGateway(t, "3.5", responsesFor(tt.couplingRC))
+			system := NewSystem(client)
+			p := NewPeerMdm(client, &types.PeerMdm{
+				ID:    "peer1",
+				Links: []*types.Link{{Rel: "self", HREF: "/api/instances/PeerMdm::peer1"}},
+			})
+
+			err := tt.call(t, system, p)
+			assert.Equal(t, tt.isErr, err != nil, "error: %v", err)
+			assert.Equal(t, tt.requests, gateway.Requests())
+		})
+	}
+}
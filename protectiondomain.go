@@ -16,7 +16,6 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"time"
 
 	types "github.com/AnshumanPradipPatil1506/goscaleio/types/v1"
 )
@@ -43,9 +42,22 @@ func NewProtectionDomainEx(client *Client, pd *types.ProtectionDomain) *Protecti
 	}
 }
 
+// withClient returns a copy of the protection domain that makes its requests with c,
+// the client of a call that calls other methods of the protection domain
+func (pd *ProtectionDomain) withClient(c *Client) *ProtectionDomain {
+	return &ProtectionDomain{ProtectionDomain: pd.ProtectionDomain, client: c}
+}
+
 // CreateProtectionDomain creates a ProtectionDomain
-func (s *System) CreateProtectionDomain(name string) (string, error) {
-	defer TimeSpent("CreateProtectionDomain", time.Now())
+func (s *System) CreateProtectionDomain(name string) (_ string, err error) {
+	c, end := s.client.trace("System.CreateProtectionDomain")
+	defer end(&err)
+
+	c.audit("ProtectionDomain", "")
+
+	if err := c.checkCapability(OpStorageAdmin); err != nil {
+		return "", err
+	}
 
 	protectionDomainParam := &types.ProtectionDomainParam{
 		Name: name,
@@ -54,7 +66,7 @@ func (s *System) CreateProtectionDomain(name string) (string, error) {
 	path := fmt.Sprintf("/api/types/ProtectionDomain/instances")
 
 	pd := types.ProtectionDomainResp{}
-	err := s.client.getJSONWithRetry(
+	err = c.getJSONWithRetry(
 		http.MethodPost, path, protectionDomainParam, &pd)
 	if err != nil {
 		return "", err
@@ -64,12 +76,20 @@ func (s *System) CreateProtectionDomain(name string) (string, error) {
 }
 
 // DeleteProtectionDomain will delete a protection domain
-func (s *System) DeleteProtectionDomain(name string) error {
+func (s *System) DeleteProtectionDomain(name string) (err error) {
+	c, end := s.client.trace("System.DeleteProtectionDomain")
+	defer end(&err)
+
+	if err := c.checkCapability(OpStorageAdmin); err != nil {
+		return err
+	}
+
 	// get the protection domain
-	domain, err := s.FindProtectionDomain("", name, "")
+	domain, err := s.withClient(c).FindProtectionDomain("", name, "")
 	if err != nil {
 		return err
 	}
+	c.audit("ProtectionDomain", domain.ID)
 
 	link, err := GetLink(domain.Links, "self")
 	if err != nil {
@@ -80,7 +100,7 @@ func (s *System) DeleteProtectionDomain(name string) error {
 
 	path := fmt.Sprintf("%v/action/removeProtectionDomain", link.HREF)
 
-	err = s.client.getJSONWithRetry(
+	err = c.getJSONWithRetry(
 		http.MethodPost, path, protectionDomainParam, nil)
 	if err != nil {
 		return err
@@ -91,11 +111,11 @@ func (s *System) DeleteProtectionDomain(name string) error {
 
 // GetProtectionDomain returns a ProtectionDomain
 func (s *System) GetProtectionDomain(
-	pdhref string) ([]*types.ProtectionDomain, error) {
-	defer TimeSpent("GetprotectionDomain", time.Now())
+	pdhref string) (_ []*types.ProtectionDomain, err error) {
+	c, end := s.client.trace("System.GetProtectionDomain")
+	defer end(&err)
 
 	var (
-		err error
 		pd  = &types.ProtectionDomain{}
 		pds []*types.ProtectionDomain
 	)
@@ -109,10 +129,10 @@ func (s *System) GetProtectionDomain(
 			return nil, err
 		}
 
-		err = s.client.getJSONWithRetry(
+		err = c.getJSONWithRetry(
 			http.MethodGet, link.HREF, nil, &pds)
 	} else {
-		err = s.client.getJSONWithRetry(
+		err = c.getJSONWithRetry(
 			http.MethodGet, pdhref, nil, pd)
 	}
 	if err != nil {
@@ -127,10 +147,11 @@ func (s *System) GetProtectionDomain(
 
 // FindProtectionDomain returns a ProtectionDomain
 func (s *System) FindProtectionDomain(
-	id, name, href string) (*types.ProtectionDomain, error) {
-	defer TimeSpent("FindProtectionDomain", time.Now())
+	id, name, href string) (_ *types.ProtectionDomain, err error) {
+	c, end := s.client.trace("System.FindProtectionDomain")
+	defer end(&err)
 
-	pds, err := s.GetProtectionDomain(href)
+	pds, err := s.withClient(c).GetProtectionDomain(href)
 	if err != nil {
 		return nil, fmt.Errorf("Error getting protection domains %s", err)
 	}
@@ -0,0 +1,318 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goscaleio
+
+import (
+	"fmt"
+	"net/http"
+
+	types "github.com/AnshumanPradipPatil1506/goscaleio/types/v1"
+)
+
+// replicationMinVersion is the first API version with native replication
+const replicationMinVersion = "3.5"
+
+// Pause modes of a replication consistency group
+const (
+	// PauseModeStopDataTransfer stops sending data to the target
+	PauseModeStopDataTransfer = "StopDataTransfer"
+	// PauseModeOnlyTrackChanges keeps tracking changes without sending them
+	PauseModeOnlyTrackChanges = "OnlyTrackChanges"
+)
+
+// Copy types of a replication pair
+const (
+	// CopyTypeOnlineCopy copies the source volume to the target over the link
+	CopyTypeOnlineCopy = "OnlineCopy"
+	// CopyTypeOfflineCopy expects the target volume to be seeded out of band
+	CopyTypeOfflineCopy = "OfflineCopy"
+)
+
+// ReplicationConsistencyGroup defines struct for ReplicationConsistencyGroup
+type ReplicationConsistencyGroup struct {
+	ReplicationConsistencyGroup *types.ReplicationConsistencyGroup
+	client                      *Client
+}
+
+// NewReplicationConsistencyGroup returns a new ReplicationConsistencyGroup
+func NewReplicationConsistencyGroup(client *Client) *ReplicationConsistencyGroup {
+	return &ReplicationConsistencyGroup{
+		ReplicationConsistencyGroup: &types.ReplicationConsistencyGroup{},
+		client:                      client,
+	}
+}
+
+// NewReplicationConsistencyGroupEx returns a new ReplicationConsistencyGroupEx
+func NewReplicationConsistencyGroupEx(
+	client *Client, rcg *types.ReplicationConsistencyGroup) *ReplicationConsistencyGroup {
+	return &ReplicationConsistencyGroup{
+		ReplicationConsistencyGroup: rcg,
+		client:                      client,
+	}
+}
+
+// withClient returns a copy of the group that makes its requests with c,
+// the client of a call that calls other methods of the group
+func (rcg *ReplicationConsistencyGroup) withClient(c *Client) *ReplicationConsistencyGroup {
+	return &ReplicationConsistencyGroup{ReplicationConsistencyGroup: rcg.ReplicationConsistencyGroup, client: c}
+}
+
+// CreateReplicationConsistencyGroup creates a replication consistency group
+// between a local and a remote protection domain
+func (s *System) CreateReplicationConsistencyGroup(
+	rcgParam *types.ReplicationConsistencyGroupParam) (_ *types.ReplicationConsistencyGroupResp, err error) {
+	c, end := s.client.trace("System.CreateReplicationConsistencyGroup")
+	defer end(&err)
+
+	c.audit("ReplicationConsistencyGroup", "")
+
+	if err := c.checkMinVersion("replication", replicationMinVersion); err != nil {
+		return nil, err
+	}
+	if err := c.checkCapability(OpReplicationAdmin); err != nil {
+		return nil, err
+	}
+
+	if rcgParam.RpoInSeconds == "" || rcgParam.ProtectionDomainID == "" ||
+		rcgParam.RemoteProtectionDomainID == "" {
+		return nil, fmt.Errorf("RPO, protection domain and remote protection domain are required")
+	}
+
+	path := "/api/types/ReplicationConsistencyGroup/instances"
+
+	rcg := &types.ReplicationConsistencyGroupResp{}
+	err = c.getJSONWithRetry(
+		http.MethodPost, path, rcgParam, rcg)
+	if err != nil {
+		return nil, err
+	}
+
+	return rcg, nil
+}
+
+// GetReplicationConsistencyGroups returns the replication consistency groups
+func (s *System) GetReplicationConsistencyGroups() (_ []*types.ReplicationConsistencyGroup, err error) {
+	c, end := s.client.trace("System.GetReplicationConsistencyGroups")
+	defer end(&err)
+
+	if err := c.checkMinVersion("replication", replicationMinVersion); err != nil {
+		return nil, err
+	}
+
+	path := "/api/types/ReplicationConsistencyGroup/instances"
+
+	var rcgs []*types.ReplicationConsistencyGroup
+	err = c.getJSONWithRetry(
+		http.MethodGet, path, nil, &rcgs)
+	if err != nil {
+		return nil, err
+	}
+
+	return rcgs, nil
+}
+
+// GetReplicationConsistencyGroupByID returns the replication consistency
+// group with the given ID
+func (s *System) GetReplicationConsistencyGroupByID(
+	id string) (_ *ReplicationConsistencyGroup, err error) {
+	c, end := s.client.trace("System.GetReplicationConsistencyGroupByID")
+	defer end(&err)
+
+	rcg := NewReplicationConsistencyGroupEx(c.base(),
+		&types.ReplicationConsistencyGroup{ID: id})
+	call := rcg.withClient(c)
+	if err := call.Refresh(); err != nil {
+		return nil, err
+	}
+	rcg.ReplicationConsistencyGroup = call.ReplicationConsistencyGroup
+
+	return rcg, nil
+}
+
+// Refresh reloads the replication consistency group from the system
+func (rcg *ReplicationConsistencyGroup) Refresh() (err error) {
+	c, end := rcg.client.trace("ReplicationConsistencyGroup.Refresh")
+	defer end(&err)
+
+	if err := c.checkMinVersion("replication", replicationMinVersion); err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/api/instances/ReplicationConsistencyGroup::%v",
+		rcg.ReplicationConsistencyGroup.ID)
+
+	group := &types.ReplicationConsistencyGroup{}
+	err = c.getJSONWithRetry(
+		http.MethodGet, path, nil, group)
+	if err != nil {
+		return err
+	}
+	rcg.ReplicationConsistencyGroup = group
+
+	return nil
+}
+
+// SetRPO sets the recovery point objective of the group
+func (rcg *ReplicationConsistencyGroup) SetRPO(rpoInSeconds int) (err error) {
+	c, end := rcg.client.trace("ReplicationConsistencyGroup.SetRPO")
+	defer end(&err)
+
+	return rcg.action(c, "modifyReplicationConsistencyGroupRpo",
+		&types.SetRpoParam{RpoInSeconds: fmt.Sprint(rpoInSeconds)})
+}
+
+// Activate starts replication of a new or terminated group
+func (rcg *ReplicationConsistencyGroup) Activate() (err error) {
+	c, end := rcg.client.trace("ReplicationConsistencyGroup.Activate")
+	defer end(&err)
+
+	return rcg.action(c, "activateReplicationConsistencyGroup", &types.EmptyPayload{})
+}
+
+// Terminate stops replication of the group, keeping its pairs
+func (rcg *ReplicationConsistencyGroup) Terminate() (err error) {
+	c, end := rcg.client.trace("ReplicationConsistencyGroup.Terminate")
+	defer end(&err)
+
+	return rcg.action(c, "terminateReplicationConsistencyGroup", &types.EmptyPayload{})
+}
+
+// Pause pauses replication of the group in the given mode, one of
+// PauseModeStopDataTransfer or PauseModeOnlyTrackChanges
+func (rcg *ReplicationConsistencyGroup) Pause(pauseMode string) (err error) {
+	c, end := rcg.client.trace("ReplicationConsistencyGroup.Pause")
+	defer end(&err)
+
+	if pauseMode == "" {
+		pauseMode = PauseModeStopDataTransfer
+	}
+	return rcg.action(c, "pauseReplicationConsistencyGroup",
+		&types.PauseReplicationConsistencyGroupParam{PauseMode: pauseMode})
+}
+
+// Resume resumes replication of a paused group
+func (rcg *ReplicationConsistencyGroup) Resume() (err error) {
+	c, end := rcg.client.trace("ReplicationConsistencyGroup.Resume")
+	defer end(&err)
+
+	return rcg.action(c, "resumeReplicationConsistencyGroup", &types.EmptyPayload{})
+}
+
+// Remove removes the group. It must not have any replication pairs.
+func (rcg *ReplicationConsistencyGroup) Remove() (err error) {
+	c, end := rcg.client.trace("ReplicationConsistencyGroup.Remove")
+	defer end(&err)
+
+	return rcg.action(c, "removeReplicationConsistencyGroup", &types.EmptyPayload{})
+}
+
+// CreateReplicationPair adds a pair linking a local source volume to a
+// remote target volume to the group. The copy type defaults to
+// CopyTypeOnlineCopy.
+func (rcg *ReplicationConsistencyGroup) CreateReplicationPair(
+	pairParam *types.ReplicationPairParam) (_ *types.ReplicationPairResp, err error) {
+	c, end := rcg.client.trace("ReplicationConsistencyGroup.CreateReplicationPair")
+	defer end(&err)
+
+	c.audit("ReplicationPair", "")
+
+	if err := c.checkMinVersion("replication", replicationMinVersion); err != nil {
+		return nil, err
+	}
+	if err := c.checkCapability(OpReplicationAdmin); err != nil {
+		return nil, err
+	}
+
+	if pairParam.SourceVolumeID == "" || pairParam.DestinationVolumeID == "" {
+		return nil, fmt.Errorf("source and destination volumes are required")
+	}
+	pairParam.ReplicationConsistencyGroupID = rcg.ReplicationConsistencyGroup.ID
+	if pairParam.CopyType == "" {
+		pairParam.CopyType = CopyTypeOnlineCopy
+	}
+
+	path := "/api/types/ReplicationPair/instances"
+
+	pair := &types.ReplicationPairResp{}
+	err = c.getJSONWithRetry(
+		http.MethodPost, path, pairParam, pair)
+	if err != nil {
+		return nil, err
+	}
+
+	return pair, nil
+}
+
+// GetReplicationPairs returns the replication pairs of the group
+func (rcg *ReplicationConsistencyGroup) GetReplicationPairs() (_ []*types.ReplicationPair, err error) {
+	c, end := rcg.client.trace("ReplicationConsistencyGroup.GetReplicationPairs")
+	defer end(&err)
+
+	if err := c.checkMinVersion("replication", replicationMinVersion); err != nil {
+		return nil, err
+	}
+
+	path := fmt.Sprintf(
+		"/api/instances/ReplicationConsistencyGroup::%v/relationships/ReplicationPair",
+		rcg.ReplicationConsistencyGroup.ID)
+
+	var pairs []*types.ReplicationPair
+	err = c.getJSONWithRetry(
+		http.MethodGet, path, nil, &pairs)
+	if err != nil {
+		return nil, err
+	}
+
+	return pairs, nil
+}
+
+// RemoveReplicationPair removes a replication pair from the group. The
+// volumes of the pair are not removed.
+func (rcg *ReplicationConsistencyGroup) RemoveReplicationPair(pairID string) (err error) {
+	c, end := rcg.client.trace("ReplicationConsistencyGroup.RemoveReplicationPair")
+	defer end(&err)
+
+	c.audit("ReplicationPair", pairID)
+
+	if err := c.checkMinVersion("replication", replicationMinVersion); err != nil {
+		return err
+	}
+	if err := c.checkCapability(OpReplicationAdmin); err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/api/instances/ReplicationPair::%v/action/removeReplicationPair",
+		pairID)
+
+	return c.getJSONWithRetry(
+		http.MethodPost, path, &types.EmptyPayload{}, nil)
+}
+
+// action posts an action to the replication consistency group with c, the
+// client of the calling method
+func (rcg *ReplicationConsistencyGroup) action(c *Client, name string, body interface{}) error {
+	c.audit("ReplicationConsistencyGroup", rcg.ReplicationConsistencyGroup.ID)
+
+	if err := c.checkMinVersion("replication", replicationMinVersion); err != nil {
+		return err
+	}
+	if err := c.checkCapability(OpReplicationAdmin); err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/api/instances/ReplicationConsistencyGroup::%v/action/%s",
+		rcg.ReplicationConsistencyGroup.ID, name)
+
+	return c.getJSONWithRetry(
+		http.MethodPost, path, body, nil)
+}
@@ -0,0 +1,267 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goscaleio
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	types "github.com/AnshumanPradipPatil1506/goscaleio/types/v1"
+)
+
+const (
+	// DefaultReplicationWaitTimeout is how long WaitForStatus waits by default
+	DefaultReplicationWaitTimeout = 5 * time.Minute
+	// DefaultReplicationPollInterval is how often WaitForStatus polls by default
+	DefaultReplicationPollInterval = 5 * time.Second
+)
+
+// ReplicationLinkState summarises whether data flows between the two sides
+// of a replication consistency group
+type ReplicationLinkState string
+
+const (
+	// ReplicationLinkUp means replication is active and not paused
+	ReplicationLinkUp ReplicationLinkState = "Up"
+	// ReplicationLinkPaused means replication was paused
+	ReplicationLinkPaused ReplicationLinkState = "Paused"
+	// ReplicationLinkInactive means one side of the group is not active
+	ReplicationLinkInactive ReplicationLinkState = "Inactive"
+	// ReplicationLinkError means the group reports an error
+	ReplicationLinkError ReplicationLinkState = "Error"
+)
+
+// ReplicationStatus is the replication state of a consistency group
+type ReplicationStatus struct {
+	// ConsistencyMode is the current consistency mode, such as Consistent
+	ConsistencyMode string
+	// LinkState summarises whether data flows between the sides
+	LinkState ReplicationLinkState
+	// Lag is how far the target is behind the source, zero if not known
+	Lag time.Duration
+	// FailoverType is the failover operation in effect, if any
+	FailoverType string
+	// FailoverState is the progress of that operation
+	FailoverState string
+	// DisasterRecoveryState is the disaster recovery state of the local side
+	DisasterRecoveryState string
+	// ReplicationDirection is the direction data is replicated in
+	ReplicationDirection string
+	// PauseMode is the pause mode, if paused
+	PauseMode string
+}
+
+// NewReplicationStatus derives the status of a group from its properties.
+// Lag is not part of them and is left zero.
+func NewReplicationStatus(group *types.ReplicationConsistencyGroup) *ReplicationStatus {
+	status := &ReplicationStatus{
+		ConsistencyMode:       group.CurrConsistMode,
+		FailoverType:          group.FailoverType,
+		FailoverState:         group.FailoverState,
+		DisasterRecoveryState: group.DisasterRecoveryState,
+		ReplicationDirection:  group.ReplicationDirection,
+		PauseMode:             group.PauseMode,
+	}
+
+	switch {
+	case group.Error != 0:
+		status.LinkState = ReplicationLinkError
+	case !group.ActiveLocal || !group.ActiveRemote:
+		status.LinkState = ReplicationLinkInactive
+	case group.PauseMode != "" && group.PauseMode != "None":
+		status.LinkState = ReplicationLinkPaused
+	default:
+		status.LinkState = ReplicationLinkUp
+	}
+
+	return status
+}
+
+// GetStatistics returns the statistics of the group
+func (rcg *ReplicationConsistencyGroup) GetStatistics() (_ *types.ReplicationConsistencyGroupStatistics, err error) {
+	c, end := rcg.client.trace("ReplicationConsistencyGroup.GetStatistics")
+	defer end(&err)
+
+	if err := c.checkMinVersion("replication", replicationMinVersion); err != nil {
+		return nil, err
+	}
+
+	path := fmt.Sprintf(
+		"/api/instances/ReplicationConsistencyGroup::%v/relationships/Statistics",
+		rcg.ReplicationConsistencyGroup.ID)
+
+	var stats types.ReplicationConsistencyGroupStatistics
+	err = c.getJSONWithRetry(
+		http.MethodGet, path, nil, &stats)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
+
+// GetStatus refreshes the group and returns its replication status,
+// including the lag reported by its statistics
+func (rcg *ReplicationConsistencyGroup) GetStatus() (_ *ReplicationStatus, err error) {
+	c, end := rcg.client.trace("ReplicationConsistencyGroup.GetStatus")
+	defer end(&err)
+
+	call := rcg.withClient(c)
+	if err := call.Refresh(); err != nil {
+		return nil, err
+	}
+	rcg.ReplicationConsistencyGroup = call.ReplicationConsistencyGroup
+	status := NewReplicationStatus(rcg.ReplicationConsistencyGroup)
+
+	stats, err := call.GetStatistics()
+	if err != nil {
+		return nil, err
+	}
+	status.Lag = time.Duration(stats.CurrentRpoInSeconds) * time.Second
+
+	return status, nil
+}
+
+// Failover makes the target side of the group available for I/O after the
+// source is lost, without synchronising first
+func (rcg *ReplicationConsistencyGroup) Failover() (err error) {
+	c, end := rcg.client.trace("ReplicationConsistencyGroup.Failover")
+	defer end(&err)
+
+	return rcg.action(c, "failoverReplicationConsistencyGroup", &types.EmptyPayload{})
+}
+
+// Restore resumes replication in the original direction after a failover,
+// discarding writes made on the target
+func (rcg *ReplicationConsistencyGroup) Restore() (err error) {
+	c, end := rcg.client.trace("ReplicationConsistencyGroup.Restore")
+	defer end(&err)
+
+	return rcg.action(c, "restoreReplicationConsistencyGroup", &types.EmptyPayload{})
+}
+
+// Reverse resumes replication from the target back to the source after a
+// failover, keeping writes made on the target
+func (rcg *ReplicationConsistencyGroup) Reverse() (err error) {
+	c, end := rcg.client.trace("ReplicationConsistencyGroup.Reverse")
+	defer end(&err)
+
+	return rcg.action(c, "reverseReplicationConsistencyGroup", &types.EmptyPayload{})
+}
+
+// Switchover synchronises the group and swaps the roles of source and
+// target without data loss
+func (rcg *ReplicationConsistencyGroup) Switchover() (err error) {
+	c, end := rcg.client.trace("ReplicationConsistencyGroup.Switchover")
+	defer end(&err)
+
+	return rcg.action(c, "switchoverReplicationConsistencyGroup", &types.EmptyPayload{})
+}
+
+// TestFailover makes a snapshot of the target available for I/O while
+// replication continues
+func (rcg *ReplicationConsistencyGroup) TestFailover() (err error) {
+	c, end := rcg.client.trace("ReplicationConsistencyGroup.TestFailover")
+	defer end(&err)
+
+	return rcg.action(c, "testFailoverReplicationConsistencyGroup", &types.EmptyPayload{})
+}
+
+// TestFailoverStop ends a test failover and discards its snapshot
+func (rcg *ReplicationConsistencyGroup) TestFailoverStop() (err error) {
+	c, end := rcg.client.trace("ReplicationConsistencyGroup.TestFailoverStop")
+	defer end(&err)
+
+	return rcg.action(c, "testFailoverStopReplicationConsistencyGroup", &types.EmptyPayload{})
+}
+
+// ReplicationWaitOptions defines the options for WaitForStatus
+type ReplicationWaitOptions struct {
+	// Timeout is how long to wait, DefaultReplicationWaitTimeout if zero
+	Timeout time.Duration
+	// PollInterval is how often to poll, DefaultReplicationPollInterval if zero
+	PollInterval time.Duration
+}
+
+// WaitForStatus polls the group until done returns true for its status and
+// returns that status. It gives up with an error, and the last status seen,
+// once the timeout expires or ctx is done.
+func (rcg *ReplicationConsistencyGroup) WaitForStatus(
+	ctx context.Context,
+	done func(*ReplicationStatus) bool,
+	opts *ReplicationWaitOptions) (_ *ReplicationStatus, err error) {
+	c, end := rcg.client.WithContext(ctx).trace("ReplicationConsistencyGroup.WaitForStatus")
+	defer end(&err)
+
+	timeout := DefaultReplicationWaitTimeout
+	interval := DefaultReplicationPollInterval
+	if opts != nil {
+		if opts.Timeout != 0 {
+			timeout = opts.Timeout
+		}
+		if opts.PollInterval != 0 {
+			interval = opts.PollInterval
+		}
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	call := rcg.withClient(c)
+	for {
+		status, err := call.GetStatus()
+		if err != nil {
+			return nil, err
+		}
+		rcg.ReplicationConsistencyGroup = call.ReplicationConsistencyGroup
+		if done(status) {
+			return status, nil
+		}
+		select {
+		case <-ctx.Done():
+			return status, fmt.Errorf(
+				"replication consistency group %s: %v",
+				rcg.ReplicationConsistencyGroup.ID, ctx.Err())
+		case <-timer.C:
+			return status, fmt.Errorf(
+				"replication consistency group %s did not reach the expected state within %v",
+				rcg.ReplicationConsistencyGroup.ID, timeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+// FailoverStateIs returns a WaitForStatus condition matching a failover state
+func FailoverStateIs(state string) func(*ReplicationStatus) bool {
+	return func(s *ReplicationStatus) bool {
+		return s.FailoverState == state
+	}
+}
+
+// ConsistencyModeIs returns a WaitForStatus condition matching a consistency mode
+func ConsistencyModeIs(mode string) func(*ReplicationStatus) bool {
+	return func(s *ReplicationStatus) bool {
+		return s.ConsistencyMode == mode
+	}
+}
+
+// LinkStateIs returns a WaitForStatus condition matching a link state
+func LinkStateIs(state ReplicationLinkState) func(*ReplicationStatus) bool {
+	return func(s *ReplicationStatus) bool {
+		return s.LinkState == state
+	}
+}
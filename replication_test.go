@@ -0,0 +1,321 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goscaleio
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	types "github.com/AnshumanPradipPatil1506/goscaleio/types/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+// replicationResponses answers the replication endpoints, with GETs of the
+// group answered by rcgState
+func replicationResponses(rcgState func() types.ReplicationConsistencyGroup) map[string]interface{} {
+	if rcgState == nil {
+		rcgState = func() types.ReplicationConsistencyGroup {
+			return types.ReplicationConsistencyGroup{ID: "rcg1", Name: "rcg", RpoInSeconds: 60}
+		}
+	}
+	responses := map[string]interface{}{
+		"POST /api/types/ReplicationConsistencyGroup/instances": types.ReplicationConsistencyGroupResp{ID: "rcg1"},
+		"GET /api/types/ReplicationConsistencyGroup/instances": func() interface{} {
+			return []types.ReplicationConsistencyGroup{rcgState()}
+		},
+		"GET /api/instances/ReplicationConsistencyGroup::rcg1": func() interface{} {
+			return rcgState()
+		},
+		"GET /api/instances/ReplicationConsistencyGroup::rcg1/relationships/Statistics": types.ReplicationConsistencyGroupStatistics{
+			CurrentRpoInSeconds: 42,
+		},
+		"POST /api/types/ReplicationPair/instances": types.ReplicationPairResp{ID: "pair1"},
+		"GET /api/instances/ReplicationConsistencyGroup::rcg1/relationships/ReplicationPair": []types.ReplicationPair{
+			{ID: "pair1", LocalVolumeID: "v1", RemoteVolumeID: "rv1"},
+		},
+		"POST /api/instances/ReplicationPair::pair1/action/removeReplicationPair": nil,
+	}
+	for _, action := range []string{
+		"modifyReplicationConsistencyGroupRpo", "activateReplicationConsistencyGroup",
+		"pauseReplicationConsistencyGroup", "resumeReplicationConsistencyGroup",
+		"terminateReplicationConsistencyGroup", "removeReplicationConsistencyGroup",
+		"failoverReplicationConsistencyGroup", "restoreReplicationConsistencyGroup",
+		"reverseReplicationConsistencyGroup", "switchoverReplicationConsistencyGroup",
+		"testFailoverReplicationConsistencyGroup", "testFailoverStopReplicationConsistencyGroup",
+	} {
+		responses["POST /api/instances/ReplicationConsistencyGroup::rcg1/action/"+action] = nil
+	}
+	return responses
+}
+
+func Test_ReplicationConsistencyGroupManagement(t *testing.T) {
+	const prefix = "POST /api/instances/ReplicationConsistencyGroup::rcg1/action/"
+
+	tests := map[string]struct {
+		call     func(t *testing.T, system *System, rcg *ReplicationConsistencyGroup) error
+		isErr    bool
+		requests []string
+	}{
+		"create": {
+			call: func(t *testing.T, system *System, rcg *ReplicationConsistencyGroup) error {
+				resp, err := system.CreateReplicationConsistencyGroup(&types.ReplicationConsistencyGroupParam{
+					Name:                     "rcg",
+					RpoInSeconds:             "60",
+					ProtectionDomainID:       "pd1",
+					RemoteProtectionDomainID: "rpd1",
+					DestinationSystemID:      "sys2",
+				})
+				if err == nil {
+					assert.Equal(t, "rcg1", resp.ID)
+				}
+				return err
+			},
+			requests: []string{
+				`POST /api/types/ReplicationConsistencyGroup/instances {"name":"rcg","rpoInSeconds":"60","protectionDomainId":"pd1","remoteProtectionDomainId":"rpd1","destinationSystemId":"sys2"}`,
+			},
+		},
+		"create without protection domains": {
+			call: func(t *testing.T, system *System, rcg *ReplicationConsistencyGroup) error {
+				_, err := system.CreateReplicationConsistencyGroup(&types.ReplicationConsistencyGroupParam{
+					Name: "rcg",
+				})
+				return err
+			},
+			isErr: true,
+		},
+		"list": {
+			call: func(t *testing.T, system *System, rcg *ReplicationConsistencyGroup) error {
+				rcgs, err := system.GetReplicationConsistencyGroups()
+				assert.Len(t, rcgs, 1)
+				return err
+			},
+			requests: []string{"GET /api/types/ReplicationConsistencyGroup/instances"},
+		},
+		"get by ID": {
+			call: func(t *testing.T, system *System, rcg *ReplicationConsistencyGroup) error {
+				found, err := system.GetReplicationConsistencyGroupByID("rcg1")
+				if err == nil {
+					assert.Equal(t, 60, found.ReplicationConsistencyGroup.RpoInSeconds)
+				}
+				return err
+			},
+			requests: []string{"GET /api/instances/ReplicationConsistencyGroup::rcg1"},
+		},
+		"set RPO": {
+			call: func(t *testing.T, system *System, rcg *ReplicationConsistencyGroup) error {
+				return rcg.SetRPO(300)
+			},
+			requests: []string{prefix + `modifyReplicationConsistencyGroupRpo {"rpoInSeconds":"300"}`},
+		},
+		"activate": {
+			call: func(t *testing.T, system *System, rcg *ReplicationConsistencyGroup) error {
+				return rcg.Activate()
+			},
+			requests: []string{prefix + `activateReplicationConsistencyGroup {}`},
+		},
+		"pause": {
+			call: func(t *testing.T, system *System, rcg *ReplicationConsistencyGroup) error {
+				return rcg.Pause("")
+			},
+			requests: []string{prefix + `pauseReplicationConsistencyGroup {"pauseMode":"StopDataTransfer"}`},
+		},
+		"pause tracking changes": {
+			call: func(t *testing.T, system *System, rcg *ReplicationConsistencyGroup) error {
+				return rcg.Pause(PauseModeOnlyTrackChanges)
+			},
+			requests: []string{prefix + `pauseReplicationConsistencyGroup {"pauseMode":"OnlyTrackChanges"}`},
+		},
+		"resume": {
+			call: func(t *testing.T, system *System, rcg *ReplicationConsistencyGroup) error {
+				return rcg.Resume()
+			},
+			requests: []string{prefix + `resumeReplicationConsistencyGroup {}`},
+		},
+		"terminate": {
+			call: func(t *testing.T, system *System, rcg *ReplicationConsistencyGroup) error {
+				return rcg.Terminate()
+			},
+			requests: []string{prefix + `terminateReplicationConsistencyGroup {}`},
+		},
+		"remove": {
+			call: func(t *testing.T, system *System, rcg *ReplicationConsistencyGroup) error {
+				return rcg.Remove()
+			},
+			requests: []string{prefix + `removeReplicationConsistencyGroup {}`},
+		},
+		"create pair": {
+			call: func(t *testing.T, system *System, rcg *ReplicationConsistencyGroup) error {
+				pair, err := rcg.CreateReplicationPair(&types.ReplicationPairParam{
+					Name:                "pair",
+					SourceVolumeID:      "v1",
+					DestinationVolumeID: "rv1",
+				})
+				if err == nil {
+					assert.Equal(t, "pair1", pair.ID)
+				}
+				return err
+			},
+			requests: []string{
+				`POST /api/types/ReplicationPair/instances {"name":"pair","sourceVolumeId":"v1","destinationVolumeId":"rv1","replicationConsistencyGroupId":"rcg1","copyType":"OnlineCopy"}`,
+			},
+		},
+		"create pair without destination": {
+			call: func(t *testing.T, system *System, rcg *ReplicationConsistencyGroup) error {
+				_, err := rcg.CreateReplicationPair(&types.ReplicationPairParam{SourceVolumeID: "v1"})
+				return err
+			},
+			isErr: true,
+		},
+		"list pairs": {
+			call: func(t *testing.T, system *System, rcg *ReplicationConsistencyGroup) error {
+				pairs, err := rcg.GetReplicationPairs()
+				if err == nil && assert.Len(t, pairs, 1) {
+					assert.Equal(t, "rv1", pairs[0].RemoteVolumeID)
+				}
+				return err
+			},
+			requests: []string{"GET /api/instances/ReplicationConsistencyGroup::rcg1/relationships/ReplicationPair"},
+		},
+		"remove pair": {
+			call: func(t *testing.T, system *System, rcg *ReplicationConsistencyGroup) error {
+				return rcg.RemoveReplicationPair("pair1")
+			},
+			requests: []string{`POST /api/instances/ReplicationPair::pair1/action/removeReplicationPair {}`},
+		},
+		"failover": {
+			call: func(t *testing.T, system *System, rcg *ReplicationConsistencyGroup) error {
+				return rcg.Failover()
+			},
+			requests: []string{prefix + "failoverReplicationConsistencyGroup {}"},
+		},
+		"restore": {
+			call: func(t *testing.T, system *System, rcg *ReplicationConsistencyGroup) error {
+				return rcg.Restore()
+			},
+			requests: []string{prefix + "restoreReplicationConsistencyGroup {}"},
+		},
+		"reverse": {
+			call: func(t *testing.T, system *System, rcg *ReplicationConsistencyGroup) error {
+				return rcg.Reverse()
+			},
+			requests: []string{prefix + "reverseReplicationConsistencyGroup {}"},
+		},
+		"switchover": {
+			call: func(t *testing.T, system *System, rcg *ReplicationConsistencyGroup) error {
+				return rcg.Switchover()
+			},
+			requests: []string{prefix + "switchoverReplicationConsistencyGroup {}"},
+		},
+		"test failover": {
+			call: func(t *testing.T, system *System, rcg *ReplicationConsistencyGroup) error {
+				return rcg.TestFailover()
+			},
+			requests: []string{prefix + "testFailoverReplicationConsistencyGroup {}"},
+		},
+		"stop test failover": {
+			call: func(t *testing.T, system *System, rcg *ReplicationConsistencyGroup) error {
+				return rcg.TestFailoverStop()
+			},
+			requests: []string{prefix + "testFailoverStopReplicationConsistencyGroup {}"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			client, gateway := newTestGateway(t, "3.5", replicationResponses(nil))
+			system := NewSystem(client)
+			rcg := NewReplicationConsistencyGroupEx(client,
+				&types.ReplicationConsistencyGroup{ID: "rcg1"})
+
+			err := tt.call(t, system, rcg)
+			assert.Equal(t, tt.isErr, err != nil, "error: %v", err)
+			assert.Equal(t, tt.requests, gateway.Requests())
+		})
+	}
+}
+
+func Test_ReplicationRequiresVersion(t *testing.T) {
+	client, err := NewClientWithArgs("https://gateway.invalid", "3.0", true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	system := NewSystem(client)
+	_, err = system.GetReplicationConsistencyGroups()
+	assert.NotNil(t, err)
+	rcg := NewReplicationConsistencyGroupEx(client, &types.ReplicationConsistencyGroup{ID: "rcg1"})
+	assert.NotNil(t, rcg.Activate())
+	updateHeaders("")
+}
+
+func Test_NewReplicationStatus(t *testing.T) {
+	tests := map[string]struct {
+		group types.ReplicationConsistencyGroup
+		want  ReplicationLinkState
+	}{
+		"up":       {types.ReplicationConsistencyGroup{ActiveLocal: true, ActiveRemote: true, PauseMode: "None"}, ReplicationLinkUp},
+		"paused":   {types.ReplicationConsistencyGroup{ActiveLocal: true, ActiveRemote: true, PauseMode: PauseModeStopDataTransfer}, ReplicationLinkPaused},
+		"inactive": {types.ReplicationConsistencyGroup{ActiveLocal: true}, ReplicationLinkInactive},
+		"error":    {types.ReplicationConsistencyGroup{ActiveLocal: true, ActiveRemote: true, Error: 65}, ReplicationLinkError},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NewReplicationStatus(&tt.group).LinkState)
+		})
+	}
+}
+
+func Test_ReplicationWaitForStatus(t *testing.T) {
+	polls := 0
+	client, _ := newTestGateway(t, "3.5", replicationResponses(func() types.ReplicationConsistencyGroup {
+		polls++
+		state := "Started"
+		if polls >= 3 {
+			state = "Done"
+		}
+		return types.ReplicationConsistencyGroup{
+			ID: "rcg1", ActiveLocal: true, ActiveRemote: true,
+			CurrConsistMode: "Consistent", FailoverType: "Failover", FailoverState: state,
+		}
+	}))
+
+	rcg := NewReplicationConsistencyGroupEx(client,
+		&types.ReplicationConsistencyGroup{ID: "rcg1"})
+
+	status, err := rcg.WaitForStatus(context.Background(), FailoverStateIs("Done"),
+		&ReplicationWaitOptions{Timeout: 5 * time.Second, PollInterval: time.Millisecond})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, polls)
+	assert.Equal(t, "Done", status.FailoverState)
+	assert.Equal(t, "Consistent", status.ConsistencyMode)
+	assert.Equal(t, ReplicationLinkUp, status.LinkState)
+	assert.Equal(t, 42*time.Second, status.Lag)
+	assert.Equal(t, "Done", rcg.ReplicationConsistencyGroup.FailoverState)
+
+	status, err = rcg.WaitForStatus(context.Background(), LinkStateIs(ReplicationLinkPaused),
+		&ReplicationWaitOptions{Timeout: 10 * time.Millisecond, PollInterval: time.Millisecond})
+	assert.NotNil(t, err)
+	if assert.NotNil(t, status) {
+		assert.Equal(t, ReplicationLinkUp, status.LinkState)
+	}
+	assert.True(t, ConsistencyModeIs("Consistent")(status))
+
+	// the wait ends with the context
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	_, err = rcg.WaitForStatus(ctx, LinkStateIs(ReplicationLinkPaused),
+		&ReplicationWaitOptions{Timeout: time.Minute, PollInterval: time.Millisecond})
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), context.Canceled.Error())
+	}
+}
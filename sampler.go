@@ -0,0 +1,360 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goscaleio
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	types "github.com/AnshumanPradipPatil1506/goscaleio/types/v1"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultSamplerInterval is how often a Sampler polls by default
+	DefaultSamplerInterval = 30 * time.Second
+	// DefaultSamplerCapacity is the default number of samples kept per
+	// object and property
+	DefaultSamplerCapacity = 120
+)
+
+var errNotEnoughSamples = errors.New("not enough samples")
+
+// SampleMetric selects the value of a Sample that is aggregated
+type SampleMetric int
+
+const (
+	// SampleIOPS is the average number of operations per second
+	SampleIOPS SampleMetric = iota + 1
+	// SampleBandwidth is the average bandwidth in bytes per second
+	SampleBandwidth
+	// SampleLatency is the average latency in seconds
+	SampleLatency
+)
+
+func (m SampleMetric) String() string {
+	switch m {
+	case SampleIOPS:
+		return "IOPS"
+	case SampleBandwidth:
+		return "Bandwidth"
+	case SampleLatency:
+		return "Latency"
+	}
+	return fmt.Sprintf("SampleMetric(%d)", int(m))
+}
+
+// SampleKey identifies the samples of one BWC property of one object
+type SampleKey struct {
+	ObjectType string
+	ID         string
+	Property   string
+}
+
+// Sample is one BWC counter window converted to rates and averages
+type Sample struct {
+	// Time is when the sample was taken
+	Time time.Time
+	// IOPS is the average number of operations per second
+	IOPS float64
+	// BandwidthBytesPerSecond is the average bandwidth
+	BandwidthBytesPerSecond float64
+	// AverageLatency is the average latency of an operation. It is only
+	// meaningful for latency counters such as userDataSdcReadLatency.
+	AverageLatency time.Duration
+}
+
+// NewSample converts a BWC counter window into a Sample taken at t
+func NewSample(t time.Time, bwc types.BWC) Sample {
+	return Sample{
+		Time:                    t,
+		IOPS:                    bwc.IOPS(),
+		BandwidthBytesPerSecond: bwc.BandwidthBytesPerSecond(),
+		AverageLatency:          bwc.AverageLatency(),
+	}
+}
+
+// Value returns the value of the given metric
+func (s Sample) Value(m SampleMetric) float64 {
+	switch m {
+	case SampleIOPS:
+		return s.IOPS
+	case SampleBandwidth:
+		return s.BandwidthBytesPerSecond
+	case SampleLatency:
+		return s.AverageLatency.Seconds()
+	}
+	return 0
+}
+
+// SamplerOptions defines the options of a Sampler
+type SamplerOptions struct {
+	// Queries select the objects and BWC properties to sample, such as
+	// userDataReadBwc of every volume
+	Queries []StatisticsQuery
+	// Interval is how often Run polls, DefaultSamplerInterval if zero
+	Interval time.Duration
+	// Capacity is the number of samples kept per object and property,
+	// DefaultSamplerCapacity if zero. Older samples are dropped.
+	Capacity int
+}
+
+// Sampler periodically polls BWC statistics and keeps a bounded history of
+// samples per object and property. The history of an object and property
+// that a poll no longer returns, such as that of a removed volume, is
+// dropped.
+type Sampler struct {
+	system   *System
+	queries  []StatisticsQuery
+	interval time.Duration
+	capacity int
+
+	mu    sync.RWMutex
+	rings map[SampleKey]*sampleRing
+	now   func() time.Time
+}
+
+// NewSampler returns a Sampler of the system's statistics
+func NewSampler(system *System, opts *SamplerOptions) (*Sampler, error) {
+	if opts == nil || len(opts.Queries) == 0 {
+		return nil, errNoStatisticsQuery
+	}
+
+	sm := &Sampler{
+		system:   system,
+		queries:  opts.Queries,
+		interval: opts.Interval,
+		capacity: opts.Capacity,
+		rings:    make(map[SampleKey]*sampleRing),
+		now:      time.Now,
+	}
+	if sm.interval == 0 {
+		sm.interval = DefaultSamplerInterval
+	}
+	if sm.capacity == 0 {
+		sm.capacity = DefaultSamplerCapacity
+	}
+	if sm.capacity < 0 {
+		return nil, fmt.Errorf("invalid sampler capacity %d", sm.capacity)
+	}
+
+	return sm, nil
+}
+
+// Run polls every interval until ctx is done. Poll errors are logged and
+// do not stop the sampler.
+func (sm *Sampler) Run(ctx context.Context) {
+	ticker := time.NewTicker(sm.interval)
+	defer ticker.Stop()
+	for {
+		if err := sm.Poll(); err != nil {
+			doLog(log.WithError(err).Error, "Sampler: polling statistics")
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Poll takes one sample of every selected object and property, and drops
+// the samples of the keys it did not return
+func (sm *Sampler) Poll() (err error) {
+	c, end := sm.system.client.trace("Sampler.Poll")
+	defer end(&err)
+
+	stats, err := sm.system.withClient(c).QuerySelectedStatistics(sm.queries...)
+	if err != nil {
+		return err
+	}
+	now := sm.now()
+
+	samples := make(map[SampleKey]Sample)
+	for _, q := range sm.queries {
+		for _, id := range stats.IDs(q.Type) {
+			props, err := stats.Properties(q.Type, id)
+			if err != nil {
+				return err
+			}
+			for _, property := range q.Properties {
+				raw, ok := props[property]
+				if !ok {
+					continue
+				}
+				var bwc types.BWC
+				if err := json.Unmarshal(raw, &bwc); err != nil {
+					return fmt.Errorf("%s %s %s is not a BWC counter: %v",
+						q.Type, id, property, err)
+				}
+				samples[SampleKey{q.Type, id, property}] = NewSample(now, bwc)
+			}
+		}
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	for key := range sm.rings {
+		if _, ok := samples[key]; !ok {
+			delete(sm.rings, key)
+		}
+	}
+	for key, sample := range samples {
+		ring, ok := sm.rings[key]
+		if !ok {
+			ring = newSampleRing(sm.capacity)
+			sm.rings[key] = ring
+		}
+		ring.add(sample)
+	}
+
+	return nil
+}
+
+// Keys returns the keys that have samples, sorted by object type, ID and
+// property
+func (sm *Sampler) Keys() []SampleKey {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	keys := make([]SampleKey, 0, len(sm.rings))
+	for key := range sm.rings {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.ObjectType != b.ObjectType {
+			return a.ObjectType < b.ObjectType
+		}
+		if a.ID != b.ID {
+			return a.ID < b.ID
+		}
+		return a.Property < b.Property
+	})
+	return keys
+}
+
+// Samples returns the samples of a key, oldest first
+func (sm *Sampler) Samples(key SampleKey) []Sample {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	ring, ok := sm.rings[key]
+	if !ok {
+		return nil
+	}
+	return ring.samples()
+}
+
+// Mean returns the mean of a metric over the samples of a key
+func (sm *Sampler) Mean(key SampleKey, m SampleMetric) (float64, error) {
+	values := sm.values(key, m)
+	if len(values) == 0 {
+		return 0, errNotEnoughSamples
+	}
+
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values)), nil
+}
+
+// Percentile returns the p-th percentile, 0 to 100, of a metric over the
+// samples of a key, interpolating between the closest ranks
+func (sm *Sampler) Percentile(key SampleKey, m SampleMetric, p float64) (float64, error) {
+	if p < 0 || p > 100 {
+		return 0, fmt.Errorf("invalid percentile %v", p)
+	}
+	values := sm.values(key, m)
+	if len(values) == 0 {
+		return 0, errNotEnoughSamples
+	}
+
+	sort.Float64s(values)
+	rank := p / 100 * float64(len(values)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return values[lower] + (values[upper]-values[lower])*(rank-float64(lower)), nil
+}
+
+// Trend returns the change of a metric per second over the samples of a
+// key, as the slope of a least-squares fit. A positive trend means the
+// metric is rising.
+func (sm *Sampler) Trend(key SampleKey, m SampleMetric) (float64, error) {
+	samples := sm.Samples(key)
+	if len(samples) < 2 {
+		return 0, errNotEnoughSamples
+	}
+
+	start := samples[0].Time
+	var sumX, sumY, sumXY, sumXX float64
+	for _, s := range samples {
+		x := s.Time.Sub(start).Seconds()
+		y := s.Value(m)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	n := float64(len(samples))
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0, errNotEnoughSamples
+	}
+	return (n*sumXY - sumX*sumY) / denominator, nil
+}
+
+func (sm *Sampler) values(key SampleKey, m SampleMetric) []float64 {
+	samples := sm.Samples(key)
+	values := make([]float64, len(samples))
+	for i, s := range samples {
+		values[i] = s.Value(m)
+	}
+	return values
+}
+
+// sampleRing is a fixed-size ring of samples
+type sampleRing struct {
+	buf   []Sample
+	next  int
+	count int
+}
+
+func newSampleRing(capacity int) *sampleRing {
+	return &sampleRing{buf: make([]Sample, capacity)}
+}
+
+func (r *sampleRing) add(s Sample) {
+	r.buf[r.next] = s
+	r.next = (r.next + 1) % len(r.buf)
+	if r.count < len(r.buf) {
+		r.count++
+	}
+}
+
+// samples returns a copy of the samples, oldest first
+func (r *sampleRing) samples() []Sample {
+	out := make([]Sample, 0, r.count)
+	start := (r.next - r.count + len(r.buf)) % len(r.buf)
+	for i := 0; i < r.count; i++ {
+		out = append(out, r.buf[(start+i)%len(r.buf)])
+	}
+	return out
+}
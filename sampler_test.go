@@ -0,0 +1,178 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goscaleio
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	types "github.com/AnshumanPradipPatil1506/goscaleio/types/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_BWCRates(t *testing.T) {
+	bwc := types.BWC{TotalWeightInKb: 100, NumOccured: 50, NumSeconds: 5}
+	assert.Equal(t, 10.0, bwc.IOPS())
+	assert.Equal(t, 20480.0, bwc.BandwidthBytesPerSecond())
+	assert.Equal(t, 2*time.Microsecond, bwc.AverageLatency())
+
+	empty := types.BWC{}
+	assert.Equal(t, 0.0, empty.IOPS())
+	assert.Equal(t, 0.0, empty.BandwidthBytesPerSecond())
+	assert.Equal(t, time.Duration(0), empty.AverageLatency())
+}
+
+func Test_Sampler(t *testing.T) {
+	polls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		fmt.Fprintf(w, `{"Volume": {
+			"v1": {"userDataReadBwc": {"totalWeightInKb": %d, "numOccured": %d, "numSeconds": 1}},
+			"v2": {"userDataReadBwc": {"totalWeightInKb": 1, "numOccured": 1, "numSeconds": 1}}
+		}}`, polls*4, polls*10)
+	}))
+	defer ts.Close()
+
+	client, err := NewClientWithArgs(ts.URL, "3.5", true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	system := NewSystem(client)
+
+	_, err = NewSampler(system, nil)
+	assert.Equal(t, errNoStatisticsQuery, err)
+	_, err = NewSampler(system, &SamplerOptions{
+		Queries:  []StatisticsQuery{{Type: StatisticsTypeVolume, Properties: []string{"userDataReadBwc"}}},
+		Capacity: -1,
+	})
+	assert.NotNil(t, err)
+
+	sampler, err := NewSampler(system, &SamplerOptions{
+		Queries:  []StatisticsQuery{{Type: StatisticsTypeVolume, Properties: []string{"userDataReadBwc"}}},
+		Capacity: 4,
+	})
+	assert.Nil(t, err)
+	start := time.Unix(1000, 0)
+	now := start
+	sampler.now = func() time.Time { return now }
+
+	key := SampleKey{StatisticsTypeVolume, "v1", "userDataReadBwc"}
+	_, err = sampler.Percentile(key, SampleIOPS, 50)
+	assert.Equal(t, errNotEnoughSamples, err)
+
+	for i := 0; i < 6; i++ {
+		assert.Nil(t, sampler.Poll())
+		now = now.Add(10 * time.Second)
+	}
+
+	assert.Equal(t, []SampleKey{key, {StatisticsTypeVolume, "v2", "userDataReadBwc"}}, sampler.Keys())
+
+	// the ring keeps the last 4 of 6 polls
+	samples := sampler.Samples(key)
+	assert.Len(t, samples, 4)
+	assert.Equal(t, start.Add(20*time.Second), samples[0].Time)
+	assert.Equal(t, 30.0, samples[0].IOPS)
+	assert.Equal(t, 60.0, samples[3].IOPS)
+	assert.Equal(t, 24.0*1024, samples[3].BandwidthBytesPerSecond)
+
+	mean, err := sampler.Mean(key, SampleIOPS)
+	assert.Nil(t, err)
+	assert.Equal(t, 45.0, mean)
+
+	p, err := sampler.Percentile(key, SampleIOPS, 50)
+	assert.Nil(t, err)
+	assert.Equal(t, 45.0, p)
+	p, err = sampler.Percentile(key, SampleIOPS, 100)
+	assert.Nil(t, err)
+	assert.Equal(t, 60.0, p)
+	_, err = sampler.Percentile(key, SampleIOPS, 101)
+	assert.NotNil(t, err)
+
+	// 10 IOPS more every 10 seconds
+	trend, err := sampler.Trend(key, SampleIOPS)
+	assert.Nil(t, err)
+	assert.InDelta(t, 1.0, trend, 1e-9)
+	trend, err = sampler.Trend(SampleKey{StatisticsTypeVolume, "v2", "userDataReadBwc"}, SampleIOPS)
+	assert.Nil(t, err)
+	assert.InDelta(t, 0.0, trend, 1e-9)
+
+	assert.Nil(t, sampler.Samples(SampleKey{StatisticsTypeVolume, "v3", "userDataReadBwc"}))
+}
+
+func Test_SamplerDropsRemovedObjects(t *testing.T) {
+	volumes := `"v1": {"userDataReadBwc": {"totalWeightInKb": 1, "numOccured": 1, "numSeconds": 1}},
+		"v2": {"userDataReadBwc": {"totalWeightInKb": 1, "numOccured": 1, "numSeconds": 1}}`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"Volume": {%s}}`, volumes)
+	}))
+	defer ts.Close()
+
+	client, err := NewClientWithArgs(ts.URL, "3.5", true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sampler, err := NewSampler(NewSystem(client), &SamplerOptions{
+		Queries: []StatisticsQuery{{Type: StatisticsTypeVolume, Properties: []string{"userDataReadBwc"}}},
+	})
+	assert.Nil(t, err)
+
+	assert.Nil(t, sampler.Poll())
+	assert.Len(t, sampler.Keys(), 2)
+
+	// v2 is removed
+	volumes = `"v1": {"userDataReadBwc": {"totalWeightInKb": 1, "numOccured": 1, "numSeconds": 1}}`
+	assert.Nil(t, sampler.Poll())
+	key := SampleKey{StatisticsTypeVolume, "v1", "userDataReadBwc"}
+	assert.Equal(t, []SampleKey{key}, sampler.Keys())
+	assert.Len(t, sampler.Samples(key), 2)
+	assert.Nil(t, sampler.Samples(SampleKey{StatisticsTypeVolume, "v2", "userDataReadBwc"}))
+}
+
+func Test_SamplerRun(t *testing.T) {
+	polled := make(chan struct{}, 10)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"Sdc": {"sdc1": {"userDataReadBwc": {"totalWeightInKb": 1, "numOccured": 1, "numSeconds": 1}}}}`)
+		select {
+		case polled <- struct{}{}:
+		default:
+		}
+	}))
+	defer ts.Close()
+
+	client, err := NewClientWithArgs(ts.URL, "3.5", true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sampler, err := NewSampler(NewSystem(client), &SamplerOptions{
+		Queries:  []StatisticsQuery{{Type: StatisticsTypeSdc, Properties: []string{"userDataReadBwc"}}},
+		Interval: time.Millisecond,
+	})
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		sampler.Run(ctx)
+		close(done)
+	}()
+	<-polled
+	<-polled
+	cancel()
+	<-done
+
+	assert.GreaterOrEqual(t, len(sampler.Samples(SampleKey{StatisticsTypeSdc, "sdc1", "userDataReadBwc"})), 1)
+}
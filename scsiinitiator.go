@@ -13,23 +13,24 @@
 package goscaleio
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
-	"time"
 
 	types "github.com/AnshumanPradipPatil1506/goscaleio/types/v1"
 )
 
 // GetScsiInitiator returns a ScsiInitiator
-func (s *System) GetScsiInitiator() ([]types.ScsiInitiator, error) {
-	defer TimeSpent("GetScsiInitiator", time.Now())
+func (s *System) GetScsiInitiator() (_ []types.ScsiInitiator, err error) {
+	c, end := s.client.trace("System.GetScsiInitiator")
+	defer end(&err)
 
 	path := fmt.Sprintf(
 		"/api/instances/System::%v/relationships/ScsiInitiator",
 		s.System.ID)
 
 	var si []types.ScsiInitiator
-	err := s.client.getJSONWithRetry(
+	err = c.getJSONWithRetry(
 		http.MethodGet, path, nil, &si)
 	if err != nil {
 		return nil, err
@@ -37,3 +38,157 @@ func (s *System) GetScsiInitiator() ([]types.ScsiInitiator, error) {
 
 	return si, nil
 }
+
+// FindScsiInitiator returns the first ScsiInitiator matching all of the
+// supplied options
+func (s *System) FindScsiInitiator(opts ...FindOption) (_ *types.ScsiInitiator, err error) {
+	c, end := s.client.trace("System.FindScsiInitiator")
+	defer end(&err)
+
+	initiators, err := s.withClient(c).FindAllScsiInitiator(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return initiators[0], nil
+}
+
+// FindAllScsiInitiator returns every ScsiInitiator matching all of the
+// supplied options
+func (s *System) FindAllScsiInitiator(opts ...FindOption) (_ []*types.ScsiInitiator, err error) {
+	c, end := s.client.trace("System.FindAllScsiInitiator")
+	defer end(&err)
+
+	if err := scsiInitiatorFinder.validate(opts); err != nil {
+		return nil, err
+	}
+
+	initiators, err := s.withClient(c).GetScsiInitiator()
+	if err != nil {
+		return nil, err
+	}
+
+	matches := scsiInitiatorFinder.match(initiators, opts)
+	if len(matches) == 0 {
+		return nil, errors.New("Couldn't find ScsiInitiator")
+	}
+
+	found := make([]*types.ScsiInitiator, 0, len(matches))
+	for _, i := range matches {
+		found = append(found, &initiators[i])
+	}
+
+	return found, nil
+}
+
+// CreateScsiInitiator registers a SCSI initiator by its IQN and returns its ID
+func (s *System) CreateScsiInitiator(name, iqn string) (_ string, err error) {
+	c, end := s.client.trace("System.CreateScsiInitiator")
+	defer end(&err)
+
+	c.audit("ScsiInitiator", "")
+
+	if err := c.checkCapability(OpHostAdmin); err != nil {
+		return "", err
+	}
+
+	if iqn == "" {
+		return "", errors.New("an IQN is required to create a ScsiInitiator")
+	}
+
+	path := "/api/types/ScsiInitiator/instances"
+
+	param := &types.ScsiInitiatorParam{
+		Name: name,
+		IQN:  iqn,
+	}
+
+	var resp types.ScsiInitiatorResp
+	err = c.getJSONWithRetry(
+		http.MethodPost, path, param, &resp)
+	if err != nil {
+		return "", err
+	}
+
+	return resp.ID, nil
+}
+
+// RemoveScsiInitiator removes the SCSI initiator with the given ID
+func (s *System) RemoveScsiInitiator(id string) (err error) {
+	c, end := s.client.trace("System.RemoveScsiInitiator")
+	defer end(&err)
+
+	c.audit("ScsiInitiator", id)
+
+	if err := c.checkCapability(OpHostAdmin); err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf(
+		"/api/instances/ScsiInitiator::%v/action/removeScsiInitiator", id)
+
+	return c.getJSONWithRetry(
+		http.MethodPost, path, types.EmptyPayload{}, nil)
+}
+
+// SetScsiInitiatorName renames the SCSI initiator with the given ID
+func (s *System) SetScsiInitiatorName(id, name string) (err error) {
+	c, end := s.client.trace("System.SetScsiInitiatorName")
+	defer end(&err)
+
+	c.audit("ScsiInitiator", id)
+
+	if err := c.checkCapability(OpHostAdmin); err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf(
+		"/api/instances/ScsiInitiator::%v/action/setScsiInitiatorName", id)
+
+	param := &types.SetScsiInitiatorNameParam{
+		NewName: name,
+	}
+	return c.getJSONWithRetry(
+		http.MethodPost, path, param, nil)
+}
+
+// MapVolumeScsiInitiator maps a volume to a SCSI initiator. The LUN is
+// assigned by the system if the parameter leaves it empty.
+func (v *Volume) MapVolumeScsiInitiator(
+	mapParam *types.MapVolumeScsiInitiatorParam) (err error) {
+	c, end := v.client.trace("Volume.MapVolumeScsiInitiator")
+	defer end(&err)
+
+	c.audit("Volume", v.Volume.ID, mapParam.ScsiInitiatorID)
+
+	if err := c.checkCapability(OpVolumeMap); err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf(
+		"/api/instances/Volume::%s/action/addMappedScsiInitiator",
+		v.Volume.ID)
+
+	return c.getJSONWithRetry(
+		http.MethodPost, path, mapParam, nil)
+}
+
+// UnmapVolumeScsiInitiator unmaps a volume from a SCSI initiator
+func (v *Volume) UnmapVolumeScsiInitiator(
+	unmapParam *types.UnmapVolumeScsiInitiatorParam) (err error) {
+	c, end := v.client.trace("Volume.UnmapVolumeScsiInitiator")
+	defer end(&err)
+
+	c.audit("Volume", v.Volume.ID, unmapParam.ScsiInitiatorID)
+
+	if err := c.checkCapability(OpVolumeMap); err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf(
+		"/api/instances/Volume::%s/action/removeMappedScsiInitiator",
+		v.Volume.ID)
+
+	return c.getJSONWithRetry(
+		http.MethodPost, path, unmapParam, nil)
+}
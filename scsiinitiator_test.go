@@ -0,0 +1,157 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goscaleio
+
+import (
+	"encoding/json"
+	"testing"
+
+	types "github.com/AnshumanPradipPatil1506/goscaleio/types/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ScsiInitiatorManagement(t *testing.T) {
+	responses := map[string]interface{}{
+		"GET /api/instances/System::sys1/relationships/ScsiInitiator": []types.ScsiInitiator{
+			{ID: "si1", Name: "host-a", IQN: "iqn.1994-05.com.redhat:aaaa"},
+			{ID: "si2", Name: "host-b", IQN: "iqn.1994-05.com.redhat:bbbb"},
+		},
+		"POST /api/types/ScsiInitiator/instances":                            types.ScsiInitiatorResp{ID: "si3"},
+		"POST /api/instances/ScsiInitiator::si3/action/setScsiInitiatorName": nil,
+		"POST /api/instances/ScsiInitiator::si3/action/removeScsiInitiator":  nil,
+		"POST /api/instances/Volume::v1/action/addMappedScsiInitiator":       nil,
+		"POST /api/instances/Volume::v1/action/removeMappedScsiInitiator":    nil,
+	}
+	const list = "GET /api/instances/System::sys1/relationships/ScsiInitiator"
+
+	tests := map[string]struct {
+		call     func(t *testing.T, system *System, vol *Volume) error
+		isErr    bool
+		requests []string
+	}{
+		"find by IQN": {
+			call: func(t *testing.T, system *System, vol *Volume) error {
+				si, err := system.FindScsiInitiator(ByIQN("IQN.1994-05.com.redhat:bbbb"))
+				if err == nil {
+					assert.Equal(t, "si2", si.ID)
+				}
+				return err
+			},
+			requests: []string{list},
+		},
+		"find unknown name": {
+			call: func(t *testing.T, system *System, vol *Volume) error {
+				_, err := system.FindScsiInitiator(ByName("host-c"))
+				return err
+			},
+			isErr:    true,
+			requests: []string{list},
+		},
+		"find by GUID": {
+			call: func(t *testing.T, system *System, vol *Volume) error {
+				_, err := system.FindScsiInitiator(ByGUID("9E56672F-2F4B-4A42-BFF4-88B6846FBFDA"))
+				assert.EqualError(t, err, "GUID lookup is not supported for ScsiInitiator")
+				return err
+			},
+			isErr: true,
+		},
+		"create": {
+			call: func(t *testing.T, system *System, vol *Volume) error {
+				id, err := system.CreateScsiInitiator("host-c", "iqn.1994-05.com.redhat:cccc")
+				if err == nil {
+					assert.Equal(t, "si3", id)
+				}
+				return err
+			},
+			requests: []string{
+				`POST /api/types/ScsiInitiator/instances {"name":"host-c","iqn":"iqn.1994-05.com.redhat:cccc"}`,
+			},
+		},
+		"create without IQN": {
+			call: func(t *testing.T, system *System, vol *Volume) error {
+				_, err := system.CreateScsiInitiator("host-c", "")
+				return err
+			},
+			isErr: true,
+		},
+		"rename": {
+			call: func(t *testing.T, system *System, vol *Volume) error {
+				return system.SetScsiInitiatorName("si3", "host-d")
+			},
+			requests: []string{
+				`POST /api/instances/ScsiInitiator::si3/action/setScsiInitiatorName {"newName":"host-d"}`,
+			},
+		},
+		"map volume": {
+			call: func(t *testing.T, system *System, vol *Volume) error {
+				return vol.MapVolumeScsiInitiator(&types.MapVolumeScsiInitiatorParam{
+					ScsiInitiatorID: "si3",
+					Lun:             "3",
+				})
+			},
+			requests: []string{
+				`POST /api/instances/Volume::v1/action/addMappedScsiInitiator {"scsiInitiatorId":"si3","lun":"3"}`,
+			},
+		},
+		"unmap volume": {
+			call: func(t *testing.T, system *System, vol *Volume) error {
+				return vol.UnmapVolumeScsiInitiator(&types.UnmapVolumeScsiInitiatorParam{
+					ScsiInitiatorID: "si3",
+				})
+			},
+			requests: []string{
+				`POST /api/instances/Volume::v1/action/removeMappedScsiInitiator {"scsiInitiatorId":"si3"}`,
+			},
+		},
+		"remove": {
+			call: func(t *testing.T, system *System, vol *Volume) error {
+				return system.RemoveScsiInitiator("si3")
+			},
+			requests: []string{
+				`POST /api/instances/ScsiInitiator::si3/action/removeScsiInitiator {}`,
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			client, gateway := newTestGateway(t, "3.5", responses)
+			system := NewSystem(client)
+			system.System.ID = "sys1"
+			vol := NewVolume(client)
+			vol.Volume = &types.Volume{ID: "v1"}
+
+			err := tt.call(t, system, vol)
+			assert.Equal(t, tt.isErr, err != nil, "error: %v", err)
+			assert.Equal(t, tt.requests, gateway.Requests())
+		})
+	}
+}
+
+func Test_MappedScsiInitiatorInfo(t *testing.T) {
+	data := `{"id":"v1","mappedScsiInitiatorInfo":[
+		{"scsiInitiatorId":"si1","scsiInitiatorName":"host-a","scsiInitiatorIqn":"iqn.x:a","lun":2}]}`
+
+	var vol types.Volume
+	assert.Nil(t, json.Unmarshal([]byte(data), &vol))
+	if assert.Len(t, vol.MappedScsiInitiatorInfo, 1) {
+		info := vol.MappedScsiInitiatorInfo[0]
+		assert.Equal(t, "si1", info.ScsiInitiatorID)
+		assert.Equal(t, "host-a", info.ScsiInitiatorName)
+		assert.Equal(t, "iqn.x:a", info.ScsiInitiatorIQN)
+		assert.Equal(t, 2, info.Lun)
+	}
+
+	// volumes without initiators report null
+	assert.Nil(t, json.Unmarshal([]byte(`{"id":"v2","mappedScsiInitiatorInfo":null}`), &vol))
+}
@@ -16,11 +16,13 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"os/exec"
-	"reflect"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+
 	types "github.com/AnshumanPradipPatil1506/goscaleio/types/v1"
 )
 
@@ -39,14 +41,15 @@ func NewSdc(client *Client, sdc *types.Sdc) *Sdc {
 }
 
 // GetSdc returns a Sdc
-func (s *System) GetSdc() ([]types.Sdc, error) {
-	defer TimeSpent("GetSdc", time.Now())
+func (s *System) GetSdc() (_ []types.Sdc, err error) {
+	c, end := s.client.trace("System.GetSdc")
+	defer end(&err)
 
 	path := fmt.Sprintf("/api/instances/System::%v/relationships/Sdc",
 		s.System.ID)
 
 	var sdcs []types.Sdc
-	err := s.client.getJSONWithRetry(
+	err = c.getJSONWithRetry(
 		http.MethodGet, path, nil, &sdcs)
 	if err != nil {
 		return nil, err
@@ -56,24 +59,32 @@ func (s *System) GetSdc() ([]types.Sdc, error) {
 }
 
 // GetSdcById returns a Sdc searched by id
-func (s *System) GetSdcById(id string) (*Sdc, error) {
-	defer TimeSpent("GetSdcById", time.Now())
+func (s *System) GetSdcById(id string) (_ *Sdc, err error) {
+	c, end := s.client.trace("System.GetSdcById")
+	defer end(&err)
 
 	path := fmt.Sprintf("api/instances/Sdc::%v", id)
 
 	var sdc types.Sdc
-	err := s.client.getJSONWithRetry(
+	err = c.getJSONWithRetry(
 		http.MethodGet, path, nil, &sdc)
 	if err != nil {
-		return NewSdc(s.client, &sdc), nil
+		return NewSdc(c.base(), &sdc), nil
 	}
 
-	return NewSdc(s.client, &sdc), nil
+	return NewSdc(c.base(), &sdc), nil
 }
 
 // ChangeSdcName returns a Sdc after changing its name
-func (s *System) ChangeSdcName(idOfSdc, name string) (*Sdc, error) {
-	defer TimeSpent("GetSdcById", time.Now())
+func (s *System) ChangeSdcName(idOfSdc, name string) (_ *Sdc, err error) {
+	c, end := s.client.trace("System.ChangeSdcName")
+	defer end(&err)
+
+	c.audit("Sdc", idOfSdc)
+
+	if err := c.checkCapability(OpHostAdmin); err != nil {
+		return nil, err
+	}
 
 	path := fmt.Sprintf("/api/instances/Sdc::%v/action/setSdcName", idOfSdc)
 
@@ -82,39 +93,60 @@ func (s *System) ChangeSdcName(idOfSdc, name string) (*Sdc, error) {
 	var body types.ChangeSdcNameParam = types.ChangeSdcNameParam{
 		SdcName: name,
 	}
-	err := s.client.getJSONWithRetry(
+	err = c.getJSONWithRetry(
 		http.MethodPost, path, body, &sdc)
 	if err != nil {
-		return NewSdc(s.client, &sdc), nil
+		return NewSdc(c.base(), &sdc), nil
 
 	}
 
-	return NewSdc(s.client, &sdc), nil
+	return NewSdc(c.base(), &sdc), nil
 }
 
-// FindSdc returns a Sdc
-func (s *System) FindSdc(field, value string) (*Sdc, error) {
-	defer TimeSpent("FindSdc", time.Now())
+// FindSdc returns the first Sdc matching all of the supplied options
+func (s *System) FindSdc(opts ...FindOption) (_ *Sdc, err error) {
+	c, end := s.client.trace("System.FindSdc")
+	defer end(&err)
+
+	sdcs, err := s.withClient(c).FindAllSdc(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return sdcs[0], nil
+}
+
+// FindAllSdc returns every Sdc matching all of the supplied options
+func (s *System) FindAllSdc(opts ...FindOption) (_ []*Sdc, err error) {
+	c, end := s.client.trace("System.FindAllSdc")
+	defer end(&err)
+
+	if err := sdcFinder.validate(opts); err != nil {
+		return nil, err
+	}
 
 	sdcs, err := s.GetSdc()
 	if err != nil {
 		return nil, err
 	}
 
-	for i, sdc := range sdcs {
-		valueOf := reflect.ValueOf(sdc)
-		switch {
-		case reflect.Indirect(valueOf).FieldByName(field).String() == value:
-			return NewSdc(s.client, &sdcs[i]), nil
-		}
+	matches := sdcFinder.match(sdcs, opts)
+	if len(matches) == 0 {
+		return nil, errors.New("Couldn't find SDC")
+	}
+
+	found := make([]*Sdc, 0, len(matches))
+	for _, i := range matches {
+		found = append(found, NewSdc(c.base(), &sdcs[i]))
 	}
 
-	return nil, errors.New("Couldn't find SDC")
+	return found, nil
 }
 
 // GetStatistics returns a Sdc statistcs
-func (sdc *Sdc) GetStatistics() (*types.SdcStatistics, error) {
-	defer TimeSpent("GetStatistics", time.Now())
+func (sdc *Sdc) GetStatistics() (_ *types.SdcStatistics, err error) {
+	c, end := sdc.client.trace("Sdc.GetStatistics")
+	defer end(&err)
 
 	link, err := GetLinkFromSdc(sdc.Sdc, "/api/Sdc/relationship/Statistics")
 	if err != nil {
@@ -122,7 +154,7 @@ func (sdc *Sdc) GetStatistics() (*types.SdcStatistics, error) {
 	}
 
 	var stats types.SdcStatistics
-	err = sdc.client.getJSONWithRetry(
+	err = c.getJSONWithRetry(
 		http.MethodGet, link.HREF, nil, &stats)
 	if err != nil {
 		return nil, err
@@ -132,8 +164,9 @@ func (sdc *Sdc) GetStatistics() (*types.SdcStatistics, error) {
 }
 
 // GetVolume returns a volume
-func (sdc *Sdc) GetVolume() ([]*types.Volume, error) {
-	defer TimeSpent("GetVolume", time.Now())
+func (sdc *Sdc) GetVolume() (_ []*types.Volume, err error) {
+	c, end := sdc.client.trace("Sdc.GetVolume")
+	defer end(&err)
 
 	link, err := GetLinkFromSdc(sdc.Sdc, "/api/Sdc/relationship/Volume")
 	if err != nil {
@@ -141,7 +174,7 @@ func (sdc *Sdc) GetVolume() ([]*types.Volume, error) {
 	}
 
 	var vols []*types.Volume
-	err = sdc.client.getJSONWithRetry(
+	err = c.getJSONWithRetry(
 		http.MethodGet, link.HREF, nil, &vols)
 	if err != nil {
 		return nil, err
@@ -151,8 +184,9 @@ func (sdc *Sdc) GetVolume() ([]*types.Volume, error) {
 }
 
 // FindVolumes returns volumes
-func (sdc *Sdc) FindVolumes() ([]*Volume, error) {
-	defer TimeSpent("FindVolumes", time.Now())
+func (sdc *Sdc) FindVolumes() (_ []*Volume, err error) {
+	c, end := sdc.client.trace("Sdc.FindVolumes")
+	defer end(&err)
 
 	var rlt []*Volume
 	vols, err := sdc.GetVolume()
@@ -161,7 +195,7 @@ func (sdc *Sdc) FindVolumes() ([]*Volume, error) {
 	}
 
 	for _, v := range vols {
-		volClient := NewVolume(sdc.client)
+		volClient := NewVolume(c.base())
 		volClient.Volume = v
 		rlt = append(rlt, volClient)
 	}
@@ -169,33 +203,124 @@ func (sdc *Sdc) FindVolumes() ([]*Volume, error) {
 	return rlt, nil
 }
 
+// SdcGUIDSource identifies how the GUID of the local SDC was obtained
+type SdcGUIDSource string
+
+const (
+	// SdcGUIDSourceIoctl means the GUID was queried from the SDC device
+	SdcGUIDSourceIoctl SdcGUIDSource = "ioctl"
+	// SdcGUIDSourceDrvCfg means the GUID was queried by running DrvCfgBinary
+	SdcGUIDSourceDrvCfg SdcGUIDSource = "drv_cfg"
+)
+
+// DrvCfgBinary is the drv_cfg utility, used to add and remove MDMs and to
+// query the GUID when the SDC device cannot be queried
+var DrvCfgBinary = "/opt/emc/scaleio/sdc/bin/drv_cfg"
+
+// LocalSdcIdentity is the identity of the SDC installed on this host
+type LocalSdcIdentity struct {
+	// GUID is the SDC GUID, upper case in canonical UUID form
+	GUID string
+	// Source is how the GUID was obtained
+	Source SdcGUIDSource
+}
+
+var (
+	localSdcMu       sync.Mutex
+	localSdcIdentity *LocalSdcIdentity
+	// localSdcMockMode is SCINIMockMode as it was when localSdcIdentity was
+	// cached
+	localSdcMockMode bool
+)
+
+// NormalizeSdcGUID returns guid upper case in canonical UUID form, so GUIDs
+// from the SDC device, drv_cfg and the gateway can be compared directly
+func NormalizeSdcGUID(guid string) (string, error) {
+	u, err := uuid.Parse(strings.TrimSpace(guid))
+	if err != nil {
+		return "", fmt.Errorf("invalid SDC GUID %q: %v", guid, err)
+	}
+	return strings.ToUpper(u.String()), nil
+}
+
+// GetLocalSdcIdentity returns the identity of the local SDC. The GUID is
+// queried from the SDC device and, if that fails, by running DrvCfgBinary.
+// The result is cached until ResetLocalSdcIdentity or SetSDCDriver is called,
+// or SCINIMockMode changes.
+func GetLocalSdcIdentity() (*LocalSdcIdentity, error) {
+	defer TimeSpent("GetLocalSdcIdentity", time.Now())
+
+	localSdcMu.Lock()
+	defer localSdcMu.Unlock()
+
+	if localSdcIdentity != nil && localSdcMockMode == SCINIMockMode {
+		identity := *localSdcIdentity
+		return &identity, nil
+	}
+
+	source := SdcGUIDSourceIoctl
+	guid, ioctlErr := DrvCfgQueryGUID()
+	if ioctlErr != nil {
+		doLog(log.WithError(ioctlErr).Debug, "falling back to drv_cfg to query the SDC GUID")
+		out, err := drvCfgCommand("--query_guid")
+		if err != nil {
+			return nil, fmt.Errorf(
+				"GetLocalSdcIdentity: query GUID failed: ioctl: %v, drv_cfg: %v",
+				ioctlErr, err)
+		}
+		source = SdcGUIDSourceDrvCfg
+		guid = string(out)
+	}
+
+	normalized, err := NormalizeSdcGUID(guid)
+	if err != nil {
+		return nil, fmt.Errorf("GetLocalSdcIdentity: %v", err)
+	}
+
+	localSdcIdentity = &LocalSdcIdentity{
+		GUID:   normalized,
+		Source: source,
+	}
+	localSdcMockMode = SCINIMockMode
+	identity := *localSdcIdentity
+	return &identity, nil
+}
+
+// ResetLocalSdcIdentity clears the identity cached by GetLocalSdcIdentity
+func ResetLocalSdcIdentity() {
+	localSdcMu.Lock()
+	defer localSdcMu.Unlock()
+	localSdcIdentity = nil
+}
+
 // GetSdcLocalGUID returns GUID
 func GetSdcLocalGUID() (string, error) {
 	defer TimeSpent("GetSdcLocalGUID", time.Now())
 
-	// get sdc kernel guid
-	// /bin/emc/scaleio/drv_cfg --query_guid
-	// sdcKernelGuid := "271bad82-08ee-44f2-a2b1-7e2787c27be1"
-
-	out, err := exec.Command("/opt/emc/scaleio/sdc/bin/drv_cfg", "--query_guid").Output()
+	identity, err := GetLocalSdcIdentity()
 	if err != nil {
-		return "", fmt.Errorf("GetSdcLocalGUID: query vols failed: %v", err)
+		return "", err
 	}
 
-	sdcGUID := strings.Replace(string(out), "\n", "", -1)
-
-	return sdcGUID, nil
+	return identity.GUID, nil
 }
 
 // MapVolumeSdc maps a volume to Sdc
 func (v *Volume) MapVolumeSdc(
-	mapVolumeSdcParam *types.MapVolumeSdcParam) error {
-	defer TimeSpent("MapVolumeSdc", time.Now())
+	mapVolumeSdcParam *types.MapVolumeSdcParam) (err error) {
+	c, end := v.client.trace("Volume.MapVolumeSdc")
+	defer end(&err)
+
+	c.audit("Volume", v.Volume.ID, mapVolumeSdcParam.SdcID)
+
+	if err := c.checkCapability(OpVolumeMap); err != nil {
+		return err
+	}
 
 	path := fmt.Sprintf("/api/instances/Volume::%s/action/addMappedSdc",
 		v.Volume.ID)
 
-	err := v.client.getJSONWithRetry(
+	err = c.getJSONWithRetry(
 		http.MethodPost, path, mapVolumeSdcParam, nil)
 	if err != nil {
 		return err
@@ -206,13 +331,20 @@ func (v *Volume) MapVolumeSdc(
 
 // UnmapVolumeSdc unmaps a volume from Sdc
 func (v *Volume) UnmapVolumeSdc(
-	unmapVolumeSdcParam *types.UnmapVolumeSdcParam) error {
-	defer TimeSpent("UnmapVolumeSdc", time.Now())
+	unmapVolumeSdcParam *types.UnmapVolumeSdcParam) (err error) {
+	c, end := v.client.trace("Volume.UnmapVolumeSdc")
+	defer end(&err)
+
+	c.audit("Volume", v.Volume.ID, unmapVolumeSdcParam.SdcID)
+
+	if err := c.checkCapability(OpVolumeMap); err != nil {
+		return err
+	}
 
 	path := fmt.Sprintf("/api/instances/Volume::%s/action/removeMappedSdc",
 		v.Volume.ID)
 
-	err := v.client.getJSONWithRetry(
+	err = c.getJSONWithRetry(
 		http.MethodPost, path, unmapVolumeSdcParam, nil)
 	if err != nil {
 		return err
@@ -223,14 +355,21 @@ func (v *Volume) UnmapVolumeSdc(
 
 // SetMappedSdcLimits sets Sdc mapped limits
 func (v *Volume) SetMappedSdcLimits(
-	setMappedSdcLimitsParam *types.SetMappedSdcLimitsParam) error {
-	defer TimeSpent("SetMappedSdcLimits", time.Now())
+	setMappedSdcLimitsParam *types.SetMappedSdcLimitsParam) (err error) {
+	c, end := v.client.trace("Volume.SetMappedSdcLimits")
+	defer end(&err)
+
+	c.audit("Volume", v.Volume.ID, setMappedSdcLimitsParam.SdcID)
+
+	if err := c.checkCapability(OpVolumeMap); err != nil {
+		return err
+	}
 
 	path := fmt.Sprintf(
 		"/api/instances/Volume::%s/action/setMappedSdcLimits",
 		v.Volume.ID)
 
-	err := v.client.getJSONWithRetry(
+	err = c.getJSONWithRetry(
 		http.MethodPost, path, setMappedSdcLimitsParam, nil)
 	if err != nil {
 		return err
@@ -117,3 +117,58 @@ func Test_FindVolumes(t *testing.T) {
 		})
 	}
 }
+
+func Test_GetLocalSdcIdentity(t *testing.T) {
+	fake := NewFakeSDCDriver("9e56672f-2f4b-4a42-bff4-88b6846fbfda")
+	SetSDCDriver(fake)
+	defer SetSDCDriver(nil)
+
+	drvCfgCalls := 0
+	oldCommand := drvCfgCommand
+	drvCfgCommand = func(args ...string) ([]byte, error) {
+		assert.Equal(t, []string{"--query_guid"}, args)
+		drvCfgCalls++
+		return []byte("271bad82-08ee-44f2-a2b1-7e2787c27be1\n"), nil
+	}
+	defer func() { drvCfgCommand = oldCommand }()
+
+	identity, err := GetLocalSdcIdentity()
+	assert.Nil(t, err)
+	assert.Equal(t, &LocalSdcIdentity{GUID: "9E56672F-2F4B-4A42-BFF4-88B6846FBFDA", Source: SdcGUIDSourceIoctl}, identity)
+
+	// the identity is cached
+	guid, err := GetSdcLocalGUID()
+	assert.Nil(t, err)
+	assert.Equal(t, "9E56672F-2F4B-4A42-BFF4-88B6846FBFDA", guid)
+	assert.Equal(t, 1, fake.Calls(SDCOpQueryGUID))
+
+	// falls back to drv_cfg when the device cannot be queried
+	ResetLocalSdcIdentity()
+	fake.SetRC(SDCOpQueryGUID, 3)
+	identity, err = GetLocalSdcIdentity()
+	assert.Nil(t, err)
+	assert.Equal(t, &LocalSdcIdentity{GUID: "271BAD82-08EE-44F2-A2B1-7E2787C27BE1", Source: SdcGUIDSourceDrvCfg}, identity)
+	assert.Equal(t, 1, drvCfgCalls)
+
+	ResetLocalSdcIdentity()
+	drvCfgCommand = func(args ...string) ([]byte, error) {
+		return nil, fmt.Errorf("exec: no such file or directory")
+	}
+	_, err = GetLocalSdcIdentity()
+	assert.EqualError(t, err, "GetLocalSdcIdentity: query GUID failed: ioctl: Request to query GUID failed, RC=3, drv_cfg: exec: no such file or directory")
+
+	// the cached identity is not used once SCINIMockMode changes
+	SetSDCDriver(nil)
+	drvCfgCommand = oldCommand
+	SCINIMockMode = true
+	identity, err = GetLocalSdcIdentity()
+	assert.Nil(t, err)
+	assert.Equal(t, &LocalSdcIdentity{GUID: mockGUID, Source: SdcGUIDSourceIoctl}, identity)
+	SCINIMockMode = false
+	identity, err = GetLocalSdcIdentity()
+	assert.False(t, err == nil && identity.GUID == mockGUID)
+	ResetLocalSdcIdentity()
+
+	_, err = NormalizeSdcGUID("garbage")
+	assert.NotNil(t, err)
+}
@@ -16,8 +16,6 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"reflect"
-	"time"
 
 	types "github.com/AnshumanPradipPatil1506/goscaleio/types/v1"
 )
@@ -46,8 +44,15 @@ func NewSdsEx(client *Client, sds *types.Sds) *Sds {
 
 // CreateSds creates a new Sds
 func (pd *ProtectionDomain) CreateSds(
-	name string, ipList []string) (string, error) {
-	defer TimeSpent("CreateSds", time.Now())
+	name string, ipList []string) (_ string, err error) {
+	c, end := pd.client.trace("ProtectionDomain.CreateSds")
+	defer end(&err)
+
+	c.audit("Sds", "")
+
+	if err := c.checkCapability(OpSdsAdmin); err != nil {
+		return "", err
+	}
 
 	sdsParam := &types.SdsParam{
 		Name:               name,
@@ -72,7 +77,7 @@ func (pd *ProtectionDomain) CreateSds(
 	path := fmt.Sprintf("/api/types/Sds/instances")
 
 	sds := types.SdsResp{}
-	err := pd.client.getJSONWithRetry(
+	err = c.getJSONWithRetry(
 		http.MethodPost, path, sdsParam, &sds)
 	if err != nil {
 		return "", err
@@ -82,14 +87,15 @@ func (pd *ProtectionDomain) CreateSds(
 }
 
 // GetSds returns a Sds
-func (pd *ProtectionDomain) GetSds() ([]types.Sds, error) {
-	defer TimeSpent("GetSds", time.Now())
+func (pd *ProtectionDomain) GetSds() (_ []types.Sds, err error) {
+	c, end := pd.client.trace("ProtectionDomain.GetSds")
+	defer end(&err)
 
 	path := fmt.Sprintf("/api/instances/ProtectionDomain::%v/relationships/Sds",
 		pd.ProtectionDomain.ID)
 
 	var sdss []types.Sds
-	err := pd.client.getJSONWithRetry(
+	err = c.getJSONWithRetry(
 		http.MethodGet, path, nil, &sdss)
 	if err != nil {
 		return nil, err
@@ -98,23 +104,43 @@ func (pd *ProtectionDomain) GetSds() ([]types.Sds, error) {
 	return sdss, nil
 }
 
-// FindSds returns a Sds
-func (pd *ProtectionDomain) FindSds(
-	field, value string) (*types.Sds, error) {
-	defer TimeSpent("FindSds", time.Now())
+// FindSds returns the first Sds matching all of the supplied options
+func (pd *ProtectionDomain) FindSds(opts ...FindOption) (_ *types.Sds, err error) {
+	c, end := pd.client.trace("ProtectionDomain.FindSds")
+	defer end(&err)
+
+	sdss, err := pd.withClient(c).FindAllSds(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return sdss[0], nil
+}
+
+// FindAllSds returns every Sds matching all of the supplied options
+func (pd *ProtectionDomain) FindAllSds(
+	opts ...FindOption) (_ []*types.Sds, err error) {
+	c, end := pd.client.trace("ProtectionDomain.FindAllSds")
+	defer end(&err)
+
+	if err := sdsFinder.validate(opts); err != nil {
+		return nil, err
+	}
 
-	sdss, err := pd.GetSds()
+	sdss, err := pd.withClient(c).GetSds()
 	if err != nil {
 		return nil, err
 	}
 
-	for _, sds := range sdss {
-		valueOf := reflect.ValueOf(sds)
-		switch {
-		case reflect.Indirect(valueOf).FieldByName(field).String() == value:
-			return &sds, nil
-		}
+	matches := sdsFinder.match(sdss, opts)
+	if len(matches) == 0 {
+		return nil, errors.New("Couldn't find SDS")
+	}
+
+	found := make([]*types.Sds, 0, len(matches))
+	for _, i := range matches {
+		found = append(found, &sdss[i])
 	}
 
-	return nil, errors.New("Couldn't find SDS")
+	return found, nil
 }
@@ -0,0 +1,282 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goscaleio
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	types "github.com/AnshumanPradipPatil1506/goscaleio/types/v1"
+)
+
+// Roles of an Sdt IP
+const (
+	// SdtIPRoleStorageOnly serves only SDS traffic
+	SdtIPRoleStorageOnly = "StorageOnly"
+	// SdtIPRoleHostOnly serves only NVMe host traffic
+	SdtIPRoleHostOnly = "HostOnly"
+	// SdtIPRoleStorageAndHost serves both
+	SdtIPRoleStorageAndHost = "StorageAndHost"
+)
+
+// Sdt defines struct for Sdt
+type Sdt struct {
+	Sdt    *types.Sdt
+	client *Client
+}
+
+// NewSdt returns a new Sdt
+func NewSdt(client *Client) *Sdt {
+	return &Sdt{
+		Sdt:    &types.Sdt{},
+		client: client,
+	}
+}
+
+// NewSdtEx returns a new SdtEx
+func NewSdtEx(client *Client, sdt *types.Sdt) *Sdt {
+	return &Sdt{
+		Sdt:    sdt,
+		client: client,
+	}
+}
+
+// CreateSdt creates a new Sdt in the protection domain. sdtParam is not
+// modified.
+func (pd *ProtectionDomain) CreateSdt(sdtParam *types.SdtParam) (_ string, err error) {
+	c, end := pd.client.trace("ProtectionDomain.CreateSdt")
+	defer end(&err)
+
+	c.audit("Sdt", "")
+
+	if err := c.checkMinVersion("SDTs", nvmeMinVersion); err != nil {
+		return "", err
+	}
+	if err := c.checkCapability(OpSdsAdmin); err != nil {
+		return "", err
+	}
+
+	if len(sdtParam.IPList) == 0 {
+		return "", errors.New("Must provide at least 1 SDT IP")
+	}
+	param := *sdtParam
+	param.ProtectionDomainID = pd.ProtectionDomain.ID
+
+	path := "/api/types/Sdt/instances"
+
+	sdt := types.SdtResp{}
+	err = c.getJSONWithRetry(
+		http.MethodPost, path, &param, &sdt)
+	if err != nil {
+		return "", err
+	}
+
+	return sdt.ID, nil
+}
+
+// GetSdt returns the Sdts of the protection domain
+func (pd *ProtectionDomain) GetSdt() (_ []types.Sdt, err error) {
+	c, end := pd.client.trace("ProtectionDomain.GetSdt")
+	defer end(&err)
+
+	if err := c.checkMinVersion("SDTs", nvmeMinVersion); err != nil {
+		return nil, err
+	}
+
+	path := fmt.Sprintf("/api/instances/ProtectionDomain::%v/relationships/Sdt",
+		pd.ProtectionDomain.ID)
+
+	var sdts []types.Sdt
+	err = c.getJSONWithRetry(
+		http.MethodGet, path, nil, &sdts)
+	if err != nil {
+		return nil, err
+	}
+
+	return sdts, nil
+}
+
+// FindSdt returns the first Sdt of the protection domain matching all of the
+// supplied options
+func (pd *ProtectionDomain) FindSdt(opts ...FindOption) (_ *types.Sdt, err error) {
+	c, end := pd.client.trace("ProtectionDomain.FindSdt")
+	defer end(&err)
+
+	sdts, err := pd.withClient(c).FindAllSdt(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return sdts[0], nil
+}
+
+// FindAllSdt returns every Sdt of the protection domain matching all of the
+// supplied options
+func (pd *ProtectionDomain) FindAllSdt(opts ...FindOption) (_ []*types.Sdt, err error) {
+	c, end := pd.client.trace("ProtectionDomain.FindAllSdt")
+	defer end(&err)
+
+	if err := sdtFinder.validate(opts); err != nil {
+		return nil, err
+	}
+
+	sdts, err := pd.withClient(c).GetSdt()
+	if err != nil {
+		return nil, err
+	}
+
+	matches := sdtFinder.match(sdts, opts)
+	if len(matches) == 0 {
+		return nil, errors.New("Couldn't find SDT")
+	}
+
+	found := make([]*types.Sdt, 0, len(matches))
+	for _, i := range matches {
+		found = append(found, &sdts[i])
+	}
+
+	return found, nil
+}
+
+// RemoveSdt removes the Sdt with the given ID from the protection domain.
+// It fails without removing anything if the Sdt is not in the protection
+// domain.
+func (pd *ProtectionDomain) RemoveSdt(id string) (err error) {
+	c, end := pd.client.trace("ProtectionDomain.RemoveSdt")
+	defer end(&err)
+
+	found, err := pd.withClient(c).FindSdt(ByID(id))
+	if err != nil {
+		return fmt.Errorf("SDT %s in protection domain %s: %v",
+			id, pd.ProtectionDomain.ID, err)
+	}
+
+	sdt := NewSdtEx(c, found)
+	return sdt.action(c, "removeSdt", types.EmptyPayload{})
+}
+
+// SetName renames the Sdt
+func (sdt *Sdt) SetName(name string) (err error) {
+	c, end := sdt.client.trace("Sdt.SetName")
+	defer end(&err)
+
+	return sdt.action(c, "renameSdt", types.SdtNameParam{NewName: name})
+}
+
+// AddIP adds an IP with the given role to the Sdt
+func (sdt *Sdt) AddIP(ip, role string) (err error) {
+	c, end := sdt.client.trace("Sdt.AddIP")
+	defer end(&err)
+
+	return sdt.action(c, "addIp", types.SdtIPParam{IP: ip, Role: role})
+}
+
+// RemoveIP removes an IP from the Sdt
+func (sdt *Sdt) RemoveIP(ip string) (err error) {
+	c, end := sdt.client.trace("Sdt.RemoveIP")
+	defer end(&err)
+
+	return sdt.action(c, "removeIp", types.SdtIPParam{IP: ip})
+}
+
+// SetIPRole changes the role of one of the Sdt's IPs
+func (sdt *Sdt) SetIPRole(ip, role string) (err error) {
+	c, end := sdt.client.trace("Sdt.SetIPRole")
+	defer end(&err)
+
+	return sdt.action(c, "modifyIpRole", types.SdtIPRoleParam{IP: ip, NewRole: role})
+}
+
+// SetStoragePort sets the port the Sdt uses to talk to SDSs
+func (sdt *Sdt) SetStoragePort(port int) (err error) {
+	c, end := sdt.client.trace("Sdt.SetStoragePort")
+	defer end(&err)
+
+	return sdt.action(c, "modifyStoragePort",
+		types.SdtStoragePortParam{NewStoragePort: fmt.Sprint(port)})
+}
+
+// SetNvmePort sets the port the Sdt serves NVMe over TCP I/O on
+func (sdt *Sdt) SetNvmePort(port int) (err error) {
+	c, end := sdt.client.trace("Sdt.SetNvmePort")
+	defer end(&err)
+
+	return sdt.action(c, "modifyNvmePort",
+		types.SdtNvmePortParam{NewNvmePort: fmt.Sprint(port)})
+}
+
+// SetDiscoveryPort sets the port the Sdt serves NVMe discovery on
+func (sdt *Sdt) SetDiscoveryPort(port int) (err error) {
+	c, end := sdt.client.trace("Sdt.SetDiscoveryPort")
+	defer end(&err)
+
+	return sdt.action(c, "modifyDiscoveryPort",
+		types.SdtDiscoveryPortParam{NewDiscoveryPort: fmt.Sprint(port)})
+}
+
+// EnterMaintenanceMode puts the Sdt in maintenance mode
+func (sdt *Sdt) EnterMaintenanceMode() (err error) {
+	c, end := sdt.client.trace("Sdt.EnterMaintenanceMode")
+	defer end(&err)
+
+	return sdt.action(c, "enterMaintenanceMode", types.EmptyPayload{})
+}
+
+// ExitMaintenanceMode takes the Sdt out of maintenance mode
+func (sdt *Sdt) ExitMaintenanceMode() (err error) {
+	c, end := sdt.client.trace("Sdt.ExitMaintenanceMode")
+	defer end(&err)
+
+	return sdt.action(c, "exitMaintenanceMode", types.EmptyPayload{})
+}
+
+// GetStatistics returns the Sdt's statistics
+func (sdt *Sdt) GetStatistics() (_ *types.SdtStatistics, err error) {
+	c, end := sdt.client.trace("Sdt.GetStatistics")
+	defer end(&err)
+
+	if err := c.checkMinVersion("SDTs", nvmeMinVersion); err != nil {
+		return nil, err
+	}
+
+	path := fmt.Sprintf("/api/instances/Sdt::%v/relationships/Statistics",
+		sdt.Sdt.ID)
+
+	var stats types.SdtStatistics
+	err = c.getJSONWithRetry(
+		http.MethodGet, path, nil, &stats)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
+
+// action posts an administrative action to the Sdt with c, the client of
+// the calling method
+func (sdt *Sdt) action(c *Client, name string, body interface{}) error {
+	c.audit("Sdt", sdt.Sdt.ID)
+
+	if err := c.checkMinVersion("SDTs", nvmeMinVersion); err != nil {
+		return err
+	}
+	if err := c.checkCapability(OpSdsAdmin); err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/api/instances/Sdt::%v/action/%s", sdt.Sdt.ID, name)
+
+	return c.getJSONWithRetry(
+		http.MethodPost, path, body, nil)
+}
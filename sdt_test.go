@@ -0,0 +1,210 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goscaleio
+
+import (
+	"testing"
+
+	types "github.com/AnshumanPradipPatil1506/goscaleio/types/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SdtManagement(t *testing.T) {
+	const list = "GET /api/instances/ProtectionDomain::pd1/relationships/Sdt"
+	const prefix = "POST /api/instances/Sdt::sdt3/action/"
+	responses := map[string]interface{}{
+		list: []types.Sdt{
+			{ID: "sdt1", Name: "target-a"},
+			{ID: "sdt2", Name: "target-b", IPList: []*types.SdtIP{{IP: "10.0.0.2", Role: SdtIPRoleStorageAndHost}}},
+		},
+		"POST /api/types/Sdt/instances":                         types.SdtResp{ID: "sdt3"},
+		"GET /api/instances/Sdt::sdt3/relationships/Statistics": types.SdtStatistics{NumOfHosts: 2, NumOfMappedVolumes: 5},
+		"POST /api/instances/Sdt::sdt2/action/removeSdt":        nil,
+	}
+	for _, action := range []string{
+		"renameSdt", "addIp", "modifyIpRole", "removeIp", "modifyStoragePort",
+		"modifyNvmePort", "modifyDiscoveryPort", "enterMaintenanceMode", "exitMaintenanceMode",
+	} {
+		responses[prefix+action] = nil
+	}
+
+	tests := map[string]struct {
+		call     func(t *testing.T, pd *ProtectionDomain, sdt *Sdt) error
+		isErr    bool
+		requests []string
+	}{
+		"create": {
+			call: func(t *testing.T, pd *ProtectionDomain, sdt *Sdt) error {
+				sdtParam := &types.SdtParam{
+					Name:   "target-c",
+					IPList: []*types.SdtIP{{IP: "10.0.0.3", Role: SdtIPRoleStorageAndHost}},
+				}
+				id, err := pd.CreateSdt(sdtParam)
+				if err == nil {
+					assert.Equal(t, "sdt3", id)
+				}
+				// the caller's parameters are left alone
+				assert.Empty(t, sdtParam.ProtectionDomainID)
+				return err
+			},
+			requests: []string{
+				`POST /api/types/Sdt/instances {"name":"target-c","ipList":[{"ip":"10.0.0.3","role":"StorageAndHost"}],"protectionDomainId":"pd1"}`,
+			},
+		},
+		"create without IPs": {
+			call: func(t *testing.T, pd *ProtectionDomain, sdt *Sdt) error {
+				_, err := pd.CreateSdt(&types.SdtParam{Name: "target-c"})
+				return err
+			},
+			isErr: true,
+		},
+		"find by name": {
+			call: func(t *testing.T, pd *ProtectionDomain, sdt *Sdt) error {
+				found, err := pd.FindSdt(ByName("target-b"))
+				if err == nil {
+					assert.Equal(t, "sdt2", found.ID)
+				}
+				return err
+			},
+			requests: []string{list},
+		},
+		"find by IP and name": {
+			call: func(t *testing.T, pd *ProtectionDomain, sdt *Sdt) error {
+				found, err := pd.FindSdt(ByIP("10.0.0.2"), ByName("target-b"))
+				if err == nil {
+					assert.Equal(t, "sdt2", found.ID)
+				}
+				return err
+			},
+			requests: []string{list},
+		},
+		"find unknown ID": {
+			call: func(t *testing.T, pd *ProtectionDomain, sdt *Sdt) error {
+				_, err := pd.FindSdt(ByID("sdt9"))
+				return err
+			},
+			isErr:    true,
+			requests: []string{list},
+		},
+		"find by IQN": {
+			call: func(t *testing.T, pd *ProtectionDomain, sdt *Sdt) error {
+				_, err := pd.FindSdt(ByIQN("iqn.1994-05.com.redhat:aaaa"))
+				assert.EqualError(t, err, "IQN lookup is not supported for SDT")
+				return err
+			},
+			isErr: true,
+		},
+		"rename": {
+			call: func(t *testing.T, pd *ProtectionDomain, sdt *Sdt) error {
+				return sdt.SetName("target-d")
+			},
+			requests: []string{prefix + `renameSdt {"newName":"target-d"}`},
+		},
+		"add IP": {
+			call: func(t *testing.T, pd *ProtectionDomain, sdt *Sdt) error {
+				return sdt.AddIP("10.0.1.3", SdtIPRoleHostOnly)
+			},
+			requests: []string{prefix + `addIp {"ip":"10.0.1.3","role":"HostOnly"}`},
+		},
+		"set IP role": {
+			call: func(t *testing.T, pd *ProtectionDomain, sdt *Sdt) error {
+				return sdt.SetIPRole("10.0.1.3", SdtIPRoleStorageOnly)
+			},
+			requests: []string{prefix + `modifyIpRole {"ip":"10.0.1.3","newRole":"StorageOnly"}`},
+		},
+		"remove IP": {
+			call: func(t *testing.T, pd *ProtectionDomain, sdt *Sdt) error {
+				return sdt.RemoveIP("10.0.1.3")
+			},
+			requests: []string{prefix + `removeIp {"ip":"10.0.1.3"}`},
+		},
+		"set storage port": {
+			call: func(t *testing.T, pd *ProtectionDomain, sdt *Sdt) error {
+				return sdt.SetStoragePort(12200)
+			},
+			requests: []string{prefix + `modifyStoragePort {"newStoragePort":"12200"}`},
+		},
+		"set NVMe port": {
+			call: func(t *testing.T, pd *ProtectionDomain, sdt *Sdt) error {
+				return sdt.SetNvmePort(4420)
+			},
+			requests: []string{prefix + `modifyNvmePort {"newNvmePort":"4420"}`},
+		},
+		"set discovery port": {
+			call: func(t *testing.T, pd *ProtectionDomain, sdt *Sdt) error {
+				return sdt.SetDiscoveryPort(8009)
+			},
+			requests: []string{prefix + `modifyDiscoveryPort {"newDiscoveryPort":"8009"}`},
+		},
+		"enter maintenance mode": {
+			call: func(t *testing.T, pd *ProtectionDomain, sdt *Sdt) error {
+				return sdt.EnterMaintenanceMode()
+			},
+			requests: []string{prefix + `enterMaintenanceMode {}`},
+		},
+		"exit maintenance mode": {
+			call: func(t *testing.T, pd *ProtectionDomain, sdt *Sdt) error {
+				return sdt.ExitMaintenanceMode()
+			},
+			requests: []string{prefix + `exitMaintenanceMode {}`},
+		},
+		"statistics": {
+			call: func(t *testing.T, pd *ProtectionDomain, sdt *Sdt) error {
+				stats, err := sdt.GetStatistics()
+				if err == nil {
+					assert.Equal(t, 5, stats.NumOfMappedVolumes)
+				}
+				return err
+			},
+			requests: []string{"GET /api/instances/Sdt::sdt3/relationships/Statistics"},
+		},
+		"remove": {
+			call: func(t *testing.T, pd *ProtectionDomain, sdt *Sdt) error {
+				return pd.RemoveSdt("sdt2")
+			},
+			requests: []string{list, `POST /api/instances/Sdt::sdt2/action/removeSdt {}`},
+		},
+		// only the SDTs of the protection domain are removed
+		"remove from another protection domain": {
+			call: func(t *testing.T, pd *ProtectionDomain, sdt *Sdt) error {
+				return pd.RemoveSdt("sdt9")
+			},
+			isErr:    true,
+			requests: []string{list},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			client, gateway := newTestGateway(t, "4.0", responses)
+			pd := NewProtectionDomainEx(client, &types.ProtectionDomain{ID: "pd1"})
+			sdt := NewSdtEx(client, &types.Sdt{ID: "sdt3"})
+
+			err := tt.call(t, pd, sdt)
+			assert.Equal(t, tt.isErr, err != nil, "error: %v", err)
+			assert.Equal(t, tt.requests, gateway.Requests())
+		})
+	}
+}
+
+func Test_SdtRequiresVersion(t *testing.T) {
+	client, err := NewClientWithArgs("https://gateway.invalid", "3.6", true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pd := NewProtectionDomainEx(client, &types.ProtectionDomain{ID: "pd1"})
+	_, err = pd.GetSdt()
+	assert.NotNil(t, err)
+	assert.NotNil(t, NewSdtEx(client, &types.Sdt{ID: "sdt1"}).EnterMaintenanceMode())
+	updateHeaders("")
+}
@@ -0,0 +1,226 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goscaleio
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	types "github.com/AnshumanPradipPatil1506/goscaleio/types/v1"
+)
+
+// Object types accepted by QuerySelectedStatistics
+const (
+	StatisticsTypeSystem           = "System"
+	StatisticsTypeProtectionDomain = "ProtectionDomain"
+	StatisticsTypeStoragePool      = "StoragePool"
+	StatisticsTypeVolume           = "Volume"
+	StatisticsTypeSdc              = "Sdc"
+	StatisticsTypeSds              = "Sds"
+	StatisticsTypeDevice           = "Device"
+)
+
+var errNoStatisticsQuery = errors.New("at least one statistics query is required")
+
+// StatisticsQuery selects statistics properties of objects of one type
+type StatisticsQuery struct {
+	// Type is the object type, one of the StatisticsType constants
+	Type string
+	// IDs are the objects to query, every object of the type if empty.
+	// It is ignored for StatisticsTypeSystem.
+	IDs []string
+	// Properties are the statistics properties to return, such as
+	// userDataReadBwc
+	Properties []string
+}
+
+// SelectedStatistics holds the result of QuerySelectedStatistics, keyed by
+// object type and ID
+type SelectedStatistics struct {
+	systemID string
+	objects  map[string]map[string]json.RawMessage
+
+	// properties caches the objects decoded by Properties
+	mu         sync.Mutex
+	properties map[string]map[string]map[string]json.RawMessage
+}
+
+// QuerySelectedStatistics fetches the selected statistics properties of
+// any number of objects in one request
+func (s *System) QuerySelectedStatistics(
+	queries ...StatisticsQuery) (_ *SelectedStatistics, err error) {
+	c, end := s.client.trace("System.QuerySelectedStatistics")
+	defer end(&err)
+
+	if len(queries) == 0 {
+		return nil, errNoStatisticsQuery
+	}
+
+	param := &types.QuerySelectedStatisticsParam{}
+	for _, q := range queries {
+		if q.Type == "" || len(q.Properties) == 0 {
+			return nil, fmt.Errorf("statistics query for %q requires a type and properties", q.Type)
+		}
+		sel := &types.SelectedStatisticsQuery{
+			Type:       q.Type,
+			Properties: q.Properties,
+		}
+		switch {
+		case q.Type == StatisticsTypeSystem:
+		case len(q.IDs) == 0:
+			all := ""
+			sel.AllIDs = &all
+		default:
+			sel.IDs = q.IDs
+		}
+		param.SelectedStatisticsList = append(param.SelectedStatisticsList, sel)
+	}
+
+	path := "/api/instances/querySelectedStatistics"
+
+	var resp map[string]json.RawMessage
+	err = c.getJSONWithRetry(
+		http.MethodPost, path, param, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &SelectedStatistics{
+		systemID: s.System.ID,
+		objects:  make(map[string]map[string]json.RawMessage, len(resp)),
+	}
+	for objectType, raw := range resp {
+		if objectType == StatisticsTypeSystem {
+			// system statistics are not keyed by ID
+			stats.objects[objectType] = map[string]json.RawMessage{s.System.ID: raw}
+			continue
+		}
+		var byID map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &byID); err != nil {
+			return nil, fmt.Errorf("statistics for %s: %v", objectType, err)
+		}
+		stats.objects[objectType] = byID
+	}
+
+	return stats, nil
+}
+
+// IDs returns the sorted IDs of the objects of a type in the result
+func (r *SelectedStatistics) IDs(objectType string) []string {
+	ids := make([]string, 0, len(r.objects[objectType]))
+	for id := range r.objects[objectType] {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Decode decodes the statistics of one object into out, such as a
+// *types.VolumeStatistics. Properties that were not selected are left unset.
+func (r *SelectedStatistics) Decode(objectType, id string, out interface{}) error {
+	raw, ok := r.objects[objectType][id]
+	if !ok {
+		return fmt.Errorf("no statistics for %s %s", objectType, id)
+	}
+	return json.Unmarshal(raw, out)
+}
+
+// Properties returns the raw values of the properties of one object by
+// property name. Each object is decoded once; the returned map is shared
+// and must not be modified.
+func (r *SelectedStatistics) Properties(objectType, id string) (map[string]json.RawMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if props, ok := r.properties[objectType][id]; ok {
+		return props, nil
+	}
+
+	var props map[string]json.RawMessage
+	if err := r.Decode(objectType, id, &props); err != nil {
+		return nil, err
+	}
+	if r.properties == nil {
+		r.properties = make(map[string]map[string]map[string]json.RawMessage)
+	}
+	if r.properties[objectType] == nil {
+		r.properties[objectType] = make(map[string]map[string]json.RawMessage)
+	}
+	r.properties[objectType][id] = props
+	return props, nil
+}
+
+// Property returns the raw value of one property of one object
+func (r *SelectedStatistics) Property(objectType, id, property string) (json.RawMessage, bool) {
+	props, err := r.Properties(objectType, id)
+	if err != nil {
+		return nil, false
+	}
+	value, ok := props[property]
+	return value, ok
+}
+
+// System returns the selected system statistics
+func (r *SelectedStatistics) System() (*types.Statistics, error) {
+	stats := &types.Statistics{}
+	if err := r.Decode(StatisticsTypeSystem, r.systemID, stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// ProtectionDomains returns the selected protection domain statistics by ID
+func (r *SelectedStatistics) ProtectionDomains() (map[string]*types.Statistics, error) {
+	return decodeAll[types.Statistics](r, StatisticsTypeProtectionDomain)
+}
+
+// StoragePools returns the selected storage pool statistics by ID
+func (r *SelectedStatistics) StoragePools() (map[string]*types.Statistics, error) {
+	return decodeAll[types.Statistics](r, StatisticsTypeStoragePool)
+}
+
+// Volumes returns the selected volume statistics by ID
+func (r *SelectedStatistics) Volumes() (map[string]*types.VolumeStatistics, error) {
+	return decodeAll[types.VolumeStatistics](r, StatisticsTypeVolume)
+}
+
+// Sdcs returns the selected SDC statistics by ID
+func (r *SelectedStatistics) Sdcs() (map[string]*types.SdcStatistics, error) {
+	return decodeAll[types.SdcStatistics](r, StatisticsTypeSdc)
+}
+
+// Sdss returns the selected SDS statistics by ID
+func (r *SelectedStatistics) Sdss() (map[string]*types.SdsStatistics, error) {
+	return decodeAll[types.SdsStatistics](r, StatisticsTypeSds)
+}
+
+// Devices returns the selected device statistics by ID
+func (r *SelectedStatistics) Devices() (map[string]*types.DeviceStatistics, error) {
+	return decodeAll[types.DeviceStatistics](r, StatisticsTypeDevice)
+}
+
+// decodeAll decodes the statistics of every object of a type into a T
+func decodeAll[T any](r *SelectedStatistics, objectType string) (map[string]*T, error) {
+	out := make(map[string]*T, len(r.objects[objectType]))
+	for _, id := range r.IDs(objectType) {
+		stats := new(T)
+		if err := r.Decode(objectType, id, stats); err != nil {
+			return nil, err
+		}
+		out[id] = stats
+	}
+	return out, nil
+}
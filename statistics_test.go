@@ -0,0 +1,117 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goscaleio
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_QuerySelectedStatistics(t *testing.T) {
+	var requests []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/instances/querySelectedStatistics" {
+			t.Fatalf("unexpected path: %s %s", r.Method, r.URL.Path)
+		}
+		requests = append(requests, strings.TrimSpace(string(testReadAll(t, r.Body))))
+		fmt.Fprint(w, `{
+			"System": {"numOfVolumes": 3},
+			"Volume": {
+				"v2": {"userDataReadBwc": {"totalWeightInKb": 20, "numOccured": 2, "numSeconds": 1}},
+				"v1": {"userDataReadBwc": {"totalWeightInKb": 10, "numOccured": 1, "numSeconds": 1}}
+			},
+			"Sdc": {"sdc1": {"numOfMappedVolumes": 2}},
+			"Sds": {"sds1": {"numOfDevices": 4, "capacityInUseInKb": 1024}},
+			"Device": {"dev1": {"avgReadLatencyInMicrosec": 250}},
+			"StoragePool": {"sp1": {"numOfVolumes": 3}}
+		}`)
+	}))
+	defer ts.Close()
+
+	client, err := NewClientWithArgs(ts.URL, "3.5", true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	system := NewSystem(client)
+	system.System.ID = "sys1"
+
+	_, err = system.QuerySelectedStatistics()
+	assert.Equal(t, errNoStatisticsQuery, err)
+	_, err = system.QuerySelectedStatistics(StatisticsQuery{Type: StatisticsTypeVolume})
+	assert.NotNil(t, err)
+
+	stats, err := system.QuerySelectedStatistics(
+		StatisticsQuery{Type: StatisticsTypeSystem, Properties: []string{"numOfVolumes"}},
+		StatisticsQuery{Type: StatisticsTypeVolume, Properties: []string{"userDataReadBwc"}},
+		StatisticsQuery{Type: StatisticsTypeSdc, IDs: []string{"sdc1"}, Properties: []string{"numOfMappedVolumes"}},
+		StatisticsQuery{Type: StatisticsTypeStoragePool, IDs: []string{"sp1"}, Properties: []string{"numOfVolumes"}},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{`{"selectedStatisticsList":[` +
+		`{"type":"System","properties":["numOfVolumes"]},` +
+		`{"type":"Volume","allIds":"","properties":["userDataReadBwc"]},` +
+		`{"type":"Sdc","ids":["sdc1"],"properties":["numOfMappedVolumes"]},` +
+		`{"type":"StoragePool","ids":["sp1"],"properties":["numOfVolumes"]}]}`}, requests)
+
+	sys, err := stats.System()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, sys.NumOfVolumes)
+
+	assert.Equal(t, []string{"v1", "v2"}, stats.IDs(StatisticsTypeVolume))
+	vols, err := stats.Volumes()
+	assert.Nil(t, err)
+	assert.Equal(t, 20, vols["v2"].UserDataReadBwc.TotalWeightInKb)
+
+	sdcs, err := stats.Sdcs()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, sdcs["sdc1"].NumOfMappedVolumes)
+
+	sdss, err := stats.Sdss()
+	assert.Nil(t, err)
+	assert.Equal(t, 4, sdss["sds1"].NumOfDevices)
+	assert.Equal(t, 1024, sdss["sds1"].CapacityInUseInKb)
+
+	devices, err := stats.Devices()
+	assert.Nil(t, err)
+	assert.Equal(t, 250, devices["dev1"].AvgReadLatencyInMicrosec)
+
+	pools, err := stats.StoragePools()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, pools["sp1"].NumOfVolumes)
+
+	pds, err := stats.ProtectionDomains()
+	assert.Nil(t, err)
+	assert.Len(t, pds, 0)
+
+	raw, ok := stats.Property(StatisticsTypeSdc, "sdc1", "numOfMappedVolumes")
+	assert.True(t, ok)
+	assert.Equal(t, "2", string(raw))
+	_, ok = stats.Property(StatisticsTypeSdc, "sdc2", "numOfMappedVolumes")
+	assert.False(t, ok)
+
+	// the properties of an object are decoded once
+	props, err := stats.Properties(StatisticsTypeSdc, "sdc1")
+	assert.Nil(t, err)
+	assert.Equal(t, "2", string(props["numOfMappedVolumes"]))
+	again, err := stats.Properties(StatisticsTypeSdc, "sdc1")
+	assert.Nil(t, err)
+	assert.Equal(t, reflect.ValueOf(props).Pointer(), reflect.ValueOf(again).Pointer())
+	_, err = stats.Properties(StatisticsTypeSdc, "sdc2")
+	assert.NotNil(t, err)
+}
@@ -42,8 +42,22 @@ func NewStoragePoolEx(client *Client, pool *types.StoragePool) *StoragePool {
 	}
 }
 
+// withClient returns a copy of the storage pool that makes its requests with c,
+// the client of a call that calls other methods of the storage pool
+func (sp *StoragePool) withClient(c *Client) *StoragePool {
+	return &StoragePool{StoragePool: sp.StoragePool, client: c}
+}
+
 // CreateStoragePool creates a storage pool
-func (pd *ProtectionDomain) CreateStoragePool(name string, mediaType string) (string, error) {
+func (pd *ProtectionDomain) CreateStoragePool(name string, mediaType string) (_ string, err error) {
+	c, end := pd.client.trace("ProtectionDomain.CreateStoragePool")
+	defer end(&err)
+
+	c.audit("StoragePool", "")
+
+	if err := c.checkCapability(OpStorageAdmin); err != nil {
+		return "", err
+	}
 
 	if mediaType == "" {
 		mediaType = "HDD"
@@ -57,7 +71,7 @@ func (pd *ProtectionDomain) CreateStoragePool(name string, mediaType string) (st
 	path := fmt.Sprintf("/api/types/StoragePool/instances")
 
 	sp := types.StoragePoolResp{}
-	err := pd.client.getJSONWithRetry(
+	err = c.getJSONWithRetry(
 		http.MethodPost, path, storagePoolParam, &sp)
 	if err != nil {
 		return "", err
@@ -67,12 +81,20 @@ func (pd *ProtectionDomain) CreateStoragePool(name string, mediaType string) (st
 }
 
 // DeleteStoragePool will delete a storage pool
-func (pd *ProtectionDomain) DeleteStoragePool(name string) error {
+func (pd *ProtectionDomain) DeleteStoragePool(name string) (err error) {
+	c, end := pd.client.trace("ProtectionDomain.DeleteStoragePool")
+	defer end(&err)
+
+	if err := c.checkCapability(OpStorageAdmin); err != nil {
+		return err
+	}
+
 	// get the storage pool name
-	pool, err := pd.client.FindStoragePool("", name, "", "")
+	pool, err := c.FindStoragePool("", name, "", "")
 	if err != nil {
 		return err
 	}
+	c.audit("StoragePool", pool.ID)
 
 	link, err := GetLink(pool.Links, "self")
 	if err != nil {
@@ -83,7 +105,7 @@ func (pd *ProtectionDomain) DeleteStoragePool(name string) error {
 
 	path := fmt.Sprintf("%v/action/removeStoragePool", link.HREF)
 
-	err = pd.client.getJSONWithRetry(
+	err = c.getJSONWithRetry(
 		http.MethodPost, path, storagePoolParam, nil)
 	if err != nil {
 		return err
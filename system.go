@@ -13,9 +13,9 @@
 package goscaleio
 
 import (
+	"context"
 	"fmt"
 	"net/http"
-	"time"
 
 	types "github.com/AnshumanPradipPatil1506/goscaleio/types/v1"
 )
@@ -34,9 +34,22 @@ func NewSystem(client *Client) *System {
 	}
 }
 
+// WithContext returns a copy of the system whose calls use ctx, like the
+// calls of Client.WithContext
+func (s *System) WithContext(ctx context.Context) *System {
+	return s.withClient(s.client.WithContext(ctx))
+}
+
+// withClient returns a copy of the system that makes its requests with c,
+// the client of a call that calls other methods of the system
+func (s *System) withClient(c *Client) *System {
+	return &System{System: s.System, client: c}
+}
+
 // GetSystems returns systems
-func (c *Client) GetSystems() ([]*types.System, error) {
-	defer TimeSpent("GetSystems", time.Now())
+func (c *Client) GetSystems() (_ []*types.System, err error) {
+	c, end := c.trace("Client.GetSystems")
+	defer end(&err)
 
 	systems, err := c.GetInstance("")
 	if err != nil {
@@ -47,8 +60,9 @@ func (c *Client) GetSystems() ([]*types.System, error) {
 
 // FindSystem returns a system based on ID or name
 func (c *Client) FindSystem(
-	instanceID, name, href string) (*System, error) {
-	defer TimeSpent("FindSystem", time.Now())
+	instanceID, name, href string) (_ *System, err error) {
+	c, end := c.trace("Client.FindSystem")
+	defer end(&err)
 
 	systems, err := c.GetInstance(href)
 	if err != nil {
@@ -57,7 +71,7 @@ func (c *Client) FindSystem(
 
 	for _, system := range systems {
 		if system.ID == instanceID || system.Name == name || href != "" {
-			outSystem := NewSystem(c)
+			outSystem := NewSystem(c.base())
 			outSystem.System = system
 			return outSystem, nil
 		}
@@ -66,8 +80,9 @@ func (c *Client) FindSystem(
 }
 
 // GetStatistics returns system statistics
-func (s *System) GetStatistics() (*types.Statistics, error) {
-	defer TimeSpent("GetStatistics", time.Now())
+func (s *System) GetStatistics() (_ *types.Statistics, err error) {
+	c, end := s.client.trace("System.GetStatistics")
+	defer end(&err)
 
 	link, err := GetLink(s.System.Links,
 		"/api/System/relationship/Statistics")
@@ -76,7 +91,7 @@ func (s *System) GetStatistics() (*types.Statistics, error) {
 	}
 
 	stats := types.Statistics{}
-	err = s.client.getJSONWithRetry(
+	err = c.getJSONWithRetry(
 		http.MethodGet, link.HREF, nil, &stats)
 	if err != nil {
 		return nil, err
@@ -87,8 +102,15 @@ func (s *System) GetStatistics() (*types.Statistics, error) {
 
 // CreateSnapshotConsistencyGroup creates a snapshot consistency group
 func (s *System) CreateSnapshotConsistencyGroup(
-	snapshotVolumesParam *types.SnapshotVolumesParam) (*types.SnapshotVolumesResp, error) {
-	defer TimeSpent("CreateSnapshotConsistencyGroup", time.Now())
+	snapshotVolumesParam *types.SnapshotVolumesParam) (_ *types.SnapshotVolumesResp, err error) {
+	c, end := s.client.trace("System.CreateSnapshotConsistencyGroup")
+	defer end(&err)
+
+	c.audit("System", s.System.ID)
+
+	if err := c.checkCapability(OpVolumeCreate); err != nil {
+		return nil, err
+	}
 
 	link, err := GetLink(s.System.Links, "self")
 	if err != nil {
@@ -98,7 +120,7 @@ func (s *System) CreateSnapshotConsistencyGroup(
 	path := fmt.Sprintf("%v/action/snapshotVolumes", link.HREF)
 
 	snapResp := types.SnapshotVolumesResp{}
-	err = s.client.getJSONWithRetry(
+	err = c.getJSONWithRetry(
 		http.MethodPost, path, snapshotVolumesParam, &snapResp)
 	if err != nil {
 		return nil, err
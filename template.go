@@ -2,7 +2,6 @@ package goscaleio
 
 import (
 	"net/http"
-	"time"
 
 	template "github.com/AnshumanPradipPatil1506/goscaleio/types/v1/template"
 )
@@ -25,8 +24,15 @@ func (c *Client) CreateTemplate() *Client {
 
 // CreateTemplate creates a blank template
 func (c *Client) FromModel(
-	templateParameters template.DefaultTemplate) (interface{}, error) {
-	defer TimeSpent("CreateTemplate", time.Now())
+	templateParameters template.DefaultTemplate) (_ interface{}, err error) {
+	c, end := c.trace("Client.FromModel")
+	defer end(&err)
+
+	c.audit("ServiceTemplate", "")
+
+	if err := c.checkCapability(OpTemplateAdmin); err != nil {
+		return nil, err
+	}
 
 	path := "api/v1/ServiceTemplate"
 
@@ -39,8 +45,15 @@ func (c *Client) FromModel(
 	return backResponse, nil
 }
 func (c *Client) FromString(
-	templateString string) (interface{}, error) {
-	defer TimeSpent("CreateTemplate", time.Now())
+	templateString string) (_ interface{}, err error) {
+	c, end := c.trace("Client.FromString")
+	defer end(&err)
+
+	c.audit("ServiceTemplate", "")
+
+	if err := c.checkCapability(OpTemplateAdmin); err != nil {
+		return nil, err
+	}
 
 	path := "api/v1/ServiceTemplate"
 	backResponse, err := c.authorizedJSONWithRetry(
@@ -52,8 +65,15 @@ func (c *Client) FromString(
 	return backResponse, nil
 }
 func (c *Client) UpdateTemplate(
-	templateString, templateID string) (interface{}, error) {
-	defer TimeSpent("CreateTemplate", time.Now())
+	templateString, templateID string) (_ interface{}, err error) {
+	c, end := c.trace("Client.UpdateTemplate")
+	defer end(&err)
+
+	c.audit("ServiceTemplate", templateID)
+
+	if err := c.checkCapability(OpTemplateAdmin); err != nil {
+		return nil, err
+	}
 
 	path := "/api/v1/ServiceTemplate/" + templateID
 	backResponse, err := c.authorizedJSONWithRetry(
@@ -65,8 +85,9 @@ func (c *Client) UpdateTemplate(
 	return backResponse, nil
 }
 func (c *Client) GetTemplate(
-	templateName string) (interface{}, error) {
-	defer TimeSpent("GetTemplate", time.Now())
+	templateName string) (_ interface{}, err error) {
+	c, end := c.trace("Client.GetTemplate")
+	defer end(&err)
 
 	path := "/api/v1/ServiceTemplate?filter=eq,name," + templateName
 
@@ -79,9 +100,16 @@ func (c *Client) GetTemplate(
 	return response, nil
 }
 func (c *Client) DeleteTemplate(
-	templateId string) (interface{}, error) {
+	templateId string) (_ interface{}, err error) {
 
-	defer TimeSpent("DeleteTemplate", time.Now())
+	c, end := c.trace("Client.DeleteTemplate")
+	defer end(&err)
+
+	c.audit("ServiceTemplate", templateId)
+
+	if err := c.checkCapability(OpTemplateAdmin); err != nil {
+		return nil, err
+	}
 
 	path := "/api/v1/ServiceTemplate/" + templateId
 
@@ -16,6 +16,7 @@ import (
 	"fmt"
 	"net/http"
 	"sync"
+	"time"
 )
 
 const errorWithDetails = "Error with details"
@@ -102,6 +103,31 @@ type BWC struct {
 	NumSeconds      int `json:"numSeconds"`
 }
 
+// IOPS returns the average number of operations per second over the window
+func (b BWC) IOPS() float64 {
+	if b.NumSeconds == 0 {
+		return 0
+	}
+	return float64(b.NumOccured) / float64(b.NumSeconds)
+}
+
+// BandwidthBytesPerSecond returns the average bandwidth over the window
+func (b BWC) BandwidthBytesPerSecond() float64 {
+	if b.NumSeconds == 0 {
+		return 0
+	}
+	return float64(b.TotalWeightInKb) * 1024 / float64(b.NumSeconds)
+}
+
+// AverageLatency returns the average latency of a latency counter, whose
+// weight is the total latency in microseconds
+func (b BWC) AverageLatency() time.Duration {
+	if b.NumOccured == 0 {
+		return 0
+	}
+	return time.Duration(b.TotalWeightInKb) * time.Microsecond / time.Duration(b.NumOccured)
+}
+
 // Statistics defines struct of Statistics for Pflex Array
 type Statistics struct {
 	PrimaryReadFromDevBwc                    BWC `json:"primaryReadFromDevBwc"`
@@ -208,6 +234,44 @@ type VolumeStatistics struct {
 	NumOfMappedSdcs         int      `json:"numOfMappedSdcs"`
 }
 
+// SdsStatistics defines struct of Statistics for PFlex SDS
+type SdsStatistics struct {
+	PrimaryReadBwc              BWC      `json:"primaryReadBwc"`
+	PrimaryWriteBwc             BWC      `json:"primaryWriteBwc"`
+	SecondaryReadBwc            BWC      `json:"secondaryReadBwc"`
+	SecondaryWriteBwc           BWC      `json:"secondaryWriteBwc"`
+	TotalReadBwc                BWC      `json:"totalReadBwc"`
+	TotalWriteBwc               BWC      `json:"totalWriteBwc"`
+	CapacityInUseInKb           int      `json:"capacityInUseInKb"`
+	MaxCapacityInKb             int      `json:"maxCapacityInKb"`
+	UnusedCapacityInKb          int      `json:"unusedCapacityInKb"`
+	SnapCapacityInUseInKb       int      `json:"snapCapacityInUseInKb"`
+	ThickCapacityInUseInKb      int      `json:"thickCapacityInUseInKb"`
+	ThinCapacityInUseInKb       int      `json:"thinCapacityInUseInKb"`
+	FailedCapacityInKb          int      `json:"failedCapacityInKb"`
+	DegradedHealthyCapacityInKb int      `json:"degradedHealthyCapacityInKb"`
+	NumOfDevices                int      `json:"numOfDevices"`
+	DeviceIds                   []string `json:"deviceIds"`
+}
+
+// DeviceStatistics defines struct of Statistics for PFlex device
+type DeviceStatistics struct {
+	PrimaryReadBwc            BWC `json:"primaryReadBwc"`
+	PrimaryWriteBwc           BWC `json:"primaryWriteBwc"`
+	SecondaryReadBwc          BWC `json:"secondaryReadBwc"`
+	SecondaryWriteBwc         BWC `json:"secondaryWriteBwc"`
+	TotalReadBwc              BWC `json:"totalReadBwc"`
+	TotalWriteBwc             BWC `json:"totalWriteBwc"`
+	AvgReadSizeInBytes        int `json:"avgReadSizeInBytes"`
+	AvgWriteSizeInBytes       int `json:"avgWriteSizeInBytes"`
+	AvgReadLatencyInMicrosec  int `json:"avgReadLatencyInMicrosec"`
+	AvgWriteLatencyInMicrosec int `json:"avgWriteLatencyInMicrosec"`
+	CapacityInUseInKb         int `json:"capacityInUseInKb"`
+	MaxCapacityInKb           int `json:"maxCapacityInKb"`
+	UnusedCapacityInKb        int `json:"unusedCapacityInKb"`
+	FailedCapacityInKb        int `json:"failedCapacityInKb"`
+}
+
 // User defines struct of User for PFlex array
 type User struct {
 	SystemID              string  `json:"systemId"`
@@ -218,14 +282,174 @@ type User struct {
 	Links                 []*Link `json:"links"`
 }
 
+// Role returns the role of the user
+func (u *User) Role() UserRole {
+	return UserRole(u.UserRole)
+}
+
+// UserRole defines the role of a PFlex user
+type UserRole string
+
+// Roles a PFlex user can have
+const (
+	UserRoleMonitor         UserRole = "Monitor"
+	UserRoleConfigure       UserRole = "Configure"
+	UserRoleAdministrator   UserRole = "Administrator"
+	UserRoleSecurity        UserRole = "Security"
+	UserRoleFrontendConfig  UserRole = "FrontendConfig"
+	UserRoleBackendConfig   UserRole = "BackendConfig"
+	UserRoleSuperUser       UserRole = "SuperUser"
+	UserRoleDeployment      UserRole = "Deployment"
+	UserRoleSystemAdminSite UserRole = "SystemAdminSite"
+)
+
+// UserRoles lists the roles a PFlex user can have
+var UserRoles = []UserRole{
+	UserRoleMonitor,
+	UserRoleConfigure,
+	UserRoleAdministrator,
+	UserRoleSecurity,
+	UserRoleFrontendConfig,
+	UserRoleBackendConfig,
+	UserRoleSuperUser,
+	UserRoleDeployment,
+	UserRoleSystemAdminSite,
+}
+
+// Valid reports whether the role is one of UserRoles
+func (r UserRole) Valid() bool {
+	for _, role := range UserRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// UserParam defines struct for UserParam
+type UserParam struct {
+	Name     string   `json:"name"`
+	UserRole UserRole `json:"userRole"`
+	Password string   `json:"password,omitempty"`
+}
+
+// UserResp defines struct for UserResp
+type UserResp struct {
+	ID string `json:"id"`
+}
+
+// UserRoleParam defines struct for UserRoleParam
+type UserRoleParam struct {
+	UserRole UserRole `json:"userRole"`
+}
+
+// ResetUserPasswordParam defines struct for ResetUserPasswordParam
+type ResetUserPasswordParam struct {
+	Password string `json:"password"`
+}
+
+// UserQueryIDByKeyParam defines struct for UserQueryIDByKeyParam
+type UserQueryIDByKeyParam struct {
+	Name string `json:"name"`
+}
+
+// ChangeUserPasswordParam defines struct for ChangeUserPasswordParam
+type ChangeUserPasswordParam struct {
+	OldPassword string `json:"oldPassword"`
+	NewPassword string `json:"newPassword"`
+}
+
 // ScsiInitiator defines struct for ScsiInitiator
 type ScsiInitiator struct {
+	ID       string  `json:"id"`
 	Name     string  `json:"name"`
 	IQN      string  `json:"iqn"`
 	SystemID string  `json:"systemID"`
 	Links    []*Link `json:"links"`
 }
 
+// ScsiInitiatorParam defines struct for ScsiInitiatorParam
+type ScsiInitiatorParam struct {
+	Name string `json:"name,omitempty"`
+	IQN  string `json:"iqn"`
+}
+
+// ScsiInitiatorResp defines struct for ScsiInitiatorResp
+type ScsiInitiatorResp struct {
+	ID string `json:"id"`
+}
+
+// SetScsiInitiatorNameParam defines struct for SetScsiInitiatorNameParam
+type SetScsiInitiatorNameParam struct {
+	NewName string `json:"newName"`
+}
+
+// MappedScsiInitiatorInfo defines struct for MappedScsiInitiatorInfo
+type MappedScsiInitiatorInfo struct {
+	ScsiInitiatorID   string `json:"scsiInitiatorId"`
+	ScsiInitiatorName string `json:"scsiInitiatorName"`
+	ScsiInitiatorIQN  string `json:"scsiInitiatorIqn"`
+	Lun               int    `json:"lun"`
+}
+
+// MapVolumeScsiInitiatorParam defines struct for MapVolumeScsiInitiatorParam
+type MapVolumeScsiInitiatorParam struct {
+	ScsiInitiatorID string `json:"scsiInitiatorId"`
+	Lun             string `json:"lun,omitempty"`
+}
+
+// UnmapVolumeScsiInitiatorParam defines struct for UnmapVolumeScsiInitiatorParam
+type UnmapVolumeScsiInitiatorParam struct {
+	ScsiInitiatorID string `json:"scsiInitiatorId"`
+}
+
+// NvmeHost defines struct for an NVMe over TCP host
+type NvmeHost struct {
+	ID             string  `json:"id"`
+	Name           string  `json:"name"`
+	Nqn            string  `json:"nqn"`
+	HostType       string  `json:"hostType"`
+	MaxNumPaths    int     `json:"maxNumPaths"`
+	MaxNumSysPorts int     `json:"maxNumSysPorts"`
+	SystemID       string  `json:"systemId"`
+	Links          []*Link `json:"links"`
+}
+
+// NvmeHostParam defines struct for NvmeHostParam
+type NvmeHostParam struct {
+	Name           string `json:"name,omitempty"`
+	Nqn            string `json:"nqn"`
+	MaxNumPaths    string `json:"maxNumPaths,omitempty"`
+	MaxNumSysPorts string `json:"maxNumSysPorts,omitempty"`
+}
+
+// NvmeHostResp defines struct for NvmeHostResp
+type NvmeHostResp struct {
+	ID string `json:"id"`
+}
+
+// SetNvmeHostMaxNumPathsParam defines struct for SetNvmeHostMaxNumPathsParam
+type SetNvmeHostMaxNumPathsParam struct {
+	MaxNumPaths string `json:"maxNumPaths"`
+}
+
+// SetNvmeHostMaxNumSysPortsParam defines struct for SetNvmeHostMaxNumSysPortsParam
+type SetNvmeHostMaxNumSysPortsParam struct {
+	MaxNumSysPorts string `json:"maxNumSysPorts"`
+}
+
+// MapVolumeNvmeHostParam defines struct for MapVolumeNvmeHostParam
+type MapVolumeNvmeHostParam struct {
+	HostID                string `json:"hostId"`
+	AccessMode            string `json:"accessMode,omitempty"`
+	AllowMultipleMappings string `json:"allowMultipleMappings,omitempty"`
+}
+
+// UnmapVolumeNvmeHostParam defines struct for UnmapVolumeNvmeHostParam
+type UnmapVolumeNvmeHostParam struct {
+	HostID string `json:"hostId"`
+}
+
 // ProtectionDomain defines struct for PFlex ProtectionDomain
 type ProtectionDomain struct {
 	SystemID                          string  `json:"systemId"`
@@ -300,6 +524,90 @@ type Sds struct {
 	RmcacheMemoryAllocationState string       `json:"RmcacheMemoryAllocationState,omitempty"`
 }
 
+// SdtIP defines struct for SdtIP
+type SdtIP struct {
+	IP   string `json:"ip"`
+	Role string `json:"role"`
+}
+
+// Sdt defines struct for Sdt
+type Sdt struct {
+	ID                 string   `json:"id"`
+	Name               string   `json:"name,omitempty"`
+	ProtectionDomainID string   `json:"protectionDomainId"`
+	IPList             []*SdtIP `json:"ipList"`
+	StoragePort        int      `json:"storagePort,omitempty"`
+	NvmePort           int      `json:"nvmePort,omitempty"`
+	DiscoveryPort      int      `json:"discoveryPort,omitempty"`
+	SdtState           string   `json:"sdtState"`
+	MembershipState    string   `json:"membershipState"`
+	MdmConnectionState string   `json:"mdmConnectionState"`
+	MaintenanceState   string   `json:"maintenanceState"`
+	FaultSetID         string   `json:"faultSetId,omitempty"`
+	SystemID           string   `json:"systemId"`
+	Links              []*Link  `json:"links"`
+}
+
+// SdtParam defines struct for SdtParam
+type SdtParam struct {
+	Name               string   `json:"name,omitempty"`
+	IPList             []*SdtIP `json:"ipList"`
+	StoragePort        int      `json:"storagePort,omitempty"`
+	NvmePort           int      `json:"nvmePort,omitempty"`
+	DiscoveryPort      int      `json:"discoveryPort,omitempty"`
+	ProtectionDomainID string   `json:"protectionDomainId"`
+}
+
+// SdtResp defines struct for SdtResp
+type SdtResp struct {
+	ID string `json:"id"`
+}
+
+// SdtIPParam defines struct for SdtIPParam
+type SdtIPParam struct {
+	IP   string `json:"ip"`
+	Role string `json:"role,omitempty"`
+}
+
+// SdtIPRoleParam defines struct for SdtIPRoleParam
+type SdtIPRoleParam struct {
+	IP      string `json:"ip"`
+	NewRole string `json:"newRole"`
+}
+
+// SdtNameParam defines struct for SdtNameParam
+type SdtNameParam struct {
+	NewName string `json:"newName"`
+}
+
+// SdtStoragePortParam defines struct for SdtStoragePortParam
+type SdtStoragePortParam struct {
+	NewStoragePort string `json:"newStoragePort"`
+}
+
+// SdtNvmePortParam defines struct for SdtNvmePortParam
+type SdtNvmePortParam struct {
+	NewNvmePort string `json:"newNvmePort"`
+}
+
+// SdtDiscoveryPortParam defines struct for SdtDiscoveryPortParam
+type SdtDiscoveryPortParam struct {
+	NewDiscoveryPort string `json:"newDiscoveryPort"`
+}
+
+// SdtStatistics defines struct of Statistics for PFlex Sdt
+type SdtStatistics struct {
+	NumOfHosts             int `json:"numOfHosts"`
+	NumOfMappedVolumes     int `json:"numOfMappedVolumes"`
+	NumOfTargetControllers int `json:"numOfTargetControllers"`
+	UserDataReadBwc        BWC `json:"userDataReadBwc"`
+	UserDataWriteBwc       BWC `json:"userDataWriteBwc"`
+	UserDataTrimBwc        BWC `json:"userDataTrimBwc"`
+	HostReadLatency        BWC `json:"hostReadLatency"`
+	HostWriteLatency       BWC `json:"hostWriteLatency"`
+	HostTrimLatency        BWC `json:"hostTrimLatency"`
+}
+
 // DeviceInfo defines struct for DeviceInfo
 type DeviceInfo struct {
 	DevicePath    string `json:"devicePath"`
@@ -415,21 +723,21 @@ type MappedSdcInfo struct {
 
 // Volume defines struct for Volume
 type Volume struct {
-	StoragePoolID           string           `json:"storagePoolId"`
-	UseRmCache              bool             `json:"useRmcache"`
-	MappingToAllSdcsEnabled bool             `json:"mappingToAllSdcsEnabled"`
-	MappedSdcInfo           []*MappedSdcInfo `json:"mappedSdcInfo"`
-	IsObfuscated            bool             `json:"isObfuscated"`
-	VolumeType              string           `json:"volumeType"`
-	ConsistencyGroupID      string           `json:"consistencyGroupId"`
-	VTreeID                 string           `json:"vtreeId"`
-	AncestorVolumeID        string           `json:"ancestorVolumeId"`
-	MappedScsiInitiatorInfo string           `json:"mappedScsiInitiatorInfo"`
-	SizeInKb                int              `json:"sizeInKb"`
-	CreationTime            int              `json:"creationTime"`
-	Name                    string           `json:"name"`
-	ID                      string           `json:"id"`
-	Links                   []*Link          `json:"links"`
+	StoragePoolID           string                     `json:"storagePoolId"`
+	UseRmCache              bool                       `json:"useRmcache"`
+	MappingToAllSdcsEnabled bool                       `json:"mappingToAllSdcsEnabled"`
+	MappedSdcInfo           []*MappedSdcInfo           `json:"mappedSdcInfo"`
+	IsObfuscated            bool                       `json:"isObfuscated"`
+	VolumeType              string                     `json:"volumeType"`
+	ConsistencyGroupID      string                     `json:"consistencyGroupId"`
+	VTreeID                 string                     `json:"vtreeId"`
+	AncestorVolumeID        string                     `json:"ancestorVolumeId"`
+	MappedScsiInitiatorInfo []*MappedScsiInitiatorInfo `json:"mappedScsiInitiatorInfo"`
+	SizeInKb                int                        `json:"sizeInKb"`
+	CreationTime            int                        `json:"creationTime"`
+	Name                    string                     `json:"name"`
+	ID                      string                     `json:"id"`
+	Links                   []*Link                    `json:"links"`
 }
 
 // VolumeParam defines struct for VolumeParam
@@ -532,3 +840,169 @@ type RemoveVolumeParam struct {
 // EmptyPayload defines struct for EmptyPayload
 type EmptyPayload struct {
 }
+
+// ReplicationConsistencyGroup defines struct for ReplicationConsistencyGroup
+type ReplicationConsistencyGroup struct {
+	ID                          string  `json:"id"`
+	Name                        string  `json:"name"`
+	RpoInSeconds                int     `json:"rpoInSeconds"`
+	ProtectionDomainID          string  `json:"protectionDomainId"`
+	RemoteProtectionDomainID    string  `json:"remoteProtectionDomainId"`
+	DestinationSystemID         string  `json:"destinationSystemId"`
+	PeerMdmID                   string  `json:"peerMdmId"`
+	RemoteID                    string  `json:"remoteId"`
+	RemoteMdmID                 string  `json:"remoteMdmId"`
+	ReplicationDirection        string  `json:"replicationDirection"`
+	CurrConsistMode             string  `json:"currConsistMode"`
+	FreezeState                 string  `json:"freezeState"`
+	PauseMode                   string  `json:"pauseMode"`
+	LifetimeState               string  `json:"lifetimeState"`
+	SnapCreationInProgress      bool    `json:"snapCreationInProgress"`
+	LastSnapGroupID             string  `json:"lastSnapGroupId"`
+	Type                        string  `json:"type"`
+	DisasterRecoveryState       string  `json:"disasterRecoveryState"`
+	RemoteDisasterRecoveryState string  `json:"remoteDisasterRecoveryState"`
+	TargetVolumeAccessMode      string  `json:"targetVolumeAccessMode"`
+	FailoverType                string  `json:"failoverType"`
+	FailoverState               string  `json:"failoverState"`
+	ActiveLocal                 bool    `json:"activeLocal"`
+	ActiveRemote                bool    `json:"activeRemote"`
+	AbstractState               string  `json:"abstractState"`
+	Error                       int     `json:"error"`
+	LocalActivityState          string  `json:"localActivityState"`
+	RemoteActivityState         string  `json:"remoteActivityState"`
+	InactiveReason              int     `json:"inactiveReason"`
+	Links                       []*Link `json:"links"`
+}
+
+// ReplicationConsistencyGroupParam defines struct for ReplicationConsistencyGroupParam
+type ReplicationConsistencyGroupParam struct {
+	Name                     string `json:"name,omitempty"`
+	RpoInSeconds             string `json:"rpoInSeconds"`
+	ProtectionDomainID       string `json:"protectionDomainId"`
+	RemoteProtectionDomainID string `json:"remoteProtectionDomainId"`
+	DestinationSystemID      string `json:"destinationSystemId,omitempty"`
+	PeerMdmID                string `json:"peerMdmId,omitempty"`
+}
+
+// ReplicationConsistencyGroupResp defines struct for ReplicationConsistencyGroupResp
+type ReplicationConsistencyGroupResp struct {
+	ID string `json:"id"`
+}
+
+// SetRpoParam defines struct for SetRpoParam
+type SetRpoParam struct {
+	RpoInSeconds string `json:"rpoInSeconds"`
+}
+
+// PauseReplicationConsistencyGroupParam defines struct for PauseReplicationConsistencyGroupParam
+type PauseReplicationConsistencyGroupParam struct {
+	PauseMode string `json:"pauseMode"`
+}
+
+// ReplicationPair defines struct for ReplicationPair
+type ReplicationPair struct {
+	ID                                 string  `json:"id"`
+	Name                               string  `json:"name"`
+	RemoteID                           string  `json:"remoteId"`
+	UserRequestedPauseTransmitInitCopy bool    `json:"userRequestedPauseTransmitInitCopy"`
+	RemoteCapacityInMB                 int     `json:"remoteCapacityInMB"`
+	LocalVolumeID                      string  `json:"localVolumeId"`
+	RemoteVolumeID                     string  `json:"remoteVolumeId"`
+	RemoteVolumeName                   string  `json:"remoteVolumeName"`
+	ReplicationConsistencyGroupID      string  `json:"replicationConsistencyGroupId"`
+	CopyType                           string  `json:"copyType"`
+	LifetimeState                      string  `json:"lifetimeState"`
+	PeerSystemName                     string  `json:"peerSystemName"`
+	InitialCopyState                   string  `json:"initialCopyState"`
+	InitialCopyPriority                int     `json:"initialCopyPriority"`
+	Links                              []*Link `json:"links"`
+}
+
+// ReplicationPairParam defines struct for ReplicationPairParam
+type ReplicationPairParam struct {
+	Name                          string `json:"name,omitempty"`
+	SourceVolumeID                string `json:"sourceVolumeId"`
+	DestinationVolumeID           string `json:"destinationVolumeId"`
+	ReplicationConsistencyGroupID string `json:"replicationConsistencyGroupId"`
+	CopyType                      string `json:"copyType"`
+}
+
+// ReplicationPairResp defines struct for ReplicationPairResp
+type ReplicationPairResp struct {
+	ID string `json:"id"`
+}
+
+// ReplicationConsistencyGroupStatistics defines struct of Statistics for PFlex ReplicationConsistencyGroup
+type ReplicationConsistencyGroupStatistics struct {
+	CurrentRpoInSeconds        int `json:"currentRpoInSeconds"`
+	NumOfReplicationPairs      int `json:"numOfReplicationPairs"`
+	ReplicationTransmitBwc     BWC `json:"replicationTransmitBwc"`
+	ReplicationReceiveBwc      BWC `json:"replicationReceiveBwc"`
+	PendingReplicationDataInKb int `json:"pendingReplicationDataInKb"`
+}
+
+// PeerMdmIP defines struct for PeerMdmIP
+type PeerMdmIP struct {
+	IP string `json:"ip"`
+}
+
+// PeerMdm defines struct for a replication peer system
+type PeerMdm struct {
+	ID                  string       `json:"id"`
+	Name                string       `json:"name"`
+	Port                int          `json:"port"`
+	PeerSystemID        string       `json:"peerSystemId"`
+	SystemID            string       `json:"systemId"`
+	SoftwareVersionInfo string       `json:"softwareVersionInfo"`
+	MembershipState     string       `json:"membershipState"`
+	PerfProfile         string       `json:"perfProfile"`
+	NetworkType         string       `json:"networkType"`
+	CouplingRC          string       `json:"couplingRC"`
+	IPList              []*PeerMdmIP `json:"ipList"`
+	Links               []*Link      `json:"links"`
+}
+
+// PeerMdmParam defines struct for PeerMdmParam
+type PeerMdmParam struct {
+	Name          string   `json:"name,omitempty"`
+	PeerSystemID  string   `json:"peerSystemId"`
+	PeerSystemIps []string `json:"peerSystemIps"`
+	Port          string   `json:"port,omitempty"`
+	// PeerSystemCertificate is the PEM encoded CA certificate of the peer
+	// system, registered as trusted when the peer is added
+	PeerSystemCertificate string `json:"peerSystemCertificate,omitempty"`
+}
+
+// PeerMdmResp defines struct for PeerMdmResp
+type PeerMdmResp struct {
+	ID string `json:"id"`
+}
+
+// ModifyPeerMdmIPParam defines struct for ModifyPeerMdmIPParam
+type ModifyPeerMdmIPParam struct {
+	NewPeerMdmIps []string `json:"newPeerMDMIps"`
+}
+
+// ModifyPeerMdmPortParam defines struct for ModifyPeerMdmPortParam
+type ModifyPeerMdmPortParam struct {
+	NewPort string `json:"newPort"`
+}
+
+// ModifyPeerMdmNameParam defines struct for ModifyPeerMdmNameParam
+type ModifyPeerMdmNameParam struct {
+	NewName string `json:"newName"`
+}
+
+// SelectedStatisticsQuery defines struct for one object type of a querySelectedStatistics request
+type SelectedStatisticsQuery struct {
+	Type       string   `json:"type"`
+	IDs        []string `json:"ids,omitempty"`
+	AllIDs     *string  `json:"allIds,omitempty"`
+	Properties []string `json:"properties"`
+}
+
+// QuerySelectedStatisticsParam defines struct for QuerySelectedStatisticsParam
+type QuerySelectedStatisticsParam struct {
+	SelectedStatisticsList []*SelectedStatisticsQuery `json:"selectedStatisticsList"`
+}
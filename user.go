@@ -15,20 +15,20 @@ package goscaleio
 import (
 	"fmt"
 	"net/http"
-	"time"
 
 	types "github.com/AnshumanPradipPatil1506/goscaleio/types/v1"
 )
 
 // GetUser returns user
-func (s *System) GetUser() ([]types.User, error) {
-	defer TimeSpent("GetUser", time.Now())
+func (s *System) GetUser() (_ []types.User, err error) {
+	c, end := s.client.trace("System.GetUser")
+	defer end(&err)
 
 	path := fmt.Sprintf("/api/instances/System::%v/relationships/User",
 		s.System.ID)
 
 	var user []types.User
-	err := s.client.getJSONWithRetry(
+	err = c.getJSONWithRetry(
 		http.MethodGet, path, nil, &user)
 	if err != nil {
 		return nil, err
@@ -36,3 +36,202 @@ func (s *System) GetUser() ([]types.User, error) {
 
 	return user, nil
 }
+
+// GetUserByID returns the user with the given ID
+func (s *System) GetUserByID(id string) (_ *types.User, err error) {
+	c, end := s.client.trace("System.GetUserByID")
+	defer end(&err)
+
+	path := fmt.Sprintf("/api/instances/User::%v", id)
+
+	var user types.User
+	err = c.getJSONWithRetry(
+		http.MethodGet, path, nil, &user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// FindUserID returns the ID of the user with the given name. Unlike
+// listing the users of the system, it does not need administrator rights.
+func (s *System) FindUserID(name string) (_ string, err error) {
+	c, end := s.client.trace("System.FindUserID")
+	defer end(&err)
+
+	path := "/api/types/User/instances/action/queryIdByKey"
+
+	body := &types.UserQueryIDByKeyParam{
+		Name: name,
+	}
+	return c.getStringWithRetry(
+		http.MethodPost, path, body)
+}
+
+// GetCurrentUser returns the user the client is authenticated as
+func (s *System) GetCurrentUser() (_ *types.User, err error) {
+	c, end := s.client.trace("System.GetCurrentUser")
+	defer end(&err)
+
+	id, err := s.withClient(c).currentUserID()
+	if err != nil {
+		return nil, err
+	}
+	return s.withClient(c).GetUserByID(id)
+}
+
+// currentUserID returns the ID of the user the client is authenticated as
+func (s *System) currentUserID() (string, error) {
+	username := s.client.auth.get().Username
+	if username == "" {
+		return "", errNotAuthenticated
+	}
+	return s.FindUserID(username)
+}
+
+// CreateUser creates a user with the given role and returns its ID. A user
+// created without a password, or whose password was set by an
+// administrator, must change it on first login.
+func (s *System) CreateUser(userParam *types.UserParam) (_ *types.UserResp, err error) {
+	c, end := s.client.trace("System.CreateUser")
+	defer end(&err)
+
+	c.audit("User", "")
+
+	if err := c.checkCapability(OpUserAdmin); err != nil {
+		return nil, err
+	}
+
+	if !userParam.UserRole.Valid() {
+		return nil, fmt.Errorf("invalid user role %q", userParam.UserRole)
+	}
+
+	path := "/api/types/User/instances"
+
+	var resp types.UserResp
+	err = c.getJSONWithRetry(
+		http.MethodPost, path, userParam, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// RemoveUser removes the user with the given ID
+func (s *System) RemoveUser(userID string) (err error) {
+	c, end := s.client.trace("System.RemoveUser")
+	defer end(&err)
+
+	c.audit("User", userID)
+
+	if err := c.checkCapability(OpUserAdmin); err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/api/instances/User::%v/action/removeUser", userID)
+
+	return c.getJSONWithRetry(
+		http.MethodPost, path, types.EmptyPayload{}, nil)
+}
+
+// SetUserRole changes the role of the user with the given ID
+func (s *System) SetUserRole(userID string, role types.UserRole) (err error) {
+	c, end := s.client.trace("System.SetUserRole")
+	defer end(&err)
+
+	c.audit("User", userID)
+
+	if err := c.checkCapability(OpUserAdmin); err != nil {
+		return err
+	}
+
+	if !role.Valid() {
+		return fmt.Errorf("invalid user role %q", role)
+	}
+
+	path := fmt.Sprintf("/api/instances/User::%v/action/setUserRole", userID)
+
+	body := types.UserRoleParam{
+		UserRole: role,
+	}
+	return c.getJSONWithRetry(
+		http.MethodPost, path, body, nil)
+}
+
+// ResetUserPassword sets a new password for the user with the given ID.
+// The user must change the password on their next login.
+func (s *System) ResetUserPassword(userID, password string) (err error) {
+	c, end := s.client.trace("System.ResetUserPassword")
+	defer end(&err)
+
+	c.audit("User", userID)
+
+	if err := c.checkCapability(OpUserAdmin); err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/api/instances/User::%v/action/resetPassword", userID)
+
+	body := types.ResetUserPasswordParam{
+		Password: password,
+	}
+	return c.getJSONWithRetry(
+		http.MethodPost, path, body, nil)
+}
+
+// ChangePassword changes the password of the current user, which clears
+// PasswordChangeRequire after a first login or a reset. The client is then
+// authenticated again with the new password.
+func (s *System) ChangePassword(oldPassword, newPassword string) (err error) {
+	c, end := s.client.trace("System.ChangePassword")
+	defer end(&err)
+
+	userID, err := s.withClient(c).currentUserID()
+	if err != nil {
+		return err
+	}
+	return s.withClient(c).ChangePasswordByID(userID, oldPassword, newPassword)
+}
+
+// ChangePasswordByID changes the password of the current user, whose ID is
+// userID, like ChangePassword but without looking the user up. Use it when
+// the user may not query users before changing the password.
+func (s *System) ChangePasswordByID(userID, oldPassword, newPassword string) (err error) {
+	c, end := s.client.trace("System.ChangePasswordByID")
+	defer end(&err)
+
+	c.audit("User", userID)
+
+	if c.auth.get().Username == "" {
+		return errNotAuthenticated
+	}
+
+	path := fmt.Sprintf("/api/instances/User::%v/action/setPassword", userID)
+
+	body := types.ChangeUserPasswordParam{
+		OldPassword: oldPassword,
+		NewPassword: newPassword,
+	}
+	err = c.getJSONWithRetry(
+		http.MethodPost, path, body, nil)
+	if err != nil {
+		return err
+	}
+
+	configConnect := c.auth.get()
+	configConnect.Password = newPassword
+	_, err = c.Authenticate(&configConnect)
+	return err
+}
+
+// PasswordChangeRequired reports whether the current user must change
+// their password before the system accepts other requests
+func (s *System) PasswordChangeRequired() (bool, error) {
+	user, err := s.GetCurrentUser()
+	if err != nil {
+		return false, err
+	}
+	return user.PasswordChangeRequire, nil
+}
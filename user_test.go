@@ -0,0 +1,156 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goscaleio
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	types "github.com/AnshumanPradipPatil1506/goscaleio/types/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_UserManagement(t *testing.T) {
+	var (
+		paths     []string
+		bodies    []map[string]string
+		passwords []string
+	)
+	users := []types.User{
+		{ID: "u1", Name: "admin", UserRole: "SuperUser", PasswordChangeRequire: true},
+		{ID: "u2", Name: "monitor", UserRole: "Monitor"},
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := fmt.Sprintf("%s %s", r.Method, r.URL.Path)
+		switch r.URL.Path {
+		case "/api/login":
+			_, pwd, _ := r.BasicAuth()
+			passwords = append(passwords, pwd)
+			fmt.Fprintf(w, `"fakesessiontoken"`)
+			return
+		case "/api/version":
+			fmt.Fprintf(w, `"3.5"`)
+			return
+		}
+		paths = append(paths, path)
+		if r.Method == http.MethodPost {
+			var body map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			bodies = append(bodies, body)
+		}
+
+		var resp interface{}
+		switch path {
+		case "GET /api/instances/System::sys1/relationships/User":
+			// the current user may not list users
+			w.WriteHeader(http.StatusForbidden)
+			resp = types.Error{Message: "Forbidden", HTTPStatusCode: http.StatusForbidden}
+		case "POST /api/types/User/instances/action/queryIdByKey":
+			resp = "u1"
+		case "GET /api/instances/User::u1":
+			resp = users[0]
+		case "POST /api/types/User/instances":
+			resp = types.UserResp{ID: "u3"}
+		case "POST /api/instances/User::u3/action/setUserRole",
+			"POST /api/instances/User::u3/action/resetPassword",
+			"POST /api/instances/User::u3/action/removeUser",
+			"POST /api/instances/User::u1/action/setPassword":
+		default:
+			t.Fatalf("unexpected path: %q", path)
+		}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Fatal(err)
+		}
+	}))
+	defer ts.Close()
+
+	client, err := NewClientWithArgs(ts.URL, "", true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	system := NewSystem(client)
+	system.System.ID = "sys1"
+
+	_, err = system.GetCurrentUser()
+	assert.Equal(t, errNotAuthenticated, err)
+
+	_, err = client.Authenticate(&ConfigConnect{Username: "admin", Password: "initial"})
+	assert.Nil(t, err)
+
+	user, err := system.GetCurrentUser()
+	assert.Nil(t, err)
+	assert.Equal(t, "u1", user.ID)
+	assert.Equal(t, types.UserRoleSuperUser, user.Role())
+
+	required, err := system.PasswordChangeRequired()
+	assert.Nil(t, err)
+	assert.True(t, required)
+
+	_, err = system.GetUser()
+	assert.NotNil(t, err)
+
+	assert.Nil(t, system.ChangePassword("initial", "changed"))
+	assert.Equal(t, []string{"initial", "changed"}, passwords)
+
+	// a known ID is not looked up
+	assert.Nil(t, system.ChangePasswordByID("u1", "changed", "again"))
+	assert.Equal(t, []string{"initial", "changed", "again"}, passwords)
+
+	resp, err := system.CreateUser(&types.UserParam{
+		Name:     "operator",
+		UserRole: types.UserRoleConfigure,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "u3", resp.ID)
+
+	assert.Nil(t, system.SetUserRole("u3", types.UserRoleMonitor))
+	assert.Nil(t, system.ResetUserPassword("u3", "temporary"))
+	assert.Nil(t, system.RemoveUser("u3"))
+
+	// invalid roles are rejected without a request
+	_, err = system.CreateUser(&types.UserParam{Name: "x", UserRole: "Root"})
+	assert.NotNil(t, err)
+	assert.NotNil(t, system.SetUserRole("u3", "monitor"))
+
+	assert.Equal(t, []string{
+		"POST /api/types/User/instances/action/queryIdByKey",
+		"GET /api/instances/User::u1",
+		"POST /api/types/User/instances/action/queryIdByKey",
+		"GET /api/instances/User::u1",
+		"GET /api/instances/System::sys1/relationships/User",
+		"POST /api/types/User/instances/action/queryIdByKey",
+		"POST /api/instances/User::u1/action/setPassword",
+		"POST /api/instances/User::u1/action/setPassword",
+		"POST /api/types/User/instances",
+		"POST /api/instances/User::u3/action/setUserRole",
+		"POST /api/instances/User::u3/action/resetPassword",
+		"POST /api/instances/User::u3/action/removeUser",
+	}, paths)
+	assert.Equal(t, []map[string]string{
+		{"name": "admin"},
+		{"name": "admin"},
+		{"name": "admin"},
+		{"oldPassword": "initial", "newPassword": "changed"},
+		{"oldPassword": "changed", "newPassword": "again"},
+		{"name": "operator", "userRole": "Configure"},
+		{"userRole": "Monitor"},
+		{"password": "temporary"},
+		{},
+	}, bodies)
+}
@@ -33,9 +33,35 @@ type SdcMappedVolume struct {
 	MdmID     string
 	VolumeID  string
 	SdcDevice string
-	// Mounted   bool
-	// MountPath bool
-	// Mapped    bool
+
+	// The device information below is only read by
+	// GetLocalVolumeMapWithDeviceInfo.
+
+	// SizeInBytes is the size of the block device
+	SizeInBytes int64
+	// ReadOnly is set if the block device is read-only
+	ReadOnly bool
+	// Partitions are the kernel names of the device's partitions
+	Partitions []string
+	// MountPoints are where the device or one of its partitions is mounted
+	MountPoints []string
+	// Holders are the devices stacked on the device or its partitions,
+	// such as device-mapper and multipath targets
+	Holders []string
+	// InFlightReads is the number of read requests in progress
+	InFlightReads int64
+	// InFlightWrites is the number of write requests in progress
+	InFlightWrites int64
+}
+
+// Mounted reports whether the device or one of its partitions is mounted
+func (v *SdcMappedVolume) Mounted() bool {
+	return len(v.MountPoints) > 0
+}
+
+// InUse reports whether the device is mounted or held by another device
+func (v *SdcMappedVolume) InUse() bool {
+	return v.Mounted() || len(v.Holders) > 0
 }
 
 // Volume defines struct for Volume
@@ -52,14 +78,20 @@ func NewVolume(client *Client) *Volume {
 	}
 }
 
+// withClient returns a copy of the volume that makes its requests with c,
+// the client of a call that calls other methods of the volume
+func (v *Volume) withClient(c *Client) *Volume {
+	return &Volume{Volume: v.Volume, client: c}
+}
+
 // GetVolume returns a volume
 func (sp *StoragePool) GetVolume(
 	volumehref, volumeid, ancestorvolumeid, volumename string,
-	getSnapshots bool) ([]*types.Volume, error) {
-	defer TimeSpent("GetVolume", time.Now())
+	getSnapshots bool) (_ []*types.Volume, err error) {
+	c, end := sp.client.trace("StoragePool.GetVolume")
+	defer end(&err)
 
 	var (
-		err     error
 		path    string
 		volume  = &types.Volume{}
 		volumes []*types.Volume
@@ -89,10 +121,10 @@ func (sp *StoragePool) GetVolume(
 	}
 
 	if volumehref == "" && volumeid == "" {
-		err = sp.client.getJSONWithRetry(
+		err = c.getJSONWithRetry(
 			http.MethodGet, path, nil, &volumes)
 	} else {
-		err = sp.client.getJSONWithRetry(
+		err = c.getJSONWithRetry(
 			http.MethodGet, path, nil, volume)
 	}
 	if err != nil {
@@ -114,8 +146,9 @@ func (sp *StoragePool) GetVolume(
 }
 
 // FindVolumeID retruns a volume ID based on name
-func (sp *StoragePool) FindVolumeID(volumename string) (string, error) {
-	defer TimeSpent("FindVolumeID", time.Now())
+func (sp *StoragePool) FindVolumeID(volumename string) (_ string, err error) {
+	c, end := sp.client.trace("StoragePool.FindVolumeID")
+	defer end(&err)
 
 	volumeQeryIDByKeyParam := &types.VolumeQeryIDByKeyParam{
 		Name: volumename,
@@ -123,7 +156,7 @@ func (sp *StoragePool) FindVolumeID(volumename string) (string, error) {
 
 	path := fmt.Sprintf("/api/types/Volume/instances/action/queryIdByKey")
 
-	volumeID, err := sp.client.getStringWithRetry(
+	volumeID, err := c.getStringWithRetry(
 		http.MethodPost, path, volumeQeryIDByKeyParam)
 	if err != nil {
 		return "", err
@@ -154,6 +187,30 @@ func GetLocalVolumeMap() (mappedVolumes []*SdcMappedVolume, err error) {
 	return getVolumeMapping(`\w*`, `\w*`)
 }
 
+// GetLocalVolumeMapWithDeviceInfo returns the SdcMappedVolume entries
+// matching the supplied regex values, like GetLocalVolumeMapByRegex, with
+// their size, read-only flag, partitions, mount points, holders and
+// in-flight I/O read from /sys/block and /proc/self/mountinfo. It fails if
+// the mount table cannot be read, so that a mounted device is never
+// reported as unmounted.
+func GetLocalVolumeMapWithDeviceInfo(
+	systemIDRegex string, volumeIDRegex string) ([]*SdcMappedVolume, error) {
+
+	mappedVolumes, err := GetLocalVolumeMapByRegex(systemIDRegex, volumeIDRegex)
+	if err != nil || len(mappedVolumes) == 0 {
+		return mappedVolumes, err
+	}
+
+	mounts, err := readMountInfo()
+	if err != nil {
+		return nil, err
+	}
+	for _, vol := range mappedVolumes {
+		populateDeviceInfo(vol, mounts)
+	}
+	return mappedVolumes, nil
+}
+
 func getVolumeMapping(sysID string, volID string) (mappedVolumes []*SdcMappedVolume, err error) {
 	defer TimeSpent("GetLocalVolumeMap", time.Now())
 
@@ -188,8 +245,15 @@ func getVolumeMapping(sysID string, volID string) (mappedVolumes []*SdcMappedVol
 
 // CreateVolume creates a volume
 func (sp *StoragePool) CreateVolume(
-	volume *types.VolumeParam) (*types.VolumeResp, error) {
-	defer TimeSpent("CreateVolume", time.Now())
+	volume *types.VolumeParam) (_ *types.VolumeResp, err error) {
+	c, end := sp.client.trace("StoragePool.CreateVolume")
+	defer end(&err)
+
+	c.audit("Volume", "")
+
+	if err := c.checkCapability(OpVolumeCreate); err != nil {
+		return nil, err
+	}
 
 	path := "/api/types/Volume/instances"
 
@@ -197,7 +261,7 @@ func (sp *StoragePool) CreateVolume(
 	volume.ProtectionDomainID = sp.StoragePool.ProtectionDomainID
 
 	volumeResp := &types.VolumeResp{}
-	err := sp.client.getJSONWithRetry(
+	err = c.getJSONWithRetry(
 		http.MethodPost, path, volume, volumeResp)
 	if err != nil {
 		return nil, err
@@ -207,8 +271,9 @@ func (sp *StoragePool) CreateVolume(
 }
 
 // GetVTree returns a volume's vtree
-func (v *Volume) GetVTree() (*types.VTree, error) {
-	defer TimeSpent("GetVTree", time.Now())
+func (v *Volume) GetVTree() (_ *types.VTree, err error) {
+	c, end := v.client.trace("Volume.GetVTree")
+	defer end(&err)
 
 	link, err := GetLink(v.Volume.Links, "/api/parent/relationship/vtreeId")
 	if err != nil {
@@ -216,7 +281,7 @@ func (v *Volume) GetVTree() (*types.VTree, error) {
 	}
 
 	vtree := &types.VTree{}
-	err = v.client.getJSONWithRetry(
+	err = c.getJSONWithRetry(
 		http.MethodGet, link.HREF, nil, vtree)
 	if err != nil {
 		return nil, err
@@ -226,8 +291,9 @@ func (v *Volume) GetVTree() (*types.VTree, error) {
 }
 
 // GetVolumeStatistics returns a volume's statistics
-func (v *Volume) GetVolumeStatistics() (*types.VolumeStatistics, error) {
-	defer TimeSpent("GetStatistics", time.Now())
+func (v *Volume) GetVolumeStatistics() (_ *types.VolumeStatistics, err error) {
+	c, end := v.client.trace("Volume.GetVolumeStatistics")
+	defer end(&err)
 
 	link, err := GetLink(v.Volume.Links, "/api/Volume/relationship/Statistics")
 	if err != nil {
@@ -235,7 +301,7 @@ func (v *Volume) GetVolumeStatistics() (*types.VolumeStatistics, error) {
 	}
 
 	var stats types.VolumeStatistics
-	err = v.client.getJSONWithRetry(
+	err = c.getJSONWithRetry(
 		http.MethodGet, link.HREF, nil, &stats)
 	if err != nil {
 		return nil, err
@@ -245,8 +311,15 @@ func (v *Volume) GetVolumeStatistics() (*types.VolumeStatistics, error) {
 }
 
 // RemoveVolume removes a volume
-func (v *Volume) RemoveVolume(removeMode string) error {
-	defer TimeSpent("RemoveVolume", time.Now())
+func (v *Volume) RemoveVolume(removeMode string) (err error) {
+	c, end := v.client.trace("Volume.RemoveVolume")
+	defer end(&err)
+
+	c.audit("Volume", v.Volume.ID)
+
+	if err := c.checkCapability(OpVolumeRemove); err != nil {
+		return err
+	}
 
 	link, err := GetLink(v.Volume.Links, "self")
 	if err != nil {
@@ -262,26 +335,42 @@ func (v *Volume) RemoveVolume(removeMode string) error {
 		RemoveMode: removeMode,
 	}
 
-	err = v.client.getJSONWithRetry(
+	err = c.getJSONWithRetry(
 		http.MethodPost, path, removeVolumeParam, nil)
 	return err
 }
 
 // SetVolumeName sets a volume's name
-func (v *Volume) SetVolumeName(newName string) error {
+func (v *Volume) SetVolumeName(newName string) (err error) {
+	c, end := v.client.trace("Volume.SetVolumeName")
+	defer end(&err)
+
+	c.audit("Volume", v.Volume.ID)
+
+	if err := c.checkCapability(OpVolumeModify); err != nil {
+		return err
+	}
 
 	path := fmt.Sprintf("/api/instances/Volume::%s/action/setVolumeName", v.Volume.ID)
 
 	payload := &types.SetVolumeNameParam{
 		NewName: newName,
 	}
-	err := v.client.getJSONWithRetry(
+	err = c.getJSONWithRetry(
 		http.MethodPost, path, payload, nil)
 	return err
 }
 
 // SetVolumeSize sets a volume's size
-func (v *Volume) SetVolumeSize(sizeInGB string) error {
+func (v *Volume) SetVolumeSize(sizeInGB string) (err error) {
+	c, end := v.client.trace("Volume.SetVolumeSize")
+	defer end(&err)
+
+	c.audit("Volume", v.Volume.ID)
+
+	if err := c.checkCapability(OpVolumeModify); err != nil {
+		return err
+	}
 
 	link, err := GetLink(v.Volume.Links, "self")
 	if err != nil {
@@ -291,7 +380,7 @@ func (v *Volume) SetVolumeSize(sizeInGB string) error {
 	payload := &types.SetVolumeSizeParam{
 		SizeInGB: sizeInGB,
 	}
-	err = v.client.getJSONWithRetry(
+	err = c.getJSONWithRetry(
 		http.MethodPost, path, payload, nil)
 	return err
 }
@@ -15,11 +15,15 @@ package goscaleio
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
 	types "github.com/AnshumanPradipPatil1506/goscaleio/types/v1"
+	"github.com/stretchr/testify/assert"
 )
 
 func Test_GetVolumeStatistics(t *testing.T) {
@@ -132,3 +136,92 @@ func Test_GetVolumeStatistics(t *testing.T) {
 		})
 	}
 }
+
+func Test_GetLocalVolumeMapDeviceInfo(t *testing.T) {
+	prefix, _ := setupFakeDev(t)
+
+	write := func(path, content string) {
+		t.Helper()
+		full := filepath.Join(prefix, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(full, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// scinia: 8 GiB, read-only, partitioned, one partition mounted and
+	// held by device-mapper, I/O in flight
+	write("sys/block/scinia/size", "16777216\n")
+	write("sys/block/scinia/ro", "1\n")
+	write("sys/block/scinia/inflight", "       3        7\n")
+	write("sys/block/scinia/scinia1/partition", "1\n")
+	write("sys/block/scinia/scinia1/holders/dm-3", "")
+	write("sys/block/scinia/scinia2/partition", "2\n")
+	write("sys/block/scinia/queue/rotational", "0\n")
+	write("sys/block/scinia/dev", "252:0\n")
+	write("sys/block/scinia/scinia1/dev", "252:1\n")
+	// scinia2 is mounted by name, scinia1 through a device-mapper name
+	// that only its major:minor number ties to the partition, and scinib
+	// through its /dev/disk/by-id link
+	write("proc/self/mountinfo",
+		"22 1 8:1 / / rw,relatime - ext4 /dev/sda1 rw\n"+
+			"36 22 98:2 / /mnt/my\\040data rw,noatime - xfs /dev/scinia2 rw\n"+
+			"37 22 252:1 / /mnt/part1 rw,noatime - ext4 /dev/mapper/part1 rw\n"+
+			"38 22 98:16 / /mnt/byid rw,noatime - ext4 /dev/disk/by-id/emc-vol-"+testNodeSystemID+"-d00d000000000002 rw\n")
+	if err := os.Symlink("../../scinia", fakeVolumeLink(prefix)); err != nil {
+		t.Fatal(err)
+	}
+
+	// scinib: nothing in sysfs
+	write("dev/scinib", "")
+	if err := os.Symlink("../../scinib", filepath.Join(prefix, "dev/disk/by-id", "emc-vol-"+testNodeSystemID+"-d00d000000000002")); err != nil {
+		t.Fatal(err)
+	}
+
+	// plain listings do not read sysfs or the mount table
+	vols, err := GetLocalVolumeMap()
+	assert.Nil(t, err)
+	if assert.Equal(t, 2, len(vols)) {
+		assert.Zero(t, vols[0].SizeInBytes)
+		assert.Empty(t, vols[0].MountPoints)
+	}
+
+	vols, err = GetLocalVolumeMapWithDeviceInfo("", "")
+	assert.Nil(t, err)
+	if !assert.Equal(t, 2, len(vols)) {
+		return
+	}
+
+	vol := vols[0]
+	assert.Equal(t, testNodeVolumeID, vol.VolumeID)
+	assert.Equal(t, int64(8*1024*1024*1024), vol.SizeInBytes)
+	assert.True(t, vol.ReadOnly)
+	assert.Equal(t, []string{"scinia1", "scinia2"}, vol.Partitions)
+	assert.Equal(t, []string{"dm-3"}, vol.Holders)
+	assert.Equal(t, []string{"/mnt/my data", "/mnt/part1"}, vol.MountPoints)
+	assert.Equal(t, int64(3), vol.InFlightReads)
+	assert.Equal(t, int64(7), vol.InFlightWrites)
+	assert.True(t, vol.Mounted())
+	assert.True(t, vol.InUse())
+
+	vol = vols[1]
+	assert.Equal(t, "d00d000000000002", vol.VolumeID)
+	assert.Zero(t, vol.SizeInBytes)
+	assert.Equal(t, []string{"/mnt/byid"}, vol.MountPoints)
+	assert.True(t, vol.InUse())
+
+	// an unreadable mount table fails only the device information
+	if err := os.Remove(filepath.Join(prefix, "proc/self/mountinfo")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(prefix, "proc/self/mountinfo"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	_, err = GetLocalVolumeMapWithDeviceInfo("", "")
+	assert.NotNil(t, err)
+	vols, err = GetLocalVolumeMap()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(vols))
+}
@@ -0,0 +1,192 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goscaleio
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultVolumeWatchPollInterval is how often the polling watcher lists /dev/disk/by-id
+const DefaultVolumeWatchPollInterval = time.Second
+
+// VolumeEventType is the kind of change reported by WatchLocalVolumes
+type VolumeEventType int
+
+const (
+	// VolumeAdded means the volume's /dev/disk/by-id entry appeared
+	VolumeAdded VolumeEventType = iota + 1
+	// VolumeRemoved means the volume's /dev/disk/by-id entry disappeared
+	VolumeRemoved
+)
+
+func (t VolumeEventType) String() string {
+	switch t {
+	case VolumeAdded:
+		return "Added"
+	case VolumeRemoved:
+		return "Removed"
+	}
+	return fmt.Sprintf("VolumeEventType(%d)", int(t))
+}
+
+// VolumeEvent is a change to the volumes mapped to the local SDC
+type VolumeEvent struct {
+	Type   VolumeEventType
+	Volume *SdcMappedVolume
+}
+
+// VolumeWatchOptions defines the options for WatchLocalVolumes
+type VolumeWatchOptions struct {
+	// SystemIDRegex restricts the events to matching systems, any system if empty
+	SystemIDRegex string
+	// VolumeIDRegex restricts the events to matching volumes, any volume if empty
+	VolumeIDRegex string
+	// PollInterval is used when polling, DefaultVolumeWatchPollInterval if zero
+	PollInterval time.Duration
+	// ForcePolling disables inotify
+	ForcePolling bool
+}
+
+// volumeTrigger wakes the watcher up when /dev/disk/by-id may have changed.
+// It is closed when the context is done.
+type volumeTrigger <-chan struct{}
+
+// WatchLocalVolumes reports volumes appearing in and disappearing from
+// /dev/disk/by-id. The volumes present when the watch starts are reported as
+// added first. Changes are detected with inotify where available and by
+// polling otherwise; if the inotify watch is lost, for example because
+// /dev/disk/by-id is removed or recreated, the watch falls back to polling.
+// The returned channel is closed once ctx is done, and only then.
+func WatchLocalVolumes(
+	ctx context.Context, opts *VolumeWatchOptions) (<-chan VolumeEvent, error) {
+
+	if opts == nil {
+		opts = &VolumeWatchOptions{}
+	}
+	sysRegex, volRegex := opts.SystemIDRegex, opts.VolumeIDRegex
+	for _, r := range []string{sysRegex, volRegex} {
+		if _, err := regexp.Compile(r); err != nil {
+			return nil, fmt.Errorf("WatchLocalVolumes: %v", err)
+		}
+	}
+
+	interval := opts.PollInterval
+	if interval == 0 {
+		interval = DefaultVolumeWatchPollInterval
+	}
+
+	diskIDPath := FSDevDirectoryPrefix + "/dev/disk/by-id"
+	var trigger volumeTrigger
+	if !opts.ForcePolling {
+		t, err := inotifyTrigger(ctx, diskIDPath, interval)
+		if err != nil {
+			doLog(log.WithError(err).Debug, "inotify unavailable, polling "+diskIDPath)
+		} else {
+			trigger = t
+		}
+	}
+	if trigger == nil {
+		trigger = pollTrigger(ctx, interval)
+	}
+
+	events := make(chan VolumeEvent)
+	go func() {
+		defer close(events)
+
+		known := make(map[string]*SdcMappedVolume)
+		for {
+			current, err := GetLocalVolumeMapByRegex(sysRegex, volRegex)
+			if err != nil {
+				doLog(log.WithError(err).Error, "WatchLocalVolumes: listing volumes")
+			} else if !diffVolumes(ctx, known, current, events) {
+				return
+			}
+
+			if _, ok := <-trigger; !ok {
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// diffVolumes sends the changes from known to current and updates known. It
+// returns false if ctx was done before all events were delivered.
+func diffVolumes(
+	ctx context.Context,
+	known map[string]*SdcMappedVolume,
+	current []*SdcMappedVolume,
+	events chan<- VolumeEvent) bool {
+
+	send := func(e VolumeEvent) bool {
+		select {
+		case events <- e:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	seen := make(map[string]bool, len(current))
+	for _, v := range current {
+		key := v.MdmID + "-" + v.VolumeID
+		seen[key] = true
+		if _, ok := known[key]; ok {
+			continue
+		}
+		known[key] = v
+		if !send(VolumeEvent{Type: VolumeAdded, Volume: v}) {
+			return false
+		}
+	}
+
+	for key, v := range known {
+		if seen[key] {
+			continue
+		}
+		delete(known, key)
+		if !send(VolumeEvent{Type: VolumeRemoved, Volume: v}) {
+			return false
+		}
+	}
+	return true
+}
+
+// pollTrigger fires every interval until ctx is done
+func pollTrigger(ctx context.Context, interval time.Duration) volumeTrigger {
+	c := make(chan struct{})
+	go func() {
+		defer close(c)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				select {
+				case c <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return c
+}
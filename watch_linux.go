@@ -0,0 +1,112 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+
+package goscaleio
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+	"unsafe"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// inotifyTrigger fires whenever an entry is created in, removed from or
+// moved in or out of dir. If the watch on dir is lost, because dir was
+// removed or moved away or reading the events failed, it fires once more and
+// then falls back to firing every interval. It is only closed when ctx is
+// done.
+func inotifyTrigger(
+	ctx context.Context, dir string, interval time.Duration) (volumeTrigger, error) {
+
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("inotify init: %v", err)
+	}
+
+	mask := uint32(syscall.IN_CREATE | syscall.IN_DELETE |
+		syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO |
+		syscall.IN_DELETE_SELF | syscall.IN_MOVE_SELF)
+	if _, err := syscall.InotifyAddWatch(fd, dir, mask); err != nil {
+		_ = syscall.Close(fd)
+		return nil, fmt.Errorf("inotify watch %s: %v", dir, err)
+	}
+
+	// a non-blocking descriptor is served by the runtime poller, so closing
+	// the file unblocks the pending Read
+	f := os.NewFile(uintptr(fd), "inotify")
+
+	c := make(chan struct{})
+	fire := func() bool {
+		select {
+		case c <- struct{}{}:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	go func() {
+		<-ctx.Done()
+		_ = f.Close()
+	}()
+	go func() {
+		defer close(c)
+		buf := make([]byte, 16*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+		for {
+			// only a lost watch matters in the events, the watcher lists
+			// the directory again either way
+			n, err := f.Read(buf)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				doLog(log.WithError(err).Warn, "inotify read failed, polling "+dir)
+				break
+			}
+			lost := inotifyWatchLost(buf[:n])
+			if !fire() {
+				return
+			}
+			if lost {
+				doLog(log.Debug, "inotify watch lost, polling "+dir)
+				break
+			}
+		}
+		_ = f.Close()
+
+		for range pollTrigger(ctx, interval) {
+			if !fire() {
+				return
+			}
+		}
+	}()
+	return c, nil
+}
+
+// inotifyWatchLost reports whether the events include the end of the watch
+func inotifyWatchLost(buf []byte) bool {
+	lost := uint32(syscall.IN_IGNORED | syscall.IN_DELETE_SELF | syscall.IN_MOVE_SELF)
+	for off := 0; off+syscall.SizeofInotifyEvent <= len(buf); {
+		// #nosec G103, the kernel writes whole inotify_event records
+		e := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[off]))
+		if e.Mask&lost != 0 {
+			return true
+		}
+		off += syscall.SizeofInotifyEvent + int(e.Len)
+	}
+	return false
+}
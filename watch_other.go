@@ -0,0 +1,27 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !linux
+
+package goscaleio
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// inotifyTrigger is only available on Linux; elsewhere the watcher polls
+func inotifyTrigger(
+	ctx context.Context, dir string, interval time.Duration) (volumeTrigger, error) {
+	return nil, errors.New("inotify is not supported on this platform")
+}
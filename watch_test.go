@@ -0,0 +1,141 @@
+// Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goscaleio
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WatchLocalVolumes(t *testing.T) {
+	for name, forcePolling := range map[string]bool{"inotify": false, "polling": true} {
+		t.Run(name, func(t *testing.T) {
+			prefix, _ := setupFakeDev(t)
+			byID := filepath.Join(prefix, "dev/disk/by-id")
+
+			// present before the watch starts
+			existing := filepath.Join(byID, "emc-vol-"+testNodeSystemID+"-aaaa000000000001")
+			assert.Nil(t, os.Symlink("../../scinia", existing))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			events, err := WatchLocalVolumes(ctx, &VolumeWatchOptions{
+				SystemIDRegex: testNodeSystemID,
+				PollInterval:  10 * time.Millisecond,
+				ForcePolling:  forcePolling,
+			})
+			assert.Nil(t, err)
+
+			next := func() VolumeEvent {
+				select {
+				case e := <-events:
+					return e
+				case <-time.After(5 * time.Second):
+					t.Fatal("timed out waiting for event")
+				}
+				return VolumeEvent{}
+			}
+
+			e := next()
+			assert.Equal(t, VolumeAdded, e.Type)
+			assert.Equal(t, "aaaa000000000001", e.Volume.VolumeID)
+
+			// volumes of other systems are filtered out
+			other := filepath.Join(byID, "emc-vol-ffffffffffffffff-bbbb000000000002")
+			assert.Nil(t, os.Symlink("../../scinia", other))
+			added := filepath.Join(byID, "emc-vol-"+testNodeSystemID+"-cccc000000000003")
+			assert.Nil(t, os.Symlink("../../scinia", added))
+
+			e = next()
+			assert.Equal(t, VolumeAdded, e.Type)
+			assert.Equal(t, "cccc000000000003", e.Volume.VolumeID)
+			assert.Equal(t, testNodeSystemID, e.Volume.MdmID)
+
+			assert.Nil(t, os.Remove(existing))
+			e = next()
+			assert.Equal(t, VolumeRemoved, e.Type)
+			assert.Equal(t, "aaaa000000000001", e.Volume.VolumeID)
+
+			cancel()
+			for range events {
+			}
+		})
+	}
+}
+
+func Test_WatchLocalVolumesDirRecreated(t *testing.T) {
+	for name, forcePolling := range map[string]bool{"inotify": false, "polling": true} {
+		t.Run(name, func(t *testing.T) {
+			prefix, _ := setupFakeDev(t)
+			byID := filepath.Join(prefix, "dev/disk/by-id")
+			link := func(volumeID string) {
+				t.Helper()
+				assert.Nil(t, os.Symlink("../../scinia",
+					filepath.Join(byID, "emc-vol-"+testNodeSystemID+"-"+volumeID)))
+			}
+			link("aaaa000000000001")
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			events, err := WatchLocalVolumes(ctx, &VolumeWatchOptions{
+				PollInterval: 10 * time.Millisecond,
+				ForcePolling: forcePolling,
+			})
+			assert.Nil(t, err)
+
+			next := func() VolumeEvent {
+				select {
+				case e, ok := <-events:
+					if !ok {
+						t.Fatal("events closed before ctx was done")
+					}
+					return e
+				case <-time.After(5 * time.Second):
+					t.Fatal("timed out waiting for event")
+				}
+				return VolumeEvent{}
+			}
+
+			assert.Equal(t, VolumeAdded, next().Type)
+
+			// removing the directory removes its volumes
+			assert.Nil(t, os.RemoveAll(byID))
+			e := next()
+			assert.Equal(t, VolumeRemoved, e.Type)
+			assert.Equal(t, "aaaa000000000001", e.Volume.VolumeID)
+
+			// and volumes in the recreated directory are still reported
+			assert.Nil(t, os.MkdirAll(byID, 0755))
+			link("bbbb000000000002")
+			e = next()
+			assert.Equal(t, VolumeAdded, e.Type)
+			assert.Equal(t, "bbbb000000000002", e.Volume.VolumeID)
+
+			cancel()
+			for range events {
+			}
+		})
+	}
+}
+
+func Test_WatchLocalVolumesInvalidRegex(t *testing.T) {
+	_, err := WatchLocalVolumes(context.Background(), &VolumeWatchOptions{VolumeIDRegex: "("})
+	assert.NotNil(t, err)
+}